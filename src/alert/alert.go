@@ -0,0 +1,54 @@
+package alert
+
+import (
+	"fmt"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 預算提醒的門檻，支出佔本期可用金額的百分比，由小到大
+var Thresholds = []int{80, 100}
+
+// 預算達到的最高門檻，沒有達到時回傳 false
+//
+// 一次跨過多個門檻時只提醒最高的，key 包含期間，下一期重新提醒
+func Budget(st bundle.BudgetStatus, base string) (bundle.Alert, bool) {
+	if st.Limit <= 0 {
+		return bundle.Alert{}, false
+	}
+
+	percent := 0
+	for _, t := range Thresholds {
+		if int64(st.Spent)*100 >= int64(st.Limit)*int64(t) {
+			percent = t
+		}
+	}
+
+	if percent == 0 {
+		return bundle.Alert{}, false
+	}
+
+	return bundle.Alert{
+		Kind:     bundle.AlertBudget,
+		Key:      fmt.Sprintf("%s:%d:%s:%d", bundle.AlertBudget, st.Id, st.PeriodStart, percent),
+		BudgetId: st.Id,
+		Message: fmt.Sprintf("%s 本期支出 %s 已達預算 %s 的 %d%%",
+			st.Name, currency.Format(st.Spent, base), currency.Format(st.Limit, base), percent),
+	}, true
+}
+
+// 單筆帳單超過提醒金額，金額為基準幣別的最小單位，threshold 為 0 表示不提醒
+func Bill(itemId int, name string, amount, threshold int, base string) (bundle.Alert, bool) {
+	if threshold <= 0 || amount <= threshold {
+		return bundle.Alert{}, false
+	}
+
+	return bundle.Alert{
+		Kind:   bundle.AlertBill,
+		Key:    fmt.Sprintf("%s:%d", bundle.AlertBill, itemId),
+		ItemId: itemId,
+		Message: fmt.Sprintf("%s %s 超過單筆提醒金額 %s",
+			name, currency.Format(amount, base), currency.Format(threshold, base)),
+	}, true
+}
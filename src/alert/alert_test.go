@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+func TestBudget(t *testing.T) {
+	st := bundle.BudgetStatus{
+		Budget:      bundle.Budget{Id: 3, Name: "餐費", Amount: 8000},
+		PeriodStart: "2022-10-01",
+		Limit:       8000,
+		Spent:       6000,
+	}
+
+	if _, ok := Budget(st, "TWD"); ok {
+		t.Fatal("unexpected alert below 80%")
+	}
+
+	st.Spent = 6400
+	a, ok := Budget(st, "TWD")
+	if !ok || a.Key != "budget:3:2022-10-01:80" || a.BudgetId != 3 {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+
+	if a.Message != "餐費 本期支出 6400 已達預算 8000 的 80%" {
+		t.Fatalf("unexpected message %q", a.Message)
+	}
+
+	// 一次跨過兩個門檻只提醒 100%
+	st.Spent = 9000
+	if a, _ := Budget(st, "TWD"); a.Key != "budget:3:2022-10-01:100" {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+
+	st.Limit = 0
+	if _, ok := Budget(st, "TWD"); ok {
+		t.Fatal("unexpected alert without limit")
+	}
+}
+
+func TestBill(t *testing.T) {
+	if _, ok := Bill(1, "電視", 30000, 0, "TWD"); ok {
+		t.Fatal("unexpected alert without threshold")
+	}
+
+	if _, ok := Bill(1, "午餐", 3000, 3000, "TWD"); ok {
+		t.Fatal("unexpected alert at threshold")
+	}
+
+	a, ok := Bill(7, "Hotel", 45050, 30000, "USD")
+	if !ok || a.Key != "bill:7" || a.ItemId != 7 || a.Kind != bundle.AlertBill {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+
+	if a.Message != "Hotel 450.50 超過單筆提醒金額 300.00" {
+		t.Fatalf("unexpected message %q", a.Message)
+	}
+}
@@ -0,0 +1,169 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+// Webhook 的逾時
+const webhookTimeout = 10 * time.Second
+
+var (
+	ErrStatus  = errors.New("unexpected status")   // Webhook 回應不是 2xx
+	ErrURL     = errors.New("invalid webhook url") // 不是 http 或 https 網址
+	ErrAddress = errors.New("address not allowed") // Webhook 指向本機或內部網路
+)
+
+// 通知方式，站內收件匣由資料庫保存，不需要 Notifier
+type Notifier interface {
+	Notify(a bundle.Alert) error
+}
+
+// 依序通知，單一方式失敗不影響其他方式，回傳第一個錯誤
+func Send(ns []Notifier, a bundle.Alert) error {
+	var first error
+	for _, n := range ns {
+		if err := n.Notify(a); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// 電信業者 NAT 使用的位址 (RFC 6598)
+var sharedAddress = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// 可以連線的位址，排除本機、link-local (包含 169.254.169.254)、私有網路與 CGNAT
+func Public(ip net.IP) bool {
+	return !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddress.Contains(ip)
+}
+
+// 檢查 Webhook 網址，回傳正規化的網址
+//
+// 主機是 IP 或可以解析時全部位址都必須是 Public，解析失敗時留給送出時的連線檢查
+func CheckURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Hostname()) == 0 {
+		return "", ErrURL
+	}
+
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		ips, _ = net.LookupIP(u.Hostname())
+	}
+
+	for _, ip := range ips {
+		if !Public(ip) {
+			return "", ErrAddress
+		}
+	}
+
+	return u.String(), nil
+}
+
+// 連線前檢查解析後的位址，避免 DNS 在檢查網址後改指向內部網路
+func dialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !Public(ip) {
+		return ErrAddress
+	}
+
+	return nil
+}
+
+// 預設的 client，不使用 proxy，只連線到 Public 位址，共用連線
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: webhookTimeout, Control: dialControl}).DialContext,
+	},
+}
+
+// 以 JSON POST 提醒內容
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil 時使用預設逾時，且不連線到本機或內部網路
+}
+
+func (w *Webhook) Notify(a bundle.Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	client := w.Client
+	if client == nil {
+		client = webhookClient
+	}
+
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrStatus
+	}
+
+	return nil
+}
+
+// 寄信伺服器，Addr 為 host:port，沒有帳號時不驗證
+type MailServer struct {
+	Addr     string
+	User     string
+	Password string
+	From     string
+}
+
+// 以電子郵件通知
+type Mail struct {
+	Server MailServer
+	To     string
+}
+
+func (m *Mail) Notify(a bundle.Alert) error {
+	var auth smtp.Auth
+	if len(m.Server.User) > 0 {
+		host, _, _ := net.SplitHostPort(m.Server.Addr)
+		auth = smtp.PlainAuth("", m.Server.User, m.Server.Password, host)
+	}
+
+	return smtp.SendMail(m.Server.Addr, auth, m.Server.From, []string{m.To}, m.message(a))
+}
+
+// 信件內容，標題與內文以 UTF-8 編碼
+func (m *Mail) message(a bundle.Alert) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.Server.From)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", "GoDaily 提醒"))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	b.WriteString(base64.StdEncoding.EncodeToString([]byte(a.Message)))
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+var testAlert = bundle.Alert{Kind: bundle.AlertBill, Key: "bill:7", ItemId: 7, Message: "電視 30000 超過單筆提醒金額 20000"}
+
+// 只支援寄信必要指令的 SMTP 伺服器，收到的信件送到 mails
+func fakeSmtp(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	mails := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end with .")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				mails <- data.String()
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().String(), mails
+}
+
+func TestMail(t *testing.T) {
+	addr, mails := fakeSmtp(t)
+
+	m := &Mail{Server: MailServer{Addr: addr, From: "daily@example.com"}, To: "me@example.com"}
+	if err := m.Notify(testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := <-mails
+	if !strings.Contains(mail, "To: me@example.com\r\n") {
+		t.Fatalf("unexpected mail %q", mail)
+	}
+
+	body := mail[strings.Index(mail, "\r\n\r\n")+4:]
+	text, err := base64.StdEncoding.DecodeString(strings.TrimSpace(body))
+	if err != nil || string(text) != testAlert.Message {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	received := make(chan bundle.Alert, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a bundle.Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- a
+	}))
+	defer ts.Close()
+
+	w := &Webhook{URL: ts.URL, Client: ts.Client()}
+	if err := w.Notify(testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	if a := <-received; a.Message != testAlert.Message || a.ItemId != 7 {
+		t.Fatalf("unexpected alert %+v", a)
+	}
+
+	// 預設的 client 不連線到本機與 CGNAT 位址
+	w.Client = nil
+	for _, u := range []string{ts.URL, "http://100.64.1.1/hook"} {
+		w.URL = u
+		if err := w.Notify(testAlert); !errors.Is(err, ErrAddress) {
+			t.Fatalf("%s: unexpected error %v", u, err)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	cases := []struct {
+		url string
+		err error
+	}{
+		{"https://203.0.113.7/hook", nil},
+		{"ftp://203.0.113.7/hook", ErrURL},
+		{"/hook", ErrURL},
+		{"http://127.0.0.1:8080/hook", ErrAddress},
+		{"http://[::1]/hook", ErrAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrAddress},
+		{"http://10.0.0.1/hook", ErrAddress},
+		{"http://192.168.1.1/hook", ErrAddress},
+		{"http://0.0.0.0/hook", ErrAddress},
+		{"http://100.64.1.1/hook", ErrAddress},
+		{"http://100.127.255.254/hook", ErrAddress},
+		{"http://100.128.0.1/hook", nil},
+	}
+
+	for _, c := range cases {
+		if _, err := CheckURL(c.url); err != c.err {
+			t.Errorf("CheckURL(%q) = %v, want %v", c.url, err, c.err)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	addr, mails := fakeSmtp(t)
+	ns := []Notifier{
+		&Webhook{URL: ts.URL, Client: ts.Client()},
+		&Mail{Server: MailServer{Addr: addr, From: "daily@example.com"}, To: "me@example.com"},
+	}
+
+	// webhook 失敗仍然寄信
+	if err := Send(ns, testAlert); err != ErrStatus {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if mail := <-mails; !strings.Contains(mail, "Subject: ") {
+		t.Fatalf("unexpected mail %q", mail)
+	}
+}
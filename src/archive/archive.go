@@ -0,0 +1,266 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 備份檔版本，格式不相容時增加
+//
+// 還原接受 MinVersion 到 Version 之間的檔案
+const (
+	Version    = 9
+	MinVersion = 1
+)
+
+// 金額以最小單位儲存的版本，之前的檔案還原時換算
+const minorVersion = 9
+
+// 區段開始出現的版本，舊版檔案沒有的區段視為空白
+var since = map[string]int{
+	"accounts":             2,
+	"transfers":            3,
+	"rates":                4,
+	"budgets":              6,
+	"recurring":            7,
+	"settings":             8,
+	"recurring_exceptions": 8,
+}
+
+var (
+	ErrFormat    = errors.New("invalid archive")     // 不是備份檔
+	ErrVersion   = errors.New("unsupported version") // 版本不相容
+	ErrChecksum  = errors.New("checksum mismatch")   // 內容與檢查碼不符
+	ErrReference = errors.New("dangling reference")  // 參照不存在的類別
+)
+
+// 備份檔
+//
+// 每個區段各自計算 SHA-256，區段內容保留原始 JSON，驗證時不需要重新編碼
+type Archive struct {
+	Version   int                        `json:"version"`
+	Created   time.Time                  `json:"created"`
+	Checksums map[string]string          `json:"checksums"`
+	Data      map[string]json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// 區段名稱與內容
+func sections(d *bundle.ArchiveData) map[string]interface{} {
+	return map[string]interface{}{
+		"accounts":             &d.Accounts,
+		"main_types":           &d.MainTypes,
+		"sub_types":            &d.SubTypes,
+		"bills":                &d.Bills,
+		"rules":                &d.Rules,
+		"transfers":            &d.Transfers,
+		"rates":                &d.Rates,
+		"budgets":              &d.Budgets,
+		"recurring":            &d.Recurring,
+		"settings":             &d.Settings,
+		"recurring_exceptions": &d.RecurringExceptions,
+	}
+}
+
+// 建立備份檔
+func New(d bundle.ArchiveData, now time.Time) (*Archive, error) {
+	a := &Archive{
+		Version:   Version,
+		Created:   now,
+		Checksums: make(map[string]string),
+		Data:      make(map[string]json.RawMessage),
+	}
+
+	for name, v := range sections(&d) {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		a.Data[name] = raw
+		a.Checksums[name] = checksum(raw)
+	}
+
+	return a, nil
+}
+
+// 讀取並驗證備份檔
+//
+// base 為還原帳號目前的基準幣別，舊版檔案沒有設定時以此換算金額
+func Read(r io.Reader, base string) (bundle.ArchiveData, error) {
+	var d bundle.ArchiveData
+	var a Archive
+
+	if err := json.NewDecoder(r).Decode(&a); err != nil || a.Version == 0 {
+		return d, ErrFormat
+	}
+
+	if a.Version < MinVersion || a.Version > Version {
+		return d, ErrVersion
+	}
+
+	for name, v := range sections(&d) {
+		raw, ok := a.Data[name]
+		if !ok && a.Version < since[name] {
+			continue
+		} else if !ok {
+			return d, ErrFormat
+		}
+
+		if checksum(raw) != a.Checksums[name] {
+			return d, ErrChecksum
+		}
+
+		if err := json.Unmarshal(raw, v); err != nil {
+			return d, ErrFormat
+		}
+	}
+
+	if err := check(d); err != nil {
+		return d, err
+	}
+
+	if a.Version < minorVersion {
+		if len(d.Settings.Currency) > 0 {
+			base = d.Settings.Currency
+		}
+		rescale(&d, base)
+	}
+
+	return d, nil
+}
+
+// 舊版檔案的金額換算成最小單位，空白的幣別與帳戶、轉帳、預算、規則、提醒門檻都是基準幣別
+func rescale(d *bundle.ArchiveData, base string) {
+	factor := func(code string) int {
+		if len(code) == 0 {
+			code = base
+		}
+
+		f := 1
+		for i := currency.Scale(strings.ToUpper(code)); i > 0; i-- {
+			f *= 10
+		}
+		return f
+	}
+
+	for i := range d.Accounts {
+		d.Accounts[i].Opening *= factor(base)
+	}
+
+	for i := range d.Bills {
+		d.Bills[i].Price *= factor(d.Bills[i].Currency)
+	}
+
+	for i := range d.Rules {
+		d.Rules[i].MinPrice *= factor(base)
+		d.Rules[i].MaxPrice *= factor(base)
+	}
+
+	for i := range d.Transfers {
+		d.Transfers[i].Amount *= factor(base)
+	}
+
+	for i := range d.Budgets {
+		d.Budgets[i].Amount *= factor(base)
+	}
+
+	recurring := make(map[int]string)
+	for i, r := range d.Recurring {
+		d.Recurring[i].Price *= factor(r.Currency)
+		recurring[r.Id] = r.Currency
+	}
+
+	for i, e := range d.RecurringExceptions {
+		d.RecurringExceptions[i].Price *= factor(recurring[e.RecurringId])
+	}
+
+	d.Settings.Alert.BillThreshold *= factor(base)
+}
+
+func checksum(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// 確認子類別、帳單、規則、預算、定期帳單與例外參照的帳戶、類別、原帳單與範本都在備份內
+func check(d bundle.ArchiveData) error {
+	accounts := make(map[int]bool)
+	for _, a := range d.Accounts {
+		accounts[a.Id] = true
+	}
+
+	mains := make(map[int]bool)
+	for _, m := range d.MainTypes {
+		mains[m.Id] = true
+	}
+
+	subs := make(map[int]bool)
+	for _, s := range d.SubTypes {
+		if !mains[s.MainId] {
+			return ErrReference
+		}
+		subs[s.Id] = true
+	}
+
+	bills := make(map[int]bool)
+	for _, b := range d.Bills {
+		if !subs[b.SubId] || (b.AccountId != 0 && !accounts[b.AccountId]) {
+			return ErrReference
+		}
+		bills[b.Id] = true
+	}
+
+	for _, b := range d.Bills {
+		if b.RefundOf != 0 && !bills[b.RefundOf] {
+			return ErrReference
+		}
+	}
+
+	for _, r := range d.Rules {
+		if r.SubId != 0 && !subs[r.SubId] {
+			return ErrReference
+		}
+	}
+
+	for _, t := range d.Transfers {
+		if !accounts[t.FromId] || !accounts[t.ToId] {
+			return ErrReference
+		}
+	}
+
+	for _, b := range d.Budgets {
+		if (b.MainId != 0 && !mains[b.MainId]) || (b.SubId != 0 && !subs[b.SubId]) {
+			return ErrReference
+		}
+	}
+
+	recurring := make(map[int]bool)
+	for _, r := range d.Recurring {
+		if !subs[r.SubId] || (r.AccountId != 0 && !accounts[r.AccountId]) {
+			return ErrReference
+		}
+		recurring[r.Id] = true
+	}
+
+	for _, b := range d.Bills {
+		if b.RecurringId != 0 && !recurring[b.RecurringId] {
+			return ErrReference
+		}
+	}
+
+	for _, e := range d.RecurringExceptions {
+		if !recurring[e.RecurringId] {
+			return ErrReference
+		}
+	}
+
+	return nil
+}
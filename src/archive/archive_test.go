@@ -0,0 +1,213 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+var testData = bundle.ArchiveData{
+	Accounts: []bundle.ArchiveAccount{
+		{Id: 5, Name: "現金", Type: bundle.AccountCash},
+		{Id: 6, Name: "銀行", Type: bundle.AccountBank, Opening: 1000},
+	},
+	MainTypes: []bundle.ArchiveMain{{Id: 1, Name: "餐費"}, {Id: 2, Name: "舊的", Deleted: true}},
+	SubTypes: []bundle.ArchiveSub{
+		{Id: 10, MainId: 1, Name: "午餐", Increase: -1},
+		{Id: 20, MainId: 2, Name: "已刪除", Increase: -1, Deleted: true},
+	},
+	Bills: []bundle.ArchiveBill{
+		{Id: 100, SubId: 10, Name: "排骨飯", Price: 120, Date: "2022-10-01", AccountId: 5},
+		{Id: 101, SubId: 20, Name: "很久以前", Price: 10, Date: "2020-01-01", FitId: "ofx:1:A", Currency: "JPY"},
+		{Id: 102, SubId: 10, Name: "排骨飯退款", Price: 20, Date: "2022-10-03", AccountId: 5, Refund: true, RefundOf: 100},
+		{Id: 103, SubId: 10, Name: "便當訂閱", Price: 1500, Date: "2022-10-01", AccountId: 6, Currency: "TWD", RecurringId: 1, RecurringDate: "2022-10-01"},
+	},
+	Rules:     []bundle.Rule{{Id: 1, Keyword: "便當", SubId: 10}},
+	Transfers: []bundle.Transfer{{Id: 1, FromId: 6, ToId: 5, Amount: 500, Date: "2022-10-02"}},
+	Rates:     []bundle.Rate{{Id: 1, Base: "TWD", Currency: "JPY", Date: "2022-10-01", Rate: 0.2198}},
+	Budgets:   []bundle.Budget{{Id: 1, MainId: 1, Period: bundle.BudgetMonth, Amount: 8000, Start: "2022-10-01"}},
+	Recurring: []bundle.Recurring{{
+		Id: 1, SubId: 10, Name: "便當訂閱", Price: 1500, AccountId: 6, Currency: "TWD",
+		Frequency: bundle.RecurMonthly, Interval: 1, Start: "2022-10-01", Materialized: "2022-10-01",
+	}},
+	Settings:            bundle.ArchiveSettings{Currency: "TWD", Alert: bundle.AlertSetting{BillThreshold: 1000, Email: "a@b.c"}},
+	RecurringExceptions: []bundle.RecurringException{{RecurringId: 1, Date: "2022-11-01", Skip: true}},
+}
+
+func encode(t *testing.T, a *Archive) []byte {
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	a, err := New(testData, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Read(bytes.NewReader(encode(t, a)), "TWD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(d, testData) {
+		t.Fatalf("unexpected data %+v", d)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	a, _ := New(testData, time.Now())
+	a.Data["bills"] = json.RawMessage(strings.Replace(string(a.Data["bills"]), "120", "1200", 1))
+
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrChecksum {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	a, _ := New(testData, time.Now())
+	a.Version = Version + 1
+
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrVersion {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, s := range []string{"", "{}", "[]", `{"version":1}`} {
+		if _, err := Read(strings.NewReader(s), "TWD"); err != ErrFormat {
+			t.Errorf("Read(%q) = %v", s, err)
+		}
+	}
+}
+
+func TestOldVersion(t *testing.T) {
+	d := testData
+	d.Accounts = nil
+	d.Transfers = nil
+	d.Rates = nil
+	d.Budgets = nil
+	d.Recurring = nil
+	d.Settings = bundle.ArchiveSettings{}
+	d.RecurringExceptions = nil
+	d.Bills = append([]bundle.ArchiveBill{}, testData.Bills[:3]...)
+	d.Bills[0].AccountId = 0
+	d.Bills[2].AccountId = 0
+
+	a, _ := New(d, time.Now())
+	a.Version = 1
+	for _, name := range []string{"accounts", "transfers", "rates", "budgets", "recurring", "settings", "recurring_exceptions"} {
+		delete(a.Data, name)
+		delete(a.Checksums, name)
+	}
+
+	r, err := Read(bytes.NewReader(encode(t, a)), "TWD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Accounts) != 0 || len(r.Transfers) != 0 || len(r.Rates) != 0 || len(r.Budgets) != 0 || len(r.Recurring) != 0 || len(r.Settings.Currency) != 0 || len(r.RecurringExceptions) != 0 || len(r.Bills) != 3 {
+		t.Fatalf("unexpected data %+v", r)
+	}
+
+	// 新版檔案必須有帳戶區段
+	a.Version = Version
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrFormat {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	d := testData
+	d.Settings = bundle.ArchiveSettings{}
+	d.Bills = append([]bundle.ArchiveBill{}, testData.Bills...)
+	d.Bills[0].Currency = "USD"
+	d.Recurring = append([]bundle.Recurring{}, testData.Recurring...)
+	d.Recurring[0].Currency = ""
+	d.RecurringExceptions = []bundle.RecurringException{{RecurringId: 1, Date: "2022-11-01", Price: 1600}}
+
+	a, _ := New(d, time.Now())
+	a.Version = minorVersion - 1
+
+	// 沒有設定時依還原帳號的基準幣別換算，JPY 與 TWD 沒有小數
+	r, err := Read(bytes.NewReader(encode(t, a)), "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Bills[0].Price != 12000 || r.Bills[1].Price != 10 || r.Bills[3].Price != 1500 {
+		t.Fatalf("unexpected bills %+v", r.Bills)
+	}
+	if r.Accounts[1].Opening != 100000 || r.Transfers[0].Amount != 50000 || r.Budgets[0].Amount != 800000 {
+		t.Fatalf("unexpected data %+v", r)
+	}
+	if r.Recurring[0].Price != 150000 || r.RecurringExceptions[0].Price != 160000 {
+		t.Fatalf("unexpected recurring %+v %+v", r.Recurring, r.RecurringExceptions)
+	}
+
+	// 備份有基準幣別時使用備份的設定
+	d.Settings = bundle.ArchiveSettings{Currency: "TWD", Alert: bundle.AlertSetting{BillThreshold: 1000}}
+	a, _ = New(d, time.Now())
+	a.Version = minorVersion - 1
+
+	if r, err = Read(bytes.NewReader(encode(t, a)), "USD"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Bills[0].Price != 12000 || r.Accounts[1].Opening != 1000 || r.Settings.Alert.BillThreshold != 1000 {
+		t.Fatalf("unexpected data %+v", r)
+	}
+
+	// 新版檔案不換算
+	a.Version = Version
+	if r, err = Read(bytes.NewReader(encode(t, a)), "USD"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Bills[0].Price != 120 || r.Accounts[1].Opening != 1000 {
+		t.Fatalf("unexpected data %+v", r)
+	}
+}
+
+func TestReference(t *testing.T) {
+	d := testData
+	d.Bills = append([]bundle.ArchiveBill{}, testData.Bills...)
+	d.Bills[0].SubId = 99
+
+	a, _ := New(d, time.Now())
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrReference {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 退款連結的原帳單不在備份內
+	d.Bills = append([]bundle.ArchiveBill{}, testData.Bills...)
+	d.Bills[2].RefundOf = 99
+
+	a, _ = New(d, time.Now())
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrReference {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 定期帳單的帳戶不在備份內
+	d.Bills = testData.Bills
+	d.Recurring = append([]bundle.Recurring{}, testData.Recurring...)
+	d.Recurring[0].AccountId = 99
+
+	a, _ = New(d, time.Now())
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrReference {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// 例外的範本不在備份內
+	d.Recurring = testData.Recurring
+	d.RecurringExceptions = []bundle.RecurringException{{RecurringId: 99, Date: "2022-11-01", Skip: true}}
+
+	a, _ = New(d, time.Now())
+	if _, err := Read(bytes.NewReader(encode(t, a)), "TWD"); err != ErrReference {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
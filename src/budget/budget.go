@@ -0,0 +1,109 @@
+package budget
+
+import (
+	"errors"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+const dateFormat = "2006-01-02"
+
+var (
+	ErrPeriod = errors.New("unknown period")     // 不支援的週期
+	ErrTarget = errors.New("invalid target")     // 主類別與子類別必須指定其中一個
+	ErrAmount = errors.New("invalid amount")     // 金額必須大於 0
+	ErrStart  = errors.New("invalid start date") // 開始日期格式錯誤
+)
+
+// 補上預設值，並檢查預算是否可以使用
+//
+// 週期預設每月，開始日期預設 today，並調整到所在期間的第一天
+func Normalize(b *bundle.Budget, today time.Time) error {
+	if len(b.Period) == 0 {
+		b.Period = bundle.BudgetMonth
+	}
+
+	switch b.Period {
+	case bundle.BudgetWeek, bundle.BudgetMonth, bundle.BudgetYear:
+	default:
+		return ErrPeriod
+	}
+
+	if (b.MainId == 0) == (b.SubId == 0) || b.MainId < 0 || b.SubId < 0 {
+		return ErrTarget
+	}
+
+	if b.Amount <= 0 {
+		return ErrAmount
+	}
+
+	start := today
+	if len(b.Start) > 0 {
+		t, err := time.Parse(dateFormat, b.Start)
+		if err != nil {
+			return ErrStart
+		}
+		start = t
+	}
+
+	start, _ = Bounds(b.Period, start)
+	b.Start = start.Format(dateFormat)
+
+	return nil
+}
+
+// 日期所在期間的第一天與最後一天
+//
+// 每週從星期一開始，與 PostgreSQL 的 DATE_TRUNC 相同
+func Bounds(period string, date time.Time) (time.Time, time.Time) {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case bundle.BudgetWeek:
+		start := d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 6)
+	case bundle.BudgetYear:
+		start := time.Date(d.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	}
+
+	start := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1)
+}
+
+// 計算 today 所在期間的預算狀態
+//
+// sums 為各期間的支出，key 為期間的第一天。rollover 時每一期的上限為預算加上前期結轉，
+// 未用完的部分結轉到下一期，已經用掉的結轉不會再結轉；超支時結轉歸零，不扣減之後的預算。預估支出以本期目前的支出依經過的天數等比例推算
+func Status(b bundle.Budget, sums map[string]int, today time.Time) bundle.BudgetStatus {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	start, end := Bounds(b.Period, today)
+	st := bundle.BudgetStatus{
+		Budget:      b,
+		PeriodStart: start.Format(dateFormat),
+		PeriodEnd:   end.Format(dateFormat),
+	}
+
+	if b.Rollover {
+		p, err := time.Parse(dateFormat, b.Start)
+		for err == nil && p.Before(start) {
+			_, pEnd := Bounds(b.Period, p)
+			st.Carry += b.Amount - sums[p.Format(dateFormat)]
+			if st.Carry < 0 {
+				st.Carry = 0
+			}
+			p = pEnd.AddDate(0, 0, 1)
+		}
+	}
+
+	st.Limit = b.Amount + st.Carry
+	st.Spent = sums[st.PeriodStart]
+	st.Remaining = st.Limit - st.Spent
+
+	total := int64(end.Sub(start)/(24*time.Hour)) + 1
+	elapsed := int64(today.Sub(start)/(24*time.Hour)) + 1
+	st.Projected = int((int64(st.Spent)*total*2 + elapsed) / (elapsed * 2))
+
+	return st
+}
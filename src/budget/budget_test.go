@@ -0,0 +1,103 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(dateFormat, s)
+	return t
+}
+
+func TestNormalize(t *testing.T) {
+	b := bundle.Budget{MainId: 1, Amount: 8000}
+	if err := Normalize(&b, date("2022-10-19")); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Period != bundle.BudgetMonth || b.Start != "2022-10-01" {
+		t.Fatalf("unexpected budget %+v", b)
+	}
+
+	cases := []struct {
+		b   bundle.Budget
+		err error
+	}{
+		{bundle.Budget{MainId: 1, Amount: 1, Period: "day"}, ErrPeriod},
+		{bundle.Budget{Amount: 1}, ErrTarget},
+		{bundle.Budget{MainId: 1, SubId: 2, Amount: 1}, ErrTarget},
+		{bundle.Budget{SubId: 2}, ErrAmount},
+		{bundle.Budget{SubId: 2, Amount: 1, Start: "2022/10/01"}, ErrStart},
+		{bundle.Budget{SubId: 2, Amount: 1, Period: bundle.BudgetWeek, Start: "2022-10-19"}, nil},
+	}
+
+	for i := range cases {
+		if err := Normalize(&cases[i].b, time.Now()); err != cases[i].err {
+			t.Errorf("Normalize(%+v) = %v, want %v", cases[i].b, err, cases[i].err)
+		}
+	}
+
+	// 星期三調整到星期一
+	if cases[len(cases)-1].b.Start != "2022-10-17" {
+		t.Fatalf("unexpected start %s", cases[len(cases)-1].b.Start)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	cases := []struct {
+		period, date, start, end string
+	}{
+		{bundle.BudgetWeek, "2022-10-16", "2022-10-10", "2022-10-16"},
+		{bundle.BudgetWeek, "2022-10-17", "2022-10-17", "2022-10-23"},
+		{bundle.BudgetMonth, "2024-02-10", "2024-02-01", "2024-02-29"},
+		{bundle.BudgetYear, "2022-10-19", "2022-01-01", "2022-12-31"},
+	}
+
+	for _, c := range cases {
+		start, end := Bounds(c.period, date(c.date))
+		if start.Format(dateFormat) != c.start || end.Format(dateFormat) != c.end {
+			t.Errorf("Bounds(%s, %s) = %v, %v", c.period, c.date, start, end)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	b := bundle.Budget{MainId: 1, Period: bundle.BudgetMonth, Amount: 8000, Start: "2022-08-01"}
+	sums := map[string]int{
+		"2022-08-01": 5000,
+		"2022-09-01": 12000,
+		"2022-10-01": 3000,
+	}
+
+	st := Status(b, sums, date("2022-10-10"))
+	if st.PeriodStart != "2022-10-01" || st.PeriodEnd != "2022-10-31" {
+		t.Fatalf("unexpected period %+v", st)
+	}
+
+	if st.Carry != 0 || st.Limit != 8000 || st.Spent != 3000 || st.Remaining != 5000 || st.Projected != 9300 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	// 八月剩 3000，九月超支 4000 後歸零，不扣減十月的預算
+	b.Rollover = true
+	st = Status(b, sums, date("2022-10-10"))
+	if st.Carry != 0 || st.Limit != 8000 || st.Remaining != 5000 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	// 九月用完八月結轉的 3000，十月不能再用一次
+	sums["2022-09-01"] = 11000
+	st = Status(b, sums, date("2022-10-10"))
+	if st.Carry != 0 || st.Limit != 8000 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	sums["2022-09-01"] = 7000
+	st = Status(b, sums, date("2022-10-10"))
+	if st.Carry != 4000 || st.Limit != 12000 || st.Remaining != 9000 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+}
@@ -1,6 +1,9 @@
 package bundle
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 var (
 	CodeOk           = "E-000"
@@ -17,6 +20,81 @@ var (
 	CodeTypeRepeat   = "E-018" // 類別名稱重複
 	CodeNoData       = "E-019" // 沒有資料
 	CodeEmptyContent = "E-020" // 沒有輸入關鍵字
+	CodeQuickParse   = "E-021" // 快速輸入無法解析
+	CodeDuplicate    = "E-022" // 可能重複
+	CodeIdempotency  = "E-023" // 冪等鍵已用於不同的請求
+	CodeProcessing   = "E-024" // 相同冪等鍵的請求處理中
+	CodeImport       = "E-025" // 匯入資料有誤
+	CodeArchive      = "E-026" // 備份檔損毀
+	CodeVersion      = "E-027" // 備份檔版本不相容
+	CodeRule         = "E-028" // 規則格式錯誤
+	CodeAccount      = "E-029" // 帳戶類型錯誤或無法刪除
+	CodeTransfer     = "E-030" // 轉出與轉入帳戶相同
+	CodeCurrency     = "E-031" // 幣別錯誤
+	CodeRate         = "E-032" // 缺少匯率，無法換算成基準幣別
+	CodeAmount       = "E-033" // 金額格式錯誤或小數位數超過幣別
+	CodeRefund       = "E-034" // 原帳單不存在、類別或幣別不同，或退款超過原金額
+	CodeBudget       = "E-035" // 預算週期、類別或開始日期錯誤
+	CodeAlert        = "E-036" // 提醒金額、Webhook 網址或電子郵件錯誤
+	CodeRecurring    = "E-037" // 定期帳單的週期、日期或次數錯誤，或該次已經建立帳單
+	CodeBaseCurrency = "E-038" // 還有以原基準幣別記錄的期初餘額、轉帳、預算或提醒金額
+)
+
+// 帳戶類型
+const (
+	AccountCash       = "cash"        // 現金
+	AccountBank       = "bank"        // 銀行
+	AccountCreditCard = "credit_card" // 信用卡
+	AccountEWallet    = "e_wallet"    // 電子錢包
+)
+
+// 規則比對欄位
+const (
+	RuleFieldName   = "name"   // 名稱
+	RuleFieldRemark = "remark" // 備註
+	RuleFieldAny    = "any"    // 名稱或備註
+)
+
+// 規則比對方式
+const (
+	RuleMatchContains = "contains" // 包含
+	RuleMatchRegex    = "regex"    // 正規表示式
+	RuleMatchFuzzy    = "fuzzy"    // 模糊
+)
+
+// 預算週期
+const (
+	BudgetWeek  = "week"  // 每週，從星期一開始
+	BudgetMonth = "month" // 每月
+	BudgetYear  = "year"  // 每年
+)
+
+// 提醒種類
+const (
+	AlertBudget = "budget" // 預算達到門檻
+	AlertBill   = "bill"   // 單筆帳單超過提醒金額
+)
+
+// 定期帳單週期
+const (
+	RecurDaily   = "daily"   // 每天
+	RecurWeekly  = "weekly"  // 每週，與開始日期同一個星期幾
+	RecurMonthly = "monthly" // 每月
+	RecurYearly  = "yearly"  // 每年，與開始日期同一個月
+)
+
+// 搜尋模式
+const (
+	SearchModeFuzzy     = "fuzzy"     // 模糊
+	SearchModeSubstring = "substring" // 子字串
+	SearchModeExact     = "exact"     // 完全相同
+)
+
+// 匯入金額正負號
+const (
+	SignExpenseNegative = "expense_negative" // 負數為支出
+	SignExpensePositive = "expense_positive" // 正數為支出
+	SignAbsolute        = "absolute"         // 取絕對值，收支依類別決定
 )
 
 // 全部類型
@@ -44,13 +122,77 @@ type Sub struct {
 }
 
 type Item struct {
-	Id     int       `json:"id" db:"id"`
-	Name   string    `json:"name" db:"name"`
-	MainId int       `json:"main_id" db:"main_id"`
-	SubId  int       `json:"sub_id" db:"sub_id"`
-	Price  int       `json:"price" db:"price"`
-	Remark string    `json:"remark" db:"remark"`
-	Date   time.Time `json:"date" db:"date"`
+	Id        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	MainId    int       `json:"main_id" db:"main_id"`
+	SubId     int       `json:"sub_id" db:"sub_id"`
+	Price     int       `json:"price" db:"price"` // 幣別的最小單位
+	Remark    string    `json:"remark" db:"remark"`
+	Date      time.Time `json:"date" db:"date"`
+	AccountId int       `json:"account_id" db:"account_id"`
+	Currency  string    `json:"currency" db:"currency"`
+	Scale     int       `json:"scale" db:"scale"` // 幣別的小數位數
+	Refund    bool      `json:"refund" db:"refund"`
+	RefundOf  int       `json:"refund_of" db:"refund_of"`
+}
+
+// 新增或修改的帳單，金額已換算成幣別的最小單位
+type Bill struct {
+	Id        int    `json:"id"`
+	SubId     int    `json:"sub_id"`
+	Name      string `json:"name"`
+	Price     int    `json:"price"`
+	Remark    string `json:"remark"`
+	Date      string `json:"date"`
+	AccountId int    `json:"account_id"` // 0 表示預設帳戶，修改時表示不變更
+	Currency  string `json:"currency"`   // 空白表示基準幣別，修改時表示不變更
+	Refund    bool   `json:"refund"`     // 退款或調整，金額從同類別扣除
+	RefundOf  int    `json:"refund_of"`  // 原帳單，0 表示沒有
+}
+
+// 帳戶
+type Account struct {
+	Id      int    `json:"id" db:"id"`
+	Name    string `json:"name" db:"name"`
+	Type    string `json:"type" db:"type"`
+	Opening int    `json:"opening" db:"opening"` // 期初餘額
+	Balance int    `json:"balance" db:"balance"` // 目前餘額
+}
+
+// 帳戶明細，balance 為該筆之後的餘額
+//
+// 轉帳的 transfer_id 不為 0，名稱為對方帳戶，increase 表示轉入或轉出
+type AccountEntry struct {
+	PreviewItem
+	TransferId int `json:"transfer_id,omitempty" db:"transfer_id"`
+	Balance    int `json:"balance" db:"balance"`
+}
+
+// 匯率，一單位 currency 等於 rate 單位的 base
+//
+// 換算時使用帳單日期當天或之前最近的匯率，沒有時回傳 E-032
+type Rate struct {
+	Id       int     `json:"id" db:"id"`
+	Base     string  `json:"base" db:"base"` // 基準幣別
+	Currency string  `json:"currency" db:"currency"`
+	Date     string  `json:"date" db:"date"`
+	Rate     float64 `json:"rate" db:"rate"`
+}
+
+// 帳戶間轉帳，不計入收入與支出
+type Transfer struct {
+	Id     int    `json:"id" db:"id"`
+	FromId int    `json:"from_id" db:"from_id"` // 轉出帳戶
+	ToId   int    `json:"to_id" db:"to_id"`     // 轉入帳戶
+	Amount int    `json:"amount" db:"amount"`
+	Remark string `json:"remark" db:"remark"`
+	Date   string `json:"date" db:"date"`
+}
+
+// 符合區間 [start, end)，以字元 (rune) 計算
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // 預覽項目
@@ -62,8 +204,288 @@ type PreviewItem struct {
 	SubName  string `json:"sub_name" db:"sub_name"`
 	Name     string `json:"name" db:"name"`
 	Increase int    `json:"increase" db:"increase"`
-	Price    int    `json:"price" db:"price"`
+	Price    int    `json:"price" db:"price"` // 幣別的最小單位
+	Remark   string `json:"remark" db:"remark"`
 	Date     string `json:"date" db:"date"`
+	// 帳戶
+	AccountId int `json:"account_id" db:"account_id"`
+	// 幣別與小數位數，price 除以 10 的 scale 次方為實際金額
+	Currency string `json:"currency" db:"currency"`
+	Scale    int    `json:"scale" db:"scale"`
+	// 退款或調整，金額從同類別扣除，refund_of 為原帳單，0 表示沒有
+	Refund   bool `json:"refund" db:"refund"`
+	RefundOf int  `json:"refund_of" db:"refund_of"`
+
+	// 搜尋結果，沒有 score 表示沒有排序，0 也是有效的分數
+	Score           *int        `json:"score,omitempty" db:"-"`
+	NameHighlight   []Highlight `json:"name_highlight,omitempty" db:"-"`
+	RemarkHighlight []Highlight `json:"remark_highlight,omitempty" db:"-"`
+}
+
+// 搜尋條件
+type SearchQuery struct {
+	Keyword string // 關鍵字
+	Mode    string // 搜尋模式
+	Name    bool   // 搜尋名稱
+	Remark  bool   // 搜尋備註
+	Start   string // 起始日期
+	End     string // 結束日期
+	Limit   int    // 候選筆數上限，分頁在重新排序後進行
+}
+
+// 名稱建議
+type NameSuggestion struct {
+	Name     string `json:"name" db:"name"`
+	SubId    int    `json:"sub_id" db:"sub_id"`       // 最後一次使用的子類別
+	Price    int    `json:"price" db:"price"`         // 最後一次使用的金額
+	Count    int    `json:"count" db:"count"`         // 使用次數
+	LastDate string `json:"last_date" db:"last_date"` // 最後使用日期
+}
+
+// 類別建議
+type CategorySuggestion struct {
+	SubId      int     `json:"sub_id"`
+	Confidence float64 `json:"confidence"` // 信心度 0 ~ 1
+}
+
+// CSV 欄位對應
+//
+// 欄位可以填標題名稱，或從 1 開始的欄位編號
+type CsvMapping struct {
+	Header     bool   `json:"header"`      // 第一行為標題
+	Delimiter  string `json:"delimiter"`   // 分隔符號，預設 ","
+	Encoding   string `json:"encoding"`    // utf-8 或 big5，預設 utf-8
+	DateFormat string `json:"date_format"` // 例如 yyyy/mm/dd，預設 yyyy-mm-dd
+	Sign       string `json:"sign"`        // 金額正負號，預設負數為支出
+	Create     bool   `json:"create"`      // 自動建立找不到的類別
+	AccountId  int    `json:"account_id"`  // 匯入的帳戶，0 表示預設帳戶
+
+	Currency string `json:"currency"` // 幣別欄位，省略時使用基準幣別
+
+	Date   string `json:"date"`   // 日期欄位
+	Amount string `json:"amount"` // 金額欄位
+	Main   string `json:"main"`   // 主類別欄位，可省略
+	Sub    string `json:"sub"`    // 子類別欄位
+	Name   string `json:"name"`   // 名稱欄位，省略時使用子類別名稱
+	Remark string `json:"remark"` // 備註欄位，可省略
+}
+
+// 匯入的一筆資料
+type ImportRow struct {
+	Line     int    `json:"line"` // 來源行號
+	Date     string `json:"date"`
+	Name     string `json:"name"`
+	MainId   int    `json:"main_id"` // 0 表示需要建立
+	MainName string `json:"main_name"`
+	SubId    int    `json:"sub_id"` // 0 表示需要建立
+	SubName  string `json:"sub_name"`
+	Increase int    `json:"increase"`
+	Price    int    `json:"price"`
+	Remark   string `json:"remark"`
+	Currency string `json:"currency,omitempty"` // 空白表示基準幣別
+	FitId    string `json:"fit_id,omitempty"`   // 銀行交易編號，重複匯入時略過
+	Skipped  bool   `json:"skipped,omitempty"`  // 已經匯入過或與現有帳單重複
+	Error    string `json:"error,omitempty"`    // 無法匯入的原因
+}
+
+// 自動分類規則
+//
+// 條件：名稱或備註符合關鍵字、金額在範圍內。動作：變更子類別、改名、附加備註
+type Rule struct {
+	Id       int    `json:"id" db:"id"`
+	Field    string `json:"field" db:"match_field"` // 比對欄位，預設名稱
+	Match    string `json:"match" db:"match_type"`  // 比對方式，預設包含
+	Keyword  string `json:"keyword" db:"keyword"`
+	MinPrice int    `json:"min_price" db:"min_price"` // 幣別的最小單位，0 表示不限
+	MaxPrice int    `json:"max_price" db:"max_price"` // 幣別的最小單位，0 表示不限
+	SubId    int    `json:"sub_id" db:"sub_id"`       // 0 表示不變更
+	Rename   string `json:"rename" db:"rename"`       // 空白表示不變更
+	Remark   string `json:"remark" db:"remark"`       // 附加的備註
+	Priority int    `json:"priority" db:"priority"`   // 數字小的先比對
+}
+
+// 預算，主類別與子類別只會指定其中一個，金額為基準幣別的最小單位
+//
+// 開始日期是期間的第一天，rollover 從開始日期累計未用完的金額
+type Budget struct {
+	Id       int    `json:"id" db:"id"`
+	MainId   int    `json:"main_id" db:"main_id"`
+	SubId    int    `json:"sub_id" db:"sub_id"`
+	Name     string `json:"name" db:"name"` // 類別名稱
+	Period   string `json:"period" db:"period"`
+	Amount   int    `json:"amount" db:"amount"`
+	Rollover bool   `json:"rollover" db:"rollover"`
+	Start    string `json:"start" db:"start"`
+}
+
+// 預算在期間內的狀態，金額為基準幣別的最小單位
+type BudgetStatus struct {
+	Budget
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	Carry       int    `json:"carry"`     // 前期結轉
+	Limit       int    `json:"limit"`     // 本期可用，預算加上結轉
+	Spent       int    `json:"spent"`     // 本期支出，退款已扣除
+	Remaining   int    `json:"remaining"` // 剩餘，超支時為負數
+	Projected   int    `json:"projected"` // 依目前速度預估的期末支出
+}
+
+// 提醒，同時是站內收件匣
+//
+// key 相同的提醒只會產生一次，預算提醒的 key 包含期間與門檻
+type Alert struct {
+	Id       int       `json:"id" db:"id"`
+	Kind     string    `json:"kind" db:"kind"`
+	Key      string    `json:"-" db:"key"`
+	Message  string    `json:"message" db:"message"`
+	BudgetId int       `json:"budget_id,omitempty" db:"budget_id"`
+	ItemId   int       `json:"item_id,omitempty" db:"item_id"`
+	Read     bool      `json:"read" db:"read"`
+	Created  time.Time `json:"created" db:"created"`
+}
+
+// 提醒設定，站內收件匣一定會收到，webhook 與 email 空白表示不通知
+type AlertSetting struct {
+	BillThreshold int    `json:"bill_threshold" db:"bill_threshold"` // 基準幣別的最小單位，0 表示不提醒
+	Webhook       string `json:"webhook" db:"webhook"`
+	Email         string `json:"email" db:"email"`
+}
+
+// 定期帳單範本，金額為幣別的最小單位，帳戶為 0 時使用預設帳戶
+//
+// 每月與每年預設是開始日期的同一天，沒有這一天時為月底；nth 不為 0 時是第 nth 個星期 weekday，-1 表示最後一個。
+// end 與 count 可以同時指定，先到者結束
+type Recurring struct {
+	Id        int    `json:"id" db:"id"`
+	UserId    int    `json:"-" db:"user_id"`
+	SubId     int    `json:"sub_id" db:"sub_id"`
+	Name      string `json:"name" db:"name"`
+	Price     int    `json:"price" db:"price"`
+	Remark    string `json:"remark" db:"remark"`
+	AccountId int    `json:"account_id" db:"account_id"`
+	Currency  string `json:"currency" db:"currency"`
+	Frequency string `json:"frequency" db:"frequency"`
+	Interval  int    `json:"interval" db:"step"` // 每幾個週期一次
+	Nth       int    `json:"nth" db:"nth"`
+	Weekday   int    `json:"weekday" db:"weekday"` // 0 為星期日
+	Start     string `json:"start" db:"start"`
+	End       string `json:"end" db:"end_date"` // 空白表示沒有結束日期
+	Count     int    `json:"count" db:"count"`  // 0 表示不限次數
+	// 已建立帳單到這一天，空白表示尚未建立
+	Materialized string `json:"materialized" db:"materialized"`
+}
+
+// 定期帳單單次的例外，略過或修改該次的名稱、金額與備註，空白或 0 表示不變更
+type RecurringException struct {
+	RecurringId int    `json:"recurring_id" db:"recurring_id"`
+	Date        string `json:"date" db:"date"`
+	Skip        bool   `json:"skip" db:"skip"`
+	Name        string `json:"name" db:"name"`
+	Price       int    `json:"price" db:"price"`
+	Remark      string `json:"remark" db:"remark"`
+}
+
+// 即將發生的定期帳單，已套用例外
+type Occurrence struct {
+	RecurringId int    `json:"recurring_id"`
+	Date        string `json:"date"`
+	SubId       int    `json:"sub_id"`
+	Name        string `json:"name"`
+	Price       int    `json:"price"`
+	Remark      string `json:"remark"`
+	Currency    string `json:"currency"`
+	Scale       int    `json:"scale"`
+	Skip        bool   `json:"skip"`
+	Edited      bool   `json:"edited"` // 有修改該次的內容
+}
+
+// 規則套用結果
+type RuleTestResult struct {
+	Item   PreviewItem `json:"item"`   // 原本的帳單
+	SubId  int         `json:"sub_id"` // 套用後
+	Name   string      `json:"name"`
+	Remark string      `json:"remark"`
+}
+
+// 備份內容
+type ArchiveData struct {
+	Accounts  []ArchiveAccount `json:"accounts"`
+	MainTypes []ArchiveMain    `json:"main_types"`
+	SubTypes  []ArchiveSub     `json:"sub_types"`
+	Bills     []ArchiveBill    `json:"bills"`
+	Rules     []Rule           `json:"rules"`
+	Transfers []Transfer       `json:"transfers"`
+	Rates     []Rate           `json:"rates"`
+	Budgets   []Budget         `json:"budgets"`
+	Recurring []Recurring      `json:"recurring"`
+	Settings  ArchiveSettings  `json:"settings"`
+	// 定期帳單單次的略過與修改
+	RecurringExceptions []RecurringException `json:"recurring_exceptions"`
+}
+
+// 備份的設定，期初餘額、轉帳、預算與提醒金額都以這裡的基準幣別記錄
+type ArchiveSettings struct {
+	Currency string       `json:"currency"` // 舊版備份沒有，視為目前的基準幣別
+	Alert    AlertSetting `json:"alert"`
+}
+
+// 備份的帳戶
+type ArchiveAccount struct {
+	Id      int    `json:"id" db:"id"`
+	Name    string `json:"name" db:"name"`
+	Type    string `json:"type" db:"type"`
+	Opening int    `json:"opening" db:"opening"`
+	Deleted bool   `json:"deleted" db:"deleted"`
+}
+
+// 備份的主類別
+type ArchiveMain struct {
+	Id      int    `json:"id" db:"id"`
+	Name    string `json:"name" db:"name"`
+	Deleted bool   `json:"deleted" db:"deleted"`
+}
+
+// 備份的子類別
+type ArchiveSub struct {
+	Id       int    `json:"id" db:"id"`
+	MainId   int    `json:"main_id" db:"main_id"`
+	Name     string `json:"name" db:"name"`
+	Increase int    `json:"increase" db:"increase"`
+	Deleted  bool   `json:"deleted" db:"deleted"`
+}
+
+// 備份的帳單
+type ArchiveBill struct {
+	Id     int    `json:"id" db:"id"`
+	SubId  int    `json:"sub_id" db:"sub_id"`
+	Name   string `json:"name" db:"name"`
+	Price  int    `json:"price" db:"price"`
+	Remark string `json:"remark" db:"remark"`
+	Date   string `json:"date" db:"date"`
+	FitId  string `json:"fit_id,omitempty" db:"fit_id"`
+	// 舊版備份沒有帳戶，還原到預設帳戶
+	AccountId int `json:"account_id,omitempty" db:"account_id"`
+	// 舊版備份沒有幣別，還原成基準幣別
+	Currency string `json:"currency,omitempty" db:"currency"`
+	// 退款與原帳單，原帳單是備份內的編號
+	Refund   bool `json:"refund,omitempty" db:"refund"`
+	RefundOf int  `json:"refund_of,omitempty" db:"refund_of"`
+	// 定期帳單建立的帳單，範本是備份內的編號
+	RecurringId   int    `json:"recurring_id,omitempty" db:"recurring_id"`
+	RecurringDate string `json:"recurring_date,omitempty" db:"recurring_date"`
+}
+
+// 還原筆數
+type RestoreResult struct {
+	Accounts  int `json:"accounts"`
+	MainTypes int `json:"main_types"`
+	SubTypes  int `json:"sub_types"`
+	Bills     int `json:"bills"`
+	Rules     int `json:"rules"`
+	Transfers int `json:"transfers"`
+	Rates     int `json:"rates"`
+	Budgets   int `json:"budgets"`
+	Recurring int `json:"recurring"`
 }
 
 // 月結花費
@@ -113,16 +535,262 @@ type CreateSubTypeResponse struct {
 // 建立項目請求
 // swagger:model CreateItemRequest
 type CreateItemRequest struct {
-	SubId  int    `json:"sub_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"0"`
-	Name   string `json:"name" validate:"required,min=2,max=32" swaggertype:"string" example:"name"`
-	Price  int    `json:"price" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"100"`
-	Remark string `json:"remark" validate:"required,min=0,max=64" swaggertype:"string" example:""`
-	Date   string `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	SubId  int         `json:"sub_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"0"`
+	Name   string      `json:"name" validate:"required,min=2,max=32" swaggertype:"string" example:"name"`
+	Price  json.Number `json:"price" binding:"required" validate:"required,gt=0" swaggertype:"string" example:"45.5"`
+	Remark string      `json:"remark" validate:"required,min=0,max=64" swaggertype:"string" example:""`
+	Date   string      `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	// 帳戶，0 表示預設帳戶
+	AccountId int `json:"account_id" validate:"min=0" swaggertype:"integer" example:"0"`
+	// 幣別，空白表示基準幣別
+	Currency string `json:"currency" validate:"max=3" swaggertype:"string" example:"TWD"`
+	// 退款或調整，金額從同類別扣除
+	Refund bool `json:"refund" swaggertype:"boolean" example:"false"`
+	// 原帳單，0 表示沒有，需要相同的子類別與幣別
+	RefundOf int `json:"refund_of" validate:"min=0" swaggertype:"integer" example:"0"`
+	// 忽略重複檢查
+	Force bool `json:"force" swaggertype:"boolean" example:"false"`
 }
 
 // 建立項目回應
 type CreateItemResponse struct {
 	ErrorResponse
+	ItemId     int           `json:"item_id,omitempty"`
+	Duplicates []PreviewItem `json:"duplicates,omitempty"` // 可能重複的帳單
+	RuleId     int           `json:"rule_id,omitempty"`    // 套用的規則
+}
+
+// 快速輸入請求
+// swagger:model QuickItemRequest
+type QuickItemRequest struct {
+	// 例如 "昨天 午餐 排骨飯 120 #公司 // 備註"
+	Text string `json:"text" binding:"required" validate:"required,max=128" swaggertype:"string" example:"昨天 午餐 排骨飯 120 #公司"`
+	// 是否直接建立
+	Commit bool `json:"commit" swaggertype:"boolean" example:"false"`
+	// 忽略重複檢查
+	Force bool `json:"force" swaggertype:"boolean" example:"false"`
+}
+
+// 快速輸入回應
+type QuickItemResponse struct {
+	ErrorResponse
+	Item       CreateItemRequest `json:"item"`                 // 解析結果
+	Committed  bool              `json:"committed"`            // 已建立
+	Duplicates []PreviewItem     `json:"duplicates,omitempty"` // 可能重複的帳單
+	RuleId     int               `json:"rule_id,omitempty"`    // 套用的規則
+}
+
+// 匯入結果
+type ImportResponse struct {
+	ErrorResponse
+	Rows      []ImportRow `json:"rows"`
+	Errors    int         `json:"errors"`    // 有錯誤的筆數
+	Skipped   int         `json:"skipped"`   // 與現有帳單重複而略過的筆數
+	Committed bool        `json:"committed"` // 已寫入
+}
+
+// 銀行對帳單匯入結果
+type StatementImportResponse struct {
+	ErrorResponse
+	Rows      []ImportRow `json:"rows"`
+	Created   int         `json:"created"`   // 新增筆數
+	Skipped   int         `json:"skipped"`   // 已匯入過而略過的筆數
+	Unmatched int         `json:"unmatched"` // 找不到類別或格式錯誤的筆數
+	Committed bool        `json:"committed"` // 已寫入
+}
+
+// 還原結果，只計算新增的資料
+type RestoreResponse struct {
+	ErrorResponse
+	RestoreResult
+}
+
+// 建立規則請求
+// swagger:model CreateRuleRequest
+type CreateRuleRequest struct {
+	Field    string `json:"field" swaggertype:"string" enums:"name,remark,any" example:"name"`
+	Match    string `json:"match" swaggertype:"string" enums:"contains,regex,fuzzy" example:"contains"`
+	Keyword  string `json:"keyword" validate:"max=64" swaggertype:"string" example:"7-ELEVEN"`
+	MinPrice int    `json:"min_price" swaggertype:"integer" example:"0"`
+	MaxPrice int    `json:"max_price" swaggertype:"integer" example:"0"`
+	SubId    int    `json:"sub_id" swaggertype:"integer" example:"0"`
+	Rename   string `json:"rename" validate:"max=32" swaggertype:"string" example:""`
+	Remark   string `json:"remark" validate:"max=64" swaggertype:"string" example:""`
+	Priority int    `json:"priority" swaggertype:"integer" example:"0"`
+}
+
+// 建立帳戶請求
+// swagger:model CreateAccountRequest
+type CreateAccountRequest struct {
+	Name    string      `json:"name" binding:"required" validate:"required,min=1,max=32" swaggertype:"string" example:"現金"`
+	Type    string      `json:"type" swaggertype:"string" enums:"cash,bank,credit_card,e_wallet" example:"cash"`
+	Opening json.Number `json:"opening" swaggertype:"string" example:"0"`
+}
+
+// 建立帳戶回應
+type CreateAccountResponse struct {
+	ErrorResponse
+	AccountId int `json:"account_id"`
+}
+
+// 取得帳戶清單
+type GetAccountsResponse struct {
+	ErrorResponse
+	List []Account `json:"list"`
+}
+
+// 取得帳戶明細
+type GetAccountItemsResponse struct {
+	ErrorResponse
+	Opening int            `json:"opening"` // 區間開始前的餘額
+	List    []AccountEntry `json:"list"`
+}
+
+// 建立轉帳請求
+// swagger:model CreateTransferRequest
+type CreateTransferRequest struct {
+	FromId int         `json:"from_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"1"`
+	ToId   int         `json:"to_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"2"`
+	Amount json.Number `json:"amount" binding:"required" validate:"required,gt=0" swaggertype:"string" example:"1000"`
+	Remark string      `json:"remark" validate:"max=64" swaggertype:"string" example:""`
+	Date   string      `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+}
+
+// 建立轉帳回應
+type CreateTransferResponse struct {
+	ErrorResponse
+	TransferId int `json:"transfer_id"`
+}
+
+// 取得轉帳清單
+type GetTransfersResponse struct {
+	ErrorResponse
+	List []Transfer `json:"list"`
+}
+
+// 取得基準幣別
+type GetCurrencyResponse struct {
+	ErrorResponse
+	Currency string `json:"currency"`
+	Scale    int    `json:"scale"` // 小數位數，統計與餘額都是基準幣別的最小單位
+}
+
+// 建立匯率請求
+// swagger:model CreateRateRequest
+type CreateRateRequest struct {
+	Currency string  `json:"currency" binding:"required" validate:"required,len=3" swaggertype:"string" example:"USD"`
+	Date     string  `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	Rate     float64 `json:"rate" binding:"required" validate:"required,gt=0" swaggertype:"number" example:"30.5"`
+}
+
+// 建立匯率回應
+type CreateRateResponse struct {
+	ErrorResponse
+	RateId int `json:"rate_id"`
+}
+
+// 取得匯率清單
+type GetRatesResponse struct {
+	ErrorResponse
+	List []Rate `json:"list"`
+}
+
+// 匯入匯率回應
+type ImportRatesResponse struct {
+	ErrorResponse
+	Count int    `json:"count"`           // 新增或更新的筆數
+	Error string `json:"error,omitempty"` // 無法匯入的原因
+}
+
+// 建立預算請求
+// swagger:model CreateBudgetRequest
+type CreateBudgetRequest struct {
+	MainId   int         `json:"main_id" validate:"min=0" swaggertype:"integer" example:"1"`
+	SubId    int         `json:"sub_id" validate:"min=0" swaggertype:"integer" example:"0"`
+	Period   string      `json:"period" swaggertype:"string" enums:"week,month,year" example:"month"`
+	Amount   json.Number `json:"amount" binding:"required" swaggertype:"string" example:"8000"`
+	Rollover bool        `json:"rollover" swaggertype:"boolean" example:"false"`
+	Start    string      `json:"start" swaggertype:"string" example:"2006-01-02"`
+}
+
+// 建立預算回應
+type CreateBudgetResponse struct {
+	ErrorResponse
+	BudgetId int `json:"budget_id"`
+}
+
+// 取得預算清單
+type GetBudgetsResponse struct {
+	ErrorResponse
+	List []Budget `json:"list"`
+}
+
+// 取得預算狀態
+type GetBudgetStatusResponse struct {
+	ErrorResponse
+	Date string         `json:"date"`
+	List []BudgetStatus `json:"list"`
+}
+
+// 建立定期帳單請求，price 與 currency 的格式與建立項目相同
+// swagger:model CreateRecurringRequest
+type CreateRecurringRequest struct {
+	SubId     int         `json:"sub_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"1"`
+	Name      string      `json:"name" binding:"required" validate:"required,min=1,max=32" swaggertype:"string" example:"房租"`
+	Price     json.Number `json:"price" binding:"required" swaggertype:"string" example:"15000"`
+	Remark    string      `json:"remark" validate:"max=64" swaggertype:"string" example:""`
+	AccountId int         `json:"account_id" validate:"min=0" swaggertype:"integer" example:"0"`
+	Currency  string      `json:"currency" validate:"omitempty,len=3" swaggertype:"string" example:""`
+	Frequency string      `json:"frequency" swaggertype:"string" enums:"daily,weekly,monthly,yearly" example:"monthly"`
+	Interval  int         `json:"interval" validate:"min=0" swaggertype:"integer" example:"1"`
+	Nth       int         `json:"nth" swaggertype:"integer" example:"0"`
+	Weekday   int         `json:"weekday" swaggertype:"integer" example:"0"`
+	Start     string      `json:"start" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	End       string      `json:"end" swaggertype:"string" example:""`
+	Count     int         `json:"count" validate:"min=0" swaggertype:"integer" example:"0"`
+}
+
+// 建立定期帳單回應
+type CreateRecurringResponse struct {
+	ErrorResponse
+	RecurringId int `json:"recurring_id"`
+}
+
+// 取得定期帳單
+type GetRecurringResponse struct {
+	ErrorResponse
+	List []Recurring `json:"list"`
+}
+
+// 取得即將發生的定期帳單
+type GetUpcomingResponse struct {
+	ErrorResponse
+	List []Occurrence `json:"list"`
+}
+
+// 取得提醒
+type GetAlertsResponse struct {
+	ErrorResponse
+	List   []Alert `json:"list"`
+	Unread int     `json:"unread"` // 未讀數量
+}
+
+// 取得提醒設定
+type GetAlertSettingResponse struct {
+	ErrorResponse
+	AlertSetting
+}
+
+// 建立規則回應
+type CreateRuleResponse struct {
+	ErrorResponse
+	RuleId int `json:"rule_id"`
+}
+
+// 取得規則清單
+type GetRulesResponse struct {
+	ErrorResponse
+	List []Rule `json:"list"`
 }
 
 // 回應
@@ -155,7 +823,8 @@ type GetItemResponse struct {
 // 取得多個
 type GetItemsResponse struct {
 	ErrorResponse
-	List []PreviewItem `json:"list"`
+	List      []PreviewItem `json:"list"`
+	Truncated bool          `json:"truncated,omitempty"` // 搜尋的候選超過上限，只排序了相似度最高的部分
 }
 
 // 取得主類別清單
@@ -169,6 +838,29 @@ type GetSpendByMonthlyResponse struct {
 	List []Monthly `json:"list"`
 }
 
+// 取得類別建議
+type GetCategorySuggestionResponse struct {
+	ErrorResponse
+	List []CategorySuggestion `json:"list"`
+}
+
+// 重複的帳單
+type DuplicateGroup struct {
+	Items []PreviewItem `json:"items"`
+}
+
+// 取得重複帳單
+type GetDuplicatesResponse struct {
+	ErrorResponse
+	List []DuplicateGroup `json:"list"`
+}
+
+// 取得名稱建議
+type GetNameSuggestionResponse struct {
+	ErrorResponse
+	List []NameSuggestion `json:"list"`
+}
+
 // 取得子類別清單
 type GetSubTypeResponse struct {
 	ErrorResponse
@@ -183,12 +875,19 @@ type GetSumByMainTypeResponse struct {
 // 更新項目請求
 // swagger:model UpdateItemRequest
 type UpdateItemRequest struct {
-	ItemId int    `json:"item_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
-	SubId  int    `json:"sub_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"0"`
-	Name   string `json:"name" validate:"required,min=2,max=32" swaggertype:"string" example:"name"`
-	Price  int    `json:"price" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"100"`
-	Remark string `json:"remark" validate:"required,min=0,max=64" swaggertype:"string" example:""`
-	Date   string `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	ItemId int         `json:"item_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	SubId  int         `json:"sub_id" binding:"required" validate:"required,gt=0" swaggertype:"integer" example:"0"`
+	Name   string      `json:"name" validate:"required,min=2,max=32" swaggertype:"string" example:"name"`
+	Price  json.Number `json:"price" binding:"required" validate:"required,gt=0" swaggertype:"string" example:"45.5"`
+	Remark string      `json:"remark" validate:"required,min=0,max=64" swaggertype:"string" example:""`
+	Date   string      `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	// 帳戶，0 表示不變更
+	AccountId int `json:"account_id" validate:"min=0" swaggertype:"integer" example:"0"`
+	// 幣別，空白表示不變更
+	Currency string `json:"currency" validate:"max=3" swaggertype:"string" example:""`
+	// 退款或調整與原帳單
+	Refund   bool `json:"refund" swaggertype:"boolean" example:"false"`
+	RefundOf int  `json:"refund_of" validate:"min=0" swaggertype:"integer" example:"0"`
 }
 
 // 更新項目回應
@@ -230,3 +929,159 @@ type DeleteMainTypeResponse struct {
 type DeleteSubTypeResponse struct {
 	ErrorResponse
 }
+
+// 更新帳戶請求
+// swagger:model UpdateAccountRequest
+type UpdateAccountRequest struct {
+	AccountId int `json:"account_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	CreateAccountRequest
+}
+
+// 更新帳戶回應
+type UpdateAccountResponse struct {
+	ErrorResponse
+}
+
+// 更新轉帳請求
+// swagger:model UpdateTransferRequest
+type UpdateTransferRequest struct {
+	TransferId int `json:"transfer_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	CreateTransferRequest
+}
+
+// 更新轉帳回應
+type UpdateTransferResponse struct {
+	ErrorResponse
+}
+
+// 更新基準幣別請求
+// swagger:model UpdateCurrencyRequest
+type UpdateCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required" validate:"required,len=3" swaggertype:"string" example:"TWD"`
+}
+
+// 更新基準幣別回應
+type UpdateCurrencyResponse struct {
+	ErrorResponse
+}
+
+// 更新預算請求
+// swagger:model UpdateBudgetRequest
+type UpdateBudgetRequest struct {
+	BudgetId int `json:"budget_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	CreateBudgetRequest
+}
+
+// 更新預算回應
+type UpdateBudgetResponse struct {
+	ErrorResponse
+}
+
+// 提醒已讀請求
+// swagger:model ReadAlertsRequest
+type ReadAlertsRequest struct {
+	AlertIds []int `json:"alert_ids" swaggertype:"array,integer"` // 空白表示全部
+}
+
+// 提醒已讀回應
+type ReadAlertsResponse struct {
+	ErrorResponse
+}
+
+// 更新提醒設定請求
+// swagger:model UpdateAlertSettingRequest
+type UpdateAlertSettingRequest struct {
+	BillThreshold json.Number `json:"bill_threshold" swaggertype:"string" example:"3000"`
+	Webhook       string      `json:"webhook" validate:"max=256" swaggertype:"string" example:"https://example.com/hook"`
+	Email         string      `json:"email" validate:"max=128" swaggertype:"string" example:"me@example.com"`
+}
+
+// 更新提醒設定回應
+type UpdateAlertSettingResponse struct {
+	ErrorResponse
+}
+
+// 更新定期帳單請求
+// swagger:model UpdateRecurringRequest
+type UpdateRecurringRequest struct {
+	RecurringId int `json:"recurring_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	CreateRecurringRequest
+}
+
+// 更新定期帳單回應
+type UpdateRecurringResponse struct {
+	ErrorResponse
+}
+
+// 略過或修改定期帳單的某一次，skip 為 false 且沒有修改時取消例外
+// swagger:model UpdateOccurrenceRequest
+type UpdateOccurrenceRequest struct {
+	RecurringId int         `json:"recurring_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	Date        string      `json:"date" binding:"required" time_format:"2006-01-02" example:"2006-01-02"`
+	Skip        bool        `json:"skip" swaggertype:"boolean" example:"false"`
+	Name        string      `json:"name" validate:"max=32" swaggertype:"string" example:""`
+	Price       json.Number `json:"price" swaggertype:"string" example:""`
+	Remark      string      `json:"remark" validate:"max=64" swaggertype:"string" example:""`
+}
+
+// 略過或修改定期帳單的某一次回應
+type UpdateOccurrenceResponse struct {
+	ErrorResponse
+}
+
+// 更新規則請求
+// swagger:model UpdateRuleRequest
+type UpdateRuleRequest struct {
+	RuleId int `json:"rule_id" binding:"required" validate:"required,gt=0" swaggertype:"integer"`
+	CreateRuleRequest
+}
+
+// 更新規則回應
+type UpdateRuleResponse struct {
+	ErrorResponse
+}
+
+// 測試規則請求，預設測試最近一年的帳單
+// swagger:model TestRuleRequest
+type TestRuleRequest struct {
+	CreateRuleRequest
+	Start string `json:"start" swaggertype:"string" example:"2006-01-02"`
+	End   string `json:"end" swaggertype:"string" example:"2006-01-02"`
+}
+
+// 測試規則回應
+type TestRuleResponse struct {
+	ErrorResponse
+	List  []RuleTestResult `json:"list"`
+	Total int              `json:"total"` // 符合的筆數，list 最多回傳 200 筆
+}
+
+// 刪除帳戶回應
+type DeleteAccountResponse struct {
+	ErrorResponse
+}
+
+// 刪除轉帳回應
+type DeleteTransferResponse struct {
+	ErrorResponse
+}
+
+// 刪除匯率回應
+type DeleteRateResponse struct {
+	ErrorResponse
+}
+
+// 刪除預算回應
+type DeleteBudgetResponse struct {
+	ErrorResponse
+}
+
+// 刪除定期帳單回應
+type DeleteRecurringResponse struct {
+	ErrorResponse
+}
+
+// 刪除規則回應
+type DeleteRuleResponse struct {
+	ErrorResponse
+}
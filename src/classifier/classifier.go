@@ -0,0 +1,199 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/transformer"
+)
+
+// 字元 n-gram 長度
+const maxGram = 2
+
+// 類別統計
+type class struct {
+	docs   int            // 帳單數
+	tokens map[string]int // 各 n-gram 次數
+	total  int            // n-gram 總數
+}
+
+// 單純貝氏分類器，以名稱和備註的字元 n-gram 預測子類別
+//
+//	https://en.wikipedia.org/wiki/Naive_Bayes_classifier
+type Classifier struct {
+	mu      sync.Mutex
+	classes map[int]*class
+	vocab   map[string]int // n-gram 出現在幾個類別
+	docs    int
+}
+
+func New() *Classifier {
+	return &Classifier{
+		classes: make(map[int]*class),
+		vocab:   make(map[string]int),
+	}
+}
+
+// 加入訓練資料
+func (c *Classifier) Add(subId int, name, remark string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl, ok := c.classes[subId]
+	if !ok {
+		cl = &class{tokens: make(map[string]int)}
+		c.classes[subId] = cl
+	}
+
+	cl.docs++
+	c.docs++
+	for _, t := range tokenize(name, remark) {
+		if cl.tokens[t] == 0 {
+			c.vocab[t]++
+		}
+		cl.tokens[t]++
+		cl.total++
+	}
+}
+
+// 移除訓練資料，用於修改或刪除帳單
+func (c *Classifier) Remove(subId int, name, remark string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl, ok := c.classes[subId]
+	if !ok {
+		return
+	}
+
+	for _, t := range tokenize(name, remark) {
+		if cl.tokens[t] == 0 {
+			continue
+		}
+
+		cl.tokens[t]--
+		cl.total--
+		if cl.tokens[t] == 0 {
+			delete(cl.tokens, t)
+			if c.vocab[t]--; c.vocab[t] == 0 {
+				delete(c.vocab, t)
+			}
+		}
+	}
+
+	cl.docs--
+	c.docs--
+	if cl.docs <= 0 {
+		delete(c.classes, subId)
+	}
+}
+
+// 預測子類別，依信心度由高到低，最多 limit 筆
+func (c *Classifier) Predict(name, remark string, limit int) []bundle.CategorySuggestion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list := make([]bundle.CategorySuggestion, 0)
+	if c.docs == 0 {
+		return list
+	}
+
+	tokens := tokenize(name, remark)
+	vocab := float64(len(c.vocab) + 1)
+
+	// 對數機率
+	logs := make(map[int]float64, len(c.classes))
+	max := math.Inf(-1)
+	for subId, cl := range c.classes {
+		p := math.Log(float64(cl.docs) / float64(c.docs))
+		for _, t := range tokens {
+			// Laplace smoothing
+			p += math.Log((float64(cl.tokens[t]) + 1) / (float64(cl.total) + vocab))
+		}
+
+		logs[subId] = p
+		if p > max {
+			max = p
+		}
+	}
+
+	// 轉換成機率
+	sum := 0.0
+	for _, p := range logs {
+		sum += math.Exp(p - max)
+	}
+
+	for subId, p := range logs {
+		list = append(list, bundle.CategorySuggestion{
+			SubId:      subId,
+			Confidence: math.Exp(p-max) / sum,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Confidence == list[j].Confidence {
+			return list[i].SubId < list[j].SubId
+		}
+		return list[i].Confidence > list[j].Confidence
+	})
+
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	return list
+}
+
+// 切成字元 n-gram，名稱和備註分開計算
+func tokenize(name, remark string) []string {
+	tokens := grams(name, "n:")
+	return append(tokens, grams(remark, "r:")...)
+}
+
+func grams(s, prefix string) []string {
+	s, _, err := transform.String(transformer.NewChineseFold(), s)
+	if err != nil {
+		return nil
+	}
+
+	tokens := make([]string, 0)
+	for _, word := range splitWords(s) {
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(word); i++ {
+				tokens = append(tokens, prefix+string(word[i:i+n]))
+			}
+		}
+	}
+
+	return tokens
+}
+
+// 以空白和標點分開
+func splitWords(s string) [][]rune {
+	words := make([][]rune, 0)
+	word := make([]rune, 0)
+	for _, r := range s {
+		if isSeparator(r) {
+			if len(word) > 0 {
+				words = append(words, word)
+				word = make([]rune, 0)
+			}
+			continue
+		}
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, word)
+	}
+
+	return words
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
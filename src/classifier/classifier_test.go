@@ -0,0 +1,169 @@
+package classifier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+const (
+	subBreakfast = 1
+	subLunch     = 2
+	subMrt       = 3
+)
+
+func newTrained() *Classifier {
+	c := New()
+	c.Add(subBreakfast, "早餐 蛋餅", "")
+	c.Add(subBreakfast, "早餐店", "")
+	c.Add(subBreakfast, "蛋餅 奶茶", "")
+	c.Add(subLunch, "排骨飯", "公司")
+	c.Add(subLunch, "雞腿飯", "公司")
+	c.Add(subLunch, "午餐 便當", "")
+	c.Add(subMrt, "捷運", "上班")
+	c.Add(subMrt, "捷運", "")
+	return c
+}
+
+func TestPredict(t *testing.T) {
+	c := newTrained()
+
+	cases := []struct {
+		name   string
+		remark string
+		want   int
+	}{
+		{"蛋餅", "", subBreakfast},
+		{"控肉飯", "", subLunch},
+		{"捷運", "", subMrt},
+		{"便當", "公司", subLunch},
+		{"捷運", "上班", subMrt},
+	}
+
+	for _, tc := range cases {
+		list := c.Predict(tc.name, tc.remark, 3)
+		if len(list) == 0 || list[0].SubId != tc.want {
+			t.Errorf("Predict(%q, %q) = %v, want %d", tc.name, tc.remark, list, tc.want)
+		}
+	}
+}
+
+func TestPredictConfidence(t *testing.T) {
+	c := newTrained()
+
+	list := c.Predict("捷運", "", 3)
+	sum := 0.0
+	for i, p := range list {
+		sum += p.Confidence
+		if i > 0 && p.Confidence > list[i-1].Confidence {
+			t.Fatalf("not sorted %v", list)
+		}
+	}
+
+	if sum < 0.999 || sum > 1.001 {
+		t.Fatalf("confidence sum %f", sum)
+	}
+
+	if list[0].Confidence < 0.5 {
+		t.Fatalf("low confidence %v", list)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c := New()
+	c.Add(subLunch, "捷運", "")
+	c.Add(subMrt, "捷運", "")
+	c.Remove(subLunch, "捷運", "")
+
+	list := c.Predict("捷運", "", 3)
+	if len(list) != 1 || list[0].SubId != subMrt {
+		t.Fatalf("unexpected %v", list)
+	}
+
+	c.Remove(subMrt, "捷運", "")
+	if list := c.Predict("捷運", "", 3); len(list) != 0 {
+		t.Fatalf("unexpected %v", list)
+	}
+
+	if len(c.vocab) != 0 {
+		t.Fatalf("vocab not empty %v", c.vocab)
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore()
+	loads := 0
+	load := func() ([]bundle.Item, error) {
+		loads++
+		return []bundle.Item{{SubId: subMrt, Name: "捷運"}}, nil
+	}
+
+	if _, ok := s.Loaded(1); ok {
+		t.Fatal("unexpected loaded")
+	}
+
+	s.Get(1, load)
+	s.Get(1, load)
+	if loads != 1 {
+		t.Fatalf("loaded %d times", loads)
+	}
+
+	s.Reset(1)
+	s.Get(1, load)
+	if loads != 2 {
+		t.Fatalf("loaded %d times", loads)
+	}
+}
+
+func TestStoreConcurrentLoad(t *testing.T) {
+	s := NewStore()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// 使用者 1 讀取中
+	go s.Get(1, func() ([]bundle.Item, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+
+	// 其他使用者不需要等待
+	done := make(chan struct{})
+	go func() {
+		s.Get(2, func() ([]bundle.Item, error) {
+			return []bundle.Item{{SubId: subMrt, Name: "捷運"}}, nil
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("user 2 blocked by user 1")
+	}
+
+	close(release)
+	if _, ok := s.Loaded(1); !ok {
+		t.Fatal("expected loaded")
+	}
+}
+
+func TestStoreLoadError(t *testing.T) {
+	s := NewStore()
+
+	if _, err := s.Get(1, func() ([]bundle.Item, error) { return nil, errors.New("db") }); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if _, ok := s.Loaded(1); ok {
+		t.Fatal("unexpected loaded")
+	}
+
+	// 失敗後重新讀取
+	if _, err := s.Get(1, func() ([]bundle.Item, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+package classifier
+
+import (
+	"sync"
+
+	"me.daily/src/bundle"
+)
+
+// 每個使用者一個分類器，第一次使用時才訓練
+//
+// 讀取帳單不持有 mu，只有同一個使用者的請求會等待訓練完成
+type Store struct {
+	mu sync.Mutex
+	m  map[int]*entry
+}
+
+// 訓練中或已訓練的分類器，done 關閉後 c 與 err 才可以讀取
+type entry struct {
+	done chan struct{}
+	c    *Classifier
+	err  error
+}
+
+func NewStore() *Store {
+	return &Store{
+		m: make(map[int]*entry),
+	}
+}
+
+// 取得使用者的分類器，沒有時以 load 取得全部資料訓練
+//
+// 同一個使用者同時只會讀取一次，讀取失敗時下次重新讀取
+func (s *Store) Get(userId int, load func() ([]bundle.Item, error)) (*Classifier, error) {
+	s.mu.Lock()
+	if e, ok := s.m[userId]; ok {
+		s.mu.Unlock()
+		<-e.done
+		return e.c, e.err
+	}
+
+	e := &entry{done: make(chan struct{})}
+	s.m[userId] = e
+	s.mu.Unlock()
+
+	items, err := load()
+	if err != nil {
+		s.mu.Lock()
+		if s.m[userId] == e {
+			delete(s.m, userId)
+		}
+		s.mu.Unlock()
+
+		e.err = err
+		close(e.done)
+		return nil, err
+	}
+
+	c := New()
+	for _, item := range items {
+		c.Add(item.SubId, item.Name, item.Remark)
+	}
+
+	e.c = c
+	close(e.done)
+	return c, nil
+}
+
+// 取得已訓練的分類器，用於增量更新，訓練中時等待完成
+func (s *Store) Loaded(userId int) (*Classifier, bool) {
+	s.mu.Lock()
+	e, ok := s.m[userId]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	<-e.done
+	return e.c, e.err == nil
+}
+
+// 類別異動時丟棄，下次重新訓練
+func (s *Store) Reset(userId int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.m, userId)
+}
@@ -0,0 +1,122 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 沒有列在 Scales 的幣別的小數位數
+const DefaultScale = 2
+
+// 小數位數不是 DefaultScale 的幣別
+//
+// 新台幣實務上不使用角、分，舊資料也都是整數，所以視為 0 位
+var Scales = map[string]int{
+	"TWD": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"CLP": 0,
+	"ISK": 0,
+	"PYG": 0,
+	"UGX": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"BHD": 3,
+	"IQD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// 最小單位的上限，與資料庫的 INT 相同
+const MaxMinor = math.MaxInt32
+
+var ErrAmount = errors.New("invalid amount") // 金額格式錯誤或小數位數超過幣別
+
+// 幣別的小數位數
+func Scale(code string) int {
+	if n, ok := Scales[code]; ok {
+		return n
+	}
+
+	return DefaultScale
+}
+
+// 解析十進位金額並換算成幣別的最小單位，例如 "45.5" USD 為 4550
+//
+// 不經過浮點數，小數位數超過幣別時視為錯誤，結尾的 0 不算
+func ParseAmount(s, code string) (int, error) {
+	s = strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if len(whole) == 0 && len(frac) == 0 || !digits(whole) || !digits(frac) {
+		return 0, ErrAmount
+	}
+
+	scale := Scale(code)
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > scale {
+		return 0, ErrAmount
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || n > MaxMinor {
+		return 0, ErrAmount
+	}
+
+	if negative {
+		n = -n
+	}
+
+	return int(n), nil
+}
+
+// 最小單位轉成十進位字串，例如 4550 USD 為 "45.50"
+func Format(minor int, code string) string {
+	scale := Scale(code)
+
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	s := strconv.Itoa(minor)
+	if scale == 0 {
+		return sign + s
+	}
+
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package currency
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		s, code  string
+		expected int
+	}{
+		{"120", "TWD", 120},
+		{"120.00", "TWD", 120},
+		{"45.5", "USD", 4550},
+		{"45.55", "USD", 4555},
+		{".5", "USD", 50},
+		{"-3.", "USD", -300},
+		{"+0.001", "KWD", 1},
+		{"800", "JPY", 800},
+		{"2147483647", "TWD", MaxMinor},
+	}
+
+	for _, c := range cases {
+		if v, err := ParseAmount(c.s, c.code); err != nil || v != c.expected {
+			t.Errorf("ParseAmount(%q, %q) = %d, %v", c.s, c.code, v, err)
+		}
+	}
+
+	for _, s := range []string{"", ".", "-", "1.5", "1e3", "1,000", "2147483648", "0x10"} {
+		if _, err := ParseAmount(s, "TWD"); err != ErrAmount {
+			t.Errorf("ParseAmount(%q) = %v", s, err)
+		}
+	}
+
+	if _, err := ParseAmount("45.555", "USD"); err != ErrAmount {
+		t.Errorf("ParseAmount(45.555, USD) = %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		minor    int
+		code     string
+		expected string
+	}{
+		{120, "TWD", "120"},
+		{4550, "USD", "45.50"},
+		{5, "USD", "0.05"},
+		{-1, "KWD", "-0.001"},
+		{0, "EUR", "0.00"},
+	}
+
+	for _, c := range cases {
+		if s := Format(c.minor, c.code); s != c.expected {
+			t.Errorf("Format(%d, %q) = %q, want %q", c.minor, c.code, s, c.expected)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package currency
+
+import (
+	"errors"
+	"strings"
+)
+
+// 新帳號與舊資料的幣別
+const Default = "TWD"
+
+var ErrCode = errors.New("invalid currency") // 幣別代碼錯誤
+
+// 統一成大寫，並確認是三個英文字母的 ISO 4217 代碼
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrCode
+	}
+
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", ErrCode
+		}
+	}
+
+	return code, nil
+}
@@ -0,0 +1,86 @@
+package currency
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"usd":   "USD",
+		" JPY ": "JPY",
+		"TWD":   "TWD",
+		"":      "",
+		"US":    "",
+		"US1":   "",
+		"美金":    "",
+	}
+
+	for in, expected := range cases {
+		code, err := Normalize(in)
+		if len(expected) == 0 {
+			if err != ErrCode {
+				t.Errorf("Normalize(%q) = %q, %v", in, code, err)
+			}
+			continue
+		}
+
+		if err != nil || code != expected {
+			t.Errorf("Normalize(%q) = %q, %v", in, code, err)
+		}
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	data := "\ufeffDate,Currency,Rate,Source\n" +
+		"2022-10-01,usd,31.75,bank\n" +
+		"\n" +
+		"2022-10-02,JPY,\"0.2198\"\n"
+
+	rates, err := ParseRates(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bundle.Rate{
+		{Currency: "USD", Date: "2022-10-01", Rate: 31.75},
+		{Currency: "JPY", Date: "2022-10-02", Rate: 0.2198},
+	}
+	if !reflect.DeepEqual(rates, expected) {
+		t.Fatalf("unexpected rates %+v", rates)
+	}
+}
+
+func TestParseRatesError(t *testing.T) {
+	cases := map[string]error{
+		"":                                ErrEmpty,
+		"date,currency\n2022-10-01,USD\n": ErrHeader,
+		"date,currency,rate\n":            ErrEmpty,
+		"date,currency,rate\n2022/10/01,USD,31\n": nil,
+		"date,currency,rate\n2022-10-01,US,31\n":  ErrCode,
+		"date,currency,rate\n2022-10-01,USD,0\n":  ErrRate,
+		"date,currency,rate\n2022-10-01,USD,-1\n": ErrRate,
+	}
+
+	for data, expected := range cases {
+		_, err := ParseRates(strings.NewReader(data))
+		if err == nil {
+			t.Errorf("ParseRates(%q) should fail", data)
+			continue
+		}
+
+		if expected != nil && !errors.Is(err, expected) {
+			t.Errorf("ParseRates(%q) = %v", data, err)
+		}
+	}
+
+	var le *LineError
+	_, err := ParseRates(strings.NewReader("date,currency,rate\n2022-10-01,USD,1\n2022-10-02,USD,x\n"))
+	if !errors.As(err, &le) || le.Line != 3 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
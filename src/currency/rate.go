@@ -0,0 +1,130 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+)
+
+// 匯率檔筆數上限
+const MaxRates = 10000
+
+var (
+	ErrHeader  = errors.New("missing date, currency or rate column") // 缺少欄位
+	ErrRate    = errors.New("invalid rate")                          // 匯率格式錯誤
+	ErrEmpty   = errors.New("no rates")                              // 沒有資料
+	ErrTooMany = errors.New("too many rates")                        // 超過筆數上限
+)
+
+// 匯率檔的一行有誤
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// 解析匯率 CSV，第一行為標題，需要 date、currency、rate 三個欄位
+//
+// rate 為一單位外幣等於多少基準幣別，日期格式 yyyy-mm-dd
+func ParseRates(r io.Reader) ([]bundle.Rate, error) {
+	cr := csv.NewReader(transform.NewReader(r, unicode.BOMOverride(transform.Nop)))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrEmpty
+	} else if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{"date": -1, "currency": -1, "rate": -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if _, ok := cols[h]; ok {
+			cols[h] = i
+		}
+	}
+	for _, i := range cols {
+		if i < 0 {
+			return nil, ErrHeader
+		}
+	}
+
+	rates := make([]bundle.Rate, 0)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		get := func(name string) string {
+			if i := cols[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		if len(get("date")) == 0 && len(get("currency")) == 0 && len(get("rate")) == 0 {
+			continue
+		}
+
+		if len(rates) == MaxRates {
+			return nil, ErrTooMany
+		}
+
+		date, err := time.Parse("2006-01-02", get("date"))
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+
+		code, err := Normalize(get("currency"))
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+
+		rate, err := ParseRate(get("rate"))
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+
+		rates = append(rates, bundle.Rate{
+			Currency: code,
+			Date:     date.Format("2006-01-02"),
+			Rate:     rate,
+		})
+	}
+
+	if len(rates) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return rates, nil
+}
+
+// 匯率必須大於 0
+func ParseRate(s string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil || f <= 0 {
+		return 0, ErrRate
+	}
+
+	return f, nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"me.daily/src/bundle"
+)
+
+// 預設帳戶名稱
+const defaultAccount = "現金"
+
+// 帳戶在日期 $2 之前的餘額，期初餘額加上收支與轉帳，外幣帳單換算成基準幣別，退款反向
+//
+// 取得目前餘額時日期使用 infinity
+const balanceBefore = `a.opening
+			+ COALESCE((SELECT ROUND(SUM(s.increase * ` + refundSign + ` * ` + convertedPrice + `)) FROM bills AS b
+				INNER JOIN sub_types AS s ON b.sub_id=s.id
+				WHERE b.account_id=a.id AND b.date < $2), 0)
+			+ COALESCE((SELECT SUM(amount) FROM transfers
+				WHERE to_id=a.id AND date < $2), 0)
+			- COALESCE((SELECT SUM(amount) FROM transfers
+				WHERE from_id=a.id AND date < $2), 0)`
+
+// 建立預設帳戶
+func createAccount(tx *sqlx.Tx, userId int) (int, error) {
+	var id int
+	s := `INSERT INTO accounts (user_id, name, type, currency)
+			VALUES ($1, $2, $3, ` + userCurrency + `)
+			RETURNING id`
+	err := tx.QueryRow(s, userId, defaultAccount, bundle.AccountCash).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 確認帳戶持有者，0 表示預設帳戶，回傳帳戶編號
+func checkAccount(q sqlx.Queryer, userId, accountId int) (int, error) {
+	var id int
+	var err error
+
+	if accountId == 0 {
+		s := `SELECT id FROM accounts
+				WHERE user_id=$1 AND NOT deleted
+				ORDER BY id LIMIT 1`
+		err = sqlx.Get(q, &id, s, userId)
+	} else {
+		s := `SELECT id FROM accounts
+				WHERE user_id=$1 AND id=$2 AND NOT deleted`
+		err = sqlx.Get(q, &id, s, userId, accountId)
+	}
+
+	if err == sql.ErrNoRows {
+		return 0, errors.New(bundle.CodeHold)
+	} else if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 刪除帳戶，帳單保留在原帳戶，至少要留一個帳戶
+func (d *Db) DeleteAccount(userId, id int) error {
+	s := `UPDATE accounts SET deleted=true
+			WHERE user_id=$1 AND id=$2 AND NOT deleted`
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	r, err := tx.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	if _, err := checkAccount(tx, userId, 0); err != nil {
+		if err.Error() == bundle.CodeHold {
+			return errors.New(bundle.CodeAccount)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
+
+// 取得帳戶與目前餘額
+func (d *Db) GetAccounts(userId int) ([]bundle.Account, error) {
+	arr := make([]bundle.Account, 0)
+
+	if err := d.checkRates(userId, "-infinity", "infinity"); err != nil {
+		return arr, err
+	}
+
+	s := `SELECT a.id, a.name, a.type, a.opening, ` + balanceBefore + ` AS "balance"
+			FROM accounts AS a
+			WHERE a.user_id=$1 AND NOT a.deleted
+			ORDER BY a.id`
+	err := d.db.Select(&arr, s, userId, "infinity")
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 取得帳戶明細與逐筆餘額，包含轉帳，回傳區間開始前的餘額
+//
+// 同一天先列帳單再列轉帳
+func (d *Db) GetAccountItems(userId, accountId int, start, end string) (int, []bundle.AccountEntry, error) {
+	items := make([]bundle.AccountEntry, 0)
+
+	if _, err := checkAccount(d.db, userId, accountId); err != nil {
+		return 0, items, err
+	}
+
+	if err := d.checkRates(userId, "-infinity", end); err != nil {
+		return 0, items, err
+	}
+
+	var opening int
+	s := `SELECT ` + balanceBefore + ` FROM accounts AS a WHERE a.id=$1`
+	if err := d.db.Get(&opening, s, accountId, start); err != nil {
+		return 0, items, errors.New(bundle.CodeDb)
+	}
+
+	s = `WITH entries AS (
+				SELECT b.id, 0 AS "transfer_id", m.id AS "main_id", m.name AS "main_name",
+					s.id AS "sub_id", s.name AS "sub_name", b.name,
+					b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.currency,
+					` + refundSign + ` * ` + convertedPrice + ` AS "amount", b.date
+				FROM bills AS b
+				LEFT JOIN sub_types AS s
+				ON b.sub_id=s.id
+				LEFT JOIN main_types AS m
+				ON m.id=s.main_id
+				WHERE b.account_id=$1 AND b.date BETWEEN $2 AND $3
+				UNION ALL
+				SELECT 0, t.id, 0, '', 0, '', a.name,
+					t.amount, CASE WHEN t.to_id=$1 THEN 1 ELSE -1 END, false, 0, t.remark,
+					t.currency, t.amount, t.date
+				FROM transfers AS t
+				INNER JOIN accounts AS a
+				ON a.id=(CASE WHEN t.to_id=$1 THEN t.from_id ELSE t.to_id END)
+				WHERE (t.from_id=$1 OR t.to_id=$1) AND t.date BETWEEN $2 AND $3
+			)
+			SELECT id, transfer_id, main_id, main_name, sub_id, sub_name, name,
+				price, increase, refund, refund_of, remark, currency, currency_scale(currency) AS "scale",
+				$1 AS "account_id", TO_CHAR(date, 'yyyy-mm-dd') AS "date",
+				$4 + ROUND(SUM(increase * amount) OVER (ORDER BY date, transfer_id, id)) AS "balance"
+			FROM entries
+			ORDER BY date, transfer_id, id`
+	if err := d.db.Select(&items, s, accountId, start, end, opening); err != nil {
+		return 0, items, errors.New(bundle.CodeDb)
+	}
+
+	return opening, items, nil
+}
+
+// 新增帳戶
+func (d *Db) InsertAccount(userId int, name, accountType string, opening int) (int, error) {
+	var id int
+	s := `INSERT INTO accounts (user_id, name, type, opening, currency)
+			VALUES ($1, $2, $3, $4, ` + userCurrency + `) RETURNING id`
+	err := d.db.QueryRow(s, userId, name, accountType, opening).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 更新帳戶，期初餘額改為目前的基準幣別
+func (d *Db) UpdateAccount(userId, id int, name, accountType string, opening int) error {
+	s := `UPDATE accounts SET name=$1, type=$2, opening=$3, currency=(SELECT currency FROM users WHERE id=$4)
+			WHERE user_id=$4 AND id=$5 AND NOT deleted`
+	r, err := d.db.Exec(s, name, accountType, opening, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
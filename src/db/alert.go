@@ -0,0 +1,130 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"me.daily/src/bundle"
+)
+
+// 收件匣最多回傳的筆數
+const alertLimit = 100
+
+// 取得最近的提醒與未讀數量
+func (d *Db) GetAlerts(userId int, unread bool) ([]bundle.Alert, int, error) {
+	arr := make([]bundle.Alert, 0)
+
+	s := `SELECT id, kind, key, message, budget_id, item_id, read, created
+			FROM alerts
+			WHERE user_id=$1 AND (NOT $2 OR NOT read)
+			ORDER BY id DESC LIMIT $3`
+	if err := d.db.Select(&arr, s, userId, unread, alertLimit); err != nil {
+		return arr, 0, errors.New(bundle.CodeDb)
+	}
+
+	var count int
+	s = `SELECT COUNT(1) FROM alerts WHERE user_id=$1 AND NOT read`
+	if err := d.db.Get(&count, s, userId); err != nil {
+		return arr, 0, errors.New(bundle.CodeDb)
+	}
+
+	return arr, count, nil
+}
+
+// 取得提醒設定，沒有設定時回傳預設值
+func (d *Db) GetAlertSetting(userId int) (bundle.AlertSetting, error) {
+	var a bundle.AlertSetting
+
+	s := `SELECT bill_threshold, webhook, email FROM alert_settings WHERE user_id=$1`
+	err := d.db.Get(&a, s, userId)
+	if err != nil && err != sql.ErrNoRows {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	return a, nil
+}
+
+// 取得有預算或單筆提醒金額的使用者，排程檢查使用
+func (d *Db) GetAlertUsers() ([]int, error) {
+	arr := make([]int, 0)
+
+	s := `SELECT user_id FROM budgets
+			UNION
+			SELECT user_id FROM alert_settings WHERE bill_threshold > 0
+			ORDER BY user_id`
+	if err := d.db.Select(&arr, s); err != nil {
+		return arr, errors.New(bundle.CodeDb)
+	}
+
+	return arr, nil
+}
+
+// 取得帳單名稱與換算成基準幣別最小單位的支出，收入、退款或缺少匯率時為 0
+func (d *Db) GetBillExpense(userId, itemId int) (string, int, error) {
+	var row struct {
+		Name   string `db:"name"`
+		Amount int    `db:"amount"`
+	}
+
+	s := `SELECT b.name, CASE WHEN s.increase<0 AND NOT b.refund
+					THEN COALESCE(ROUND(` + convertedPrice + `), 0) ELSE 0 END AS "amount"
+			FROM bills AS b
+			INNER JOIN sub_types AS s
+			ON b.sub_id=s.id
+			WHERE b.user_id=$1 AND b.id=$2`
+	err := d.db.Get(&row, s, userId, itemId)
+	if err == sql.ErrNoRows {
+		return "", 0, errors.New(bundle.CodeNoData)
+	} else if err != nil {
+		return "", 0, errors.New(bundle.CodeDb)
+	}
+
+	return row.Name, row.Amount, nil
+}
+
+// 新增提醒，key 已存在時略過，回傳是否新增
+func (d *Db) InsertAlert(userId int, a bundle.Alert) (bool, error) {
+	s := `INSERT INTO alerts (user_id, kind, key, message, budget_id, item_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, key) DO NOTHING`
+	r, err := d.db.Exec(s, userId, a.Kind, a.Key, a.Message, a.BudgetId, a.ItemId)
+	if err != nil {
+		return false, errors.New(bundle.CodeDb)
+	}
+
+	n, _ := r.RowsAffected()
+
+	return n > 0, nil
+}
+
+// 提醒標為已讀，ids 空白時全部已讀
+func (d *Db) ReadAlerts(userId int, ids []int) error {
+	var err error
+
+	if len(ids) == 0 {
+		s := `UPDATE alerts SET read=true WHERE user_id=$1 AND NOT read`
+		_, err = d.db.Exec(s, userId)
+	} else {
+		s := `UPDATE alerts SET read=true WHERE user_id=$1 AND id=ANY($2)`
+		_, err = d.db.Exec(s, userId, pq.Array(ids))
+	}
+
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
+
+// 更新提醒設定
+func (d *Db) UpdateAlertSetting(userId int, a bundle.AlertSetting) error {
+	s := `INSERT INTO alert_settings (user_id, bill_threshold, webhook, email)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET bill_threshold=$2, webhook=$3, email=$4`
+	if _, err := d.db.Exec(s, userId, a.BillThreshold, a.Webhook, a.Email); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
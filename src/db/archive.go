@@ -0,0 +1,533 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"me.daily/src/bundle"
+)
+
+// 取得使用者全部資料，包含已刪除的類別
+func (d *Db) GetArchive(userId int) (bundle.ArchiveData, error) {
+	a := bundle.ArchiveData{
+		Accounts:  make([]bundle.ArchiveAccount, 0),
+		MainTypes: make([]bundle.ArchiveMain, 0),
+		SubTypes:  make([]bundle.ArchiveSub, 0),
+		Bills:     make([]bundle.ArchiveBill, 0),
+		Rules:     make([]bundle.Rule, 0),
+		Transfers: make([]bundle.Transfer, 0),
+		Rates:     make([]bundle.Rate, 0),
+		Budgets:   make([]bundle.Budget, 0),
+		Recurring: make([]bundle.Recurring, 0),
+	}
+
+	var err error
+
+	s := `SELECT id, name, type, opening, deleted FROM accounts WHERE user_id=$1 ORDER BY id`
+	if err := d.db.Select(&a.Accounts, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT id, name, deleted FROM main_types WHERE user_id=$1 ORDER BY id`
+	if err := d.db.Select(&a.MainTypes, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT id, main_id, name, increase, deleted FROM sub_types WHERE user_id=$1 ORDER BY id`
+	if err := d.db.Select(&a.SubTypes, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT id, sub_id, name, price, remark, TO_CHAR(date, 'yyyy-mm-dd') AS "date",
+				COALESCE(fit_id, '') AS "fit_id", COALESCE(account_id, 0) AS "account_id", currency,
+				refund, COALESCE(refund_of, 0) AS "refund_of", COALESCE(recurring_id, 0) AS "recurring_id",
+				COALESCE(TO_CHAR(recurring_date, 'yyyy-mm-dd'), '') AS "recurring_date"
+			FROM bills WHERE user_id=$1 ORDER BY date, id`
+	if err := d.db.Select(&a.Bills, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT ` + ruleColumns + ` FROM rules AS r WHERE r.user_id=$1 ORDER BY r.id`
+	if err := d.db.Select(&a.Rules, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT id, from_id, to_id, amount, remark, TO_CHAR(date, 'yyyy-mm-dd') AS "date"
+			FROM transfers WHERE user_id=$1 ORDER BY date, id`
+	if err := d.db.Select(&a.Transfers, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT id, base, currency, TO_CHAR(date, 'yyyy-mm-dd') AS "date", rate
+			FROM rates WHERE user_id=$1 ORDER BY base, currency, date`
+	if err := d.db.Select(&a.Rates, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT ` + budgetColumns + `
+			FROM budgets AS g
+			LEFT JOIN main_types AS m
+			ON g.main_id=m.id
+			LEFT JOIN sub_types AS s
+			ON g.sub_id=s.id
+			WHERE g.user_id=$1 ORDER BY g.id`
+	if err := d.db.Select(&a.Budgets, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	s = `SELECT ` + recurringColumns + ` FROM recurring AS r WHERE r.user_id=$1 ORDER BY r.id`
+	if err := d.db.Select(&a.Recurring, s, userId); err != nil {
+		return a, errors.New(bundle.CodeDb)
+	}
+
+	if a.RecurringExceptions, err = d.GetRecurringExceptions(userId); err != nil {
+		return a, err
+	}
+
+	code, err := d.GetCurrency(userId)
+	if err != nil {
+		return a, err
+	}
+
+	alert, err := d.GetAlertSetting(userId)
+	if err != nil {
+		return a, err
+	}
+
+	a.Settings = bundle.ArchiveSettings{Currency: code, Alert: alert}
+
+	return a, nil
+}
+
+// 還原備份，編號重新對應到目前的帳號，全部成功才寫入
+//
+// replace 為 true 時先清除帳號內的資料，包含指向舊帳單與預算的提醒。合併時沿用同名且未刪除的帳戶與類別，
+// 已刪除的照樣建立成已刪除，交易編號重複，或日期、子類別、金額、名稱、幣別與帳戶都和既有帳單相同的帳單略過，
+// 退款連結到略過的帳單時不保留連結。
+// 定期帳單保留已建立到的日期與單次的例外，合併時沿用相同的範本，帳單連結到還原後的範本，避免重新建立備份內已有的帳單。
+// replace 時基準幣別與提醒設定也改成備份的設定；合併時保留目前的設定，備份的基準幣別不同時不能合併
+func (d *Db) RestoreArchive(userId int, a bundle.ArchiveData, replace bool) (bundle.RestoreResult, error) {
+	var r bundle.RestoreResult
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return r, errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	if err := restoreSettings(tx, userId, a.Settings, replace); err != nil {
+		return r, err
+	}
+
+	if replace {
+		for _, s := range []string{
+			`DELETE FROM alerts WHERE user_id=$1`,
+			`DELETE FROM recurring WHERE user_id=$1`,
+			`DELETE FROM budgets WHERE user_id=$1`,
+			`DELETE FROM rates WHERE user_id=$1`,
+			`DELETE FROM transfers WHERE user_id=$1`,
+			`DELETE FROM rules WHERE user_id=$1`,
+			`DELETE FROM bills WHERE user_id=$1`,
+			`DELETE FROM sub_types WHERE user_id=$1`,
+			`DELETE FROM main_types WHERE user_id=$1`,
+			`DELETE FROM accounts WHERE user_id=$1`,
+		} {
+			if _, err := tx.Exec(s, userId); err != nil {
+				return r, errors.New(bundle.CodeDb)
+			}
+		}
+	}
+
+	accounts := make(map[int]int)
+	for _, account := range a.Accounts {
+		id, created, err := restoreAccount(tx, userId, account)
+		if err != nil {
+			return r, err
+		}
+		accounts[account.Id] = id
+		if created {
+			r.Accounts++
+		}
+	}
+
+	// 舊版備份的帳單使用預設帳戶，沒有可用的帳戶時建立
+	defaultId, err := checkAccount(tx, userId, 0)
+	if err != nil && err.Error() == bundle.CodeHold {
+		defaultId, err = createAccount(tx, userId)
+		r.Accounts++
+	}
+	if err != nil {
+		return r, err
+	}
+
+	mains := make(map[int]int)
+	for _, m := range a.MainTypes {
+		id, created, err := restoreMainType(tx, userId, m)
+		if err != nil {
+			return r, err
+		}
+		mains[m.Id] = id
+		if created {
+			r.MainTypes++
+		}
+	}
+
+	subs := make(map[int]int)
+	for _, sub := range a.SubTypes {
+		mainId, ok := mains[sub.MainId]
+		if !ok {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		id, created, err := restoreSubType(tx, userId, mainId, sub)
+		if err != nil {
+			return r, err
+		}
+		subs[sub.Id] = id
+		if created {
+			r.SubTypes++
+		}
+	}
+
+	// 只和還原前的帳單比較，備份內相同的帳單都保留
+	var lastId int
+	if err := tx.Get(&lastId, `SELECT COALESCE(MAX(id), 0) FROM bills WHERE user_id=$1`, userId); err != nil {
+		return r, errors.New(bundle.CodeDb)
+	}
+
+	s := `INSERT INTO bills (user_id, name, sub_id, price, remark, date,
+				name_fold, name_phonetic, remark_fold, fit_id, account_id, currency, refund)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11,
+				COALESCE(NULLIF($12, ''), ` + userCurrency + `), $13
+			WHERE NOT EXISTS (
+				SELECT 1 FROM bills
+				WHERE user_id=$1 AND id<=$14 AND date=$6 AND sub_id=$3 AND price=$4 AND name=$2
+					AND currency=COALESCE(NULLIF($12, ''), ` + userCurrency + `) AND account_id=$11
+			)
+			ON CONFLICT (user_id, fit_id) WHERE fit_id IS NOT NULL DO NOTHING
+			RETURNING id`
+	bills := make(map[int]int)
+	for _, b := range a.Bills {
+		subId, ok := subs[b.SubId]
+		if !ok {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		accountId := defaultId
+		if b.AccountId != 0 {
+			if accountId, ok = accounts[b.AccountId]; !ok {
+				return r, errors.New(bundle.CodeArchive)
+			}
+		}
+
+		var id int
+		nameFold, namePhonetic, remarkFold := searchColumns(b.Name, b.Remark)
+		err := tx.QueryRow(s, userId, b.Name, subId, b.Price, b.Remark, b.Date, nameFold, namePhonetic, remarkFold,
+			b.FitId, accountId, b.Currency, b.Refund, lastId).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+
+		bills[b.Id] = id
+		r.Bills++
+	}
+
+	// 原帳單可能排在退款之後，全部新增後再連結
+	for _, b := range a.Bills {
+		id, ok := bills[b.Id]
+		refundOf, ok2 := bills[b.RefundOf]
+		if !ok || !ok2 || b.RefundOf == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(`UPDATE bills SET refund_of=$1 WHERE id=$2`, refundOf, id); err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+	}
+
+	for _, rule := range a.Rules {
+		// 規則可以不指定子類別
+		subId, ok := subs[rule.SubId]
+		if !ok && rule.SubId != 0 {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		// 舊版備份沒有比對欄位與方式
+		if len(rule.Field) == 0 {
+			rule.Field = bundle.RuleFieldName
+		}
+		if len(rule.Match) == 0 {
+			rule.Match = bundle.RuleMatchContains
+		}
+
+		s := `INSERT INTO rules (user_id, match_field, match_type, keyword, min_price, max_price,
+					sub_id, rename, remark, priority)
+				SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+				WHERE NOT EXISTS (
+					SELECT 1 FROM rules
+					WHERE user_id=$1 AND match_field=$2 AND match_type=$3 AND keyword=$4
+						AND min_price=$5 AND max_price=$6 AND sub_id=$7
+				)`
+		result, err := tx.Exec(s, userId, rule.Field, rule.Match, rule.Keyword, rule.MinPrice, rule.MaxPrice,
+			subId, rule.Rename, rule.Remark, rule.Priority)
+		if err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+
+		n, _ := result.RowsAffected()
+		r.Rules += int(n)
+	}
+
+	s = `INSERT INTO transfers (user_id, from_id, to_id, amount, remark, date, currency)
+			SELECT $1, $2, $3, $4, $5, $6, ` + userCurrency + `
+			WHERE NOT EXISTS (
+				SELECT 1 FROM transfers
+				WHERE user_id=$1 AND from_id=$2 AND to_id=$3 AND amount=$4 AND date=$6
+			)`
+	for _, t := range a.Transfers {
+		fromId, ok := accounts[t.FromId]
+		toId, ok2 := accounts[t.ToId]
+		if !ok || !ok2 {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		result, err := tx.Exec(s, userId, fromId, toId, t.Amount, t.Remark, t.Date)
+		if err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+
+		n, _ := result.RowsAffected()
+		r.Transfers += int(n)
+	}
+
+	// 目前已有的匯率優先
+	s = `INSERT INTO rates (user_id, base, currency, date, rate)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, base, currency, date) DO NOTHING`
+	for _, rate := range a.Rates {
+		result, err := tx.Exec(s, userId, rate.Base, rate.Currency, rate.Date, rate.Rate)
+		if err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+
+		n, _ := result.RowsAffected()
+		r.Rates += int(n)
+	}
+
+	s = `INSERT INTO budgets (user_id, main_id, sub_id, period, amount, rollover, start)
+			SELECT $1, $2, $3, $4, $5, $6, $7
+			WHERE NOT EXISTS (
+				SELECT 1 FROM budgets
+				WHERE user_id=$1 AND main_id=$2 AND sub_id=$3 AND period=$4
+			)`
+	for _, b := range a.Budgets {
+		// 預算只會指定主類別或子類別其中一個
+		mainId, ok := mains[b.MainId]
+		subId, ok2 := subs[b.SubId]
+		if (!ok && b.MainId != 0) || (!ok2 && b.SubId != 0) {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		result, err := tx.Exec(s, userId, mainId, subId, b.Period, b.Amount, b.Rollover, b.Start)
+		if err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+
+		n, _ := result.RowsAffected()
+		r.Budgets += int(n)
+	}
+
+	recurring := make(map[int]int)
+	for _, rc := range a.Recurring {
+		subId, ok := subs[rc.SubId]
+		if !ok {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		// 帳戶為 0 時沿用預設帳戶
+		accountId, ok := accounts[rc.AccountId]
+		if !ok && rc.AccountId != 0 {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		id, created, err := restoreRecurring(tx, userId, subId, accountId, rc)
+		if err != nil {
+			return r, err
+		}
+		recurring[rc.Id] = id
+		if created {
+			r.Recurring++
+		}
+	}
+
+	s = `INSERT INTO recurring_exceptions (recurring_id, date, skip, name, price, remark)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (recurring_id, date) DO NOTHING`
+	for _, e := range a.RecurringExceptions {
+		recurringId, ok := recurring[e.RecurringId]
+		if !ok {
+			return r, errors.New(bundle.CodeArchive)
+		}
+
+		if _, err := tx.Exec(s, recurringId, e.Date, e.Skip, e.Name, e.Price, e.Remark); err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+	}
+
+	// 範本同一天已經有帳單時不連結
+	s = `UPDATE bills SET recurring_id=$1, recurring_date=$2
+			WHERE id=$3 AND NOT EXISTS (
+				SELECT 1 FROM bills WHERE recurring_id=$1 AND recurring_date=$2
+			)`
+	for _, b := range a.Bills {
+		id, ok := bills[b.Id]
+		recurringId, ok2 := recurring[b.RecurringId]
+		if !ok || !ok2 || b.RecurringId == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(s, recurringId, b.RecurringDate, id); err != nil {
+			return r, errors.New(bundle.CodeDb)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return r, errors.New(bundle.CodeDb)
+	}
+
+	return r, nil
+}
+
+// 還原設定，舊版備份沒有設定時略過
+func restoreSettings(tx *sqlx.Tx, userId int, settings bundle.ArchiveSettings, replace bool) error {
+	if len(settings.Currency) == 0 {
+		return nil
+	}
+
+	var base string
+	s := `SELECT currency FROM users WHERE id=$1 FOR UPDATE`
+	if err := tx.Get(&base, s, userId); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if !replace {
+		if settings.Currency != base {
+			return errors.New(bundle.CodeBaseCurrency)
+		}
+		return nil
+	}
+
+	s = `UPDATE users SET currency=$2 WHERE id=$1`
+	if _, err := tx.Exec(s, userId, settings.Currency); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	s = `INSERT INTO alert_settings (user_id, bill_threshold, webhook, email)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET bill_threshold=$2, webhook=$3, email=$4`
+	if _, err := tx.Exec(s, userId, settings.Alert.BillThreshold, settings.Alert.Webhook, settings.Alert.Email); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
+
+// 還原帳戶，回傳新的編號與是否新增
+func restoreAccount(tx *sqlx.Tx, userId int, a bundle.ArchiveAccount) (int, bool, error) {
+	var id int
+
+	if !a.Deleted {
+		s := `SELECT id FROM accounts WHERE user_id=$1 AND name=$2 AND NOT deleted`
+		err := tx.Get(&id, s, userId, a.Name)
+		if err == nil {
+			return id, false, nil
+		} else if err != sql.ErrNoRows {
+			return 0, false, errors.New(bundle.CodeDb)
+		}
+	}
+
+	s := `INSERT INTO accounts (user_id, name, type, opening, deleted, currency)
+			VALUES ($1, $2, $3, $4, $5, ` + userCurrency + `) RETURNING id`
+	if err := tx.QueryRow(s, userId, a.Name, a.Type, a.Opening, a.Deleted).Scan(&id); err != nil {
+		return 0, false, errors.New(bundle.CodeDb)
+	}
+
+	return id, true, nil
+}
+
+// 還原定期帳單範本，合併時沿用類別、名稱、週期與開始日期相同的範本，回傳新的編號與是否新增
+func restoreRecurring(tx *sqlx.Tx, userId, subId, accountId int, rc bundle.Recurring) (int, bool, error) {
+	var id int
+
+	s := `SELECT id FROM recurring WHERE user_id=$1 AND sub_id=$2 AND name=$3 AND frequency=$4 AND start=$5
+			ORDER BY id LIMIT 1`
+	err := tx.Get(&id, s, userId, subId, rc.Name, rc.Frequency, rc.Start)
+	if err == nil {
+		return id, false, nil
+	} else if err != sql.ErrNoRows {
+		return 0, false, errors.New(bundle.CodeDb)
+	}
+
+	s = `INSERT INTO recurring (user_id, sub_id, name, price, remark, account_id, currency,
+				frequency, step, nth, weekday, start, end_date, count, materialized)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, '')::DATE, $14, NULLIF($15, '')::DATE)
+			RETURNING id`
+	err = tx.QueryRow(s, userId, subId, rc.Name, rc.Price, rc.Remark, accountId, rc.Currency,
+		rc.Frequency, rc.Interval, rc.Nth, rc.Weekday, rc.Start, rc.End, rc.Count, rc.Materialized).Scan(&id)
+	if err != nil {
+		return 0, false, errors.New(bundle.CodeDb)
+	}
+
+	return id, true, nil
+}
+
+// 還原主類別，回傳新的編號與是否新增
+func restoreMainType(tx *sqlx.Tx, userId int, m bundle.ArchiveMain) (int, bool, error) {
+	var id int
+
+	if !m.Deleted {
+		s := `SELECT id FROM main_types WHERE user_id=$1 AND name=$2 AND NOT deleted`
+		err := tx.Get(&id, s, userId, m.Name)
+		if err == nil {
+			return id, false, nil
+		} else if err != sql.ErrNoRows {
+			return 0, false, errors.New(bundle.CodeDb)
+		}
+	}
+
+	s := `INSERT INTO main_types (user_id, name, deleted)
+			VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRow(s, userId, m.Name, m.Deleted).Scan(&id); err != nil {
+		return 0, false, errors.New(bundle.CodeDb)
+	}
+
+	return id, true, nil
+}
+
+// 還原子類別，回傳新的編號與是否新增
+func restoreSubType(tx *sqlx.Tx, userId, mainId int, sub bundle.ArchiveSub) (int, bool, error) {
+	var id int
+
+	if !sub.Deleted {
+		s := `SELECT id FROM sub_types
+				WHERE user_id=$1 AND main_id=$2 AND name=$3 AND NOT deleted`
+		err := tx.Get(&id, s, userId, mainId, sub.Name)
+		if err == nil {
+			return id, false, nil
+		} else if err != sql.ErrNoRows {
+			return 0, false, errors.New(bundle.CodeDb)
+		}
+	}
+
+	s := `INSERT INTO sub_types (name, user_id, main_id, increase, deleted)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`
+	if err := tx.QueryRow(s, sub.Name, userId, mainId, sub.Increase, sub.Deleted).Scan(&id); err != nil {
+		return 0, false, errors.New(bundle.CodeDb)
+	}
+
+	return id, true, nil
+}
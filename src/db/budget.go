@@ -0,0 +1,125 @@
+package db
+
+import (
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 預算欄位，名稱為子類別或主類別的名稱
+const budgetColumns = `g.id, g.main_id, g.sub_id, COALESCE(s.name, m.name, '') AS "name",
+				g.period, g.amount, g.rollover, TO_CHAR(g.start, 'yyyy-mm-dd') AS "start"`
+
+// 確認預算的類別持有者
+func (d *Db) checkBudget(userId int, b bundle.Budget) error {
+	if b.SubId != 0 {
+		return d.checkSub(userId, b.SubId)
+	}
+
+	return d.checkMainTypeOwner(userId, b.MainId)
+}
+
+// 刪除預算
+func (d *Db) DeleteBudget(userId, id int) error {
+	s := `DELETE FROM budgets WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
+
+// 取得預算，排除類別已刪除的預算
+func (d *Db) GetBudgets(userId int) ([]bundle.Budget, error) {
+	arr := make([]bundle.Budget, 0)
+
+	s := `SELECT ` + budgetColumns + `
+			FROM budgets AS g
+			LEFT JOIN main_types AS m
+			ON g.main_id=m.id
+			LEFT JOIN sub_types AS s
+			ON g.sub_id=s.id
+			WHERE g.user_id=$1 AND NOT COALESCE(s.deleted, m.deleted, true)
+			ORDER BY g.id`
+	err := d.db.Select(&arr, s, userId)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 取得預算類別在日期區間內各期間的支出，key 為期間的第一天
+//
+// 與 GetSumByMainType 使用相同的條件，期間與 budget.Bounds 相同
+func (d *Db) GetBudgetSpent(userId int, b bundle.Budget, start, end string) (map[string]int, error) {
+	sums := make(map[string]int)
+
+	if err := d.checkRates(userId, start, end); err != nil {
+		return sums, err
+	}
+
+	rows := make([]struct {
+		Period string `db:"period"`
+		Sum    int    `db:"sum"`
+	}, 0)
+
+	s := `SELECT TO_CHAR(DATE_TRUNC($4, b.date), 'yyyy-mm-dd') AS "period", ` + expenseSum + ` AS "sum"
+			` + expenseFrom + ` AND (m.id=$5 OR s.id=$6)
+			GROUP BY 1`
+	if err := d.db.Select(&rows, s, userId, start, end, b.Period, b.MainId, b.SubId); err != nil {
+		return sums, errors.New(bundle.CodeDb)
+	}
+
+	for _, r := range rows {
+		sums[r.Period] = r.Sum
+	}
+
+	return sums, nil
+}
+
+// 新增預算
+func (d *Db) InsertBudget(userId int, b bundle.Budget) (int, error) {
+	if err := d.checkBudget(userId, b); err != nil {
+		return 0, err
+	}
+
+	var id int
+	s := `INSERT INTO budgets (user_id, main_id, sub_id, period, amount, rollover, start)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := d.db.QueryRow(s, userId, b.MainId, b.SubId, b.Period, b.Amount, b.Rollover, b.Start).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 更新預算
+func (d *Db) UpdateBudget(userId int, b bundle.Budget) error {
+	if err := d.checkBudget(userId, b); err != nil {
+		return err
+	}
+
+	s := `UPDATE budgets SET main_id=$3, sub_id=$4, period=$5, amount=$6, rollover=$7, start=$8
+			WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, b.Id, b.MainId, b.SubId, b.Period, b.Amount, b.Rollover, b.Start)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
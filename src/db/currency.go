@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 帳單換算成基準幣別最小單位的金額，需要 bills AS b
+const convertedPrice = `(b.price * bill_rate(b.user_id, b.currency, b.date))`
+
+// 使用者的基準幣別，幣別空白時使用
+const userCurrency = `(SELECT currency FROM users WHERE id=$1)`
+
+// currency_scale 的 WHEN 子句，幣別代碼只有英文字母，可以直接放進 SQL
+func scaleCases() string {
+	codes := make([]string, 0, len(currency.Scales))
+	for code := range currency.Scales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var sb strings.Builder
+	for _, code := range codes {
+		sb.WriteString("WHEN '" + code + "' THEN " + strconv.Itoa(currency.Scales[code]) + " ")
+	}
+
+	return sb.String()
+}
+
+// 幣別最小單位的倍數，cur 為 SQL 運算式
+func scaleFactor(cur string) string {
+	return "CAST(POWER(10, currency_scale(" + cur + ")) AS INT)"
+}
+
+// 確認日期區間內的外幣帳單都有匯率
+func (d *Db) checkRates(userId int, start, end string) error {
+	var count int
+	s := `SELECT COUNT(1) FROM bills AS b
+			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3
+				AND b.currency<>` + userCurrency + `
+				AND bill_rate(b.user_id, b.currency, b.date) IS NULL`
+	if err := d.db.Get(&count, s, userId, start, end); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if count > 0 {
+		return errors.New(bundle.CodeRate)
+	}
+
+	return nil
+}
+
+// 刪除匯率
+func (d *Db) DeleteRate(userId, id int) error {
+	s := `DELETE FROM rates WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
+
+// 取得基準幣別
+func (d *Db) GetCurrency(userId int) (string, error) {
+	var code string
+	s := `SELECT currency FROM users WHERE id=$1`
+	err := d.db.Get(&code, s, userId)
+	if err == sql.ErrNoRows {
+		return "", errors.New(bundle.CodeNoData)
+	} else if err != nil {
+		return "", errors.New(bundle.CodeDb)
+	}
+
+	return code, nil
+}
+
+// 取得目前基準幣別的匯率，currency 空白時取得全部幣別
+func (d *Db) GetRates(userId int, code, start, end string) ([]bundle.Rate, error) {
+	arr := make([]bundle.Rate, 0)
+
+	s := `SELECT id, base, currency, TO_CHAR(date, 'yyyy-mm-dd') AS "date", rate
+			FROM rates
+			WHERE user_id=$1 AND base=` + userCurrency + `
+				AND ($2='' OR currency=$2) AND date BETWEEN $3 AND $4
+			ORDER BY currency, date`
+	err := d.db.Select(&arr, s, userId, code, start, end)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 新增或更新匯率，基準幣別為目前的基準幣別，同一天的匯率會覆蓋
+//
+// 全部成功才寫入，回傳第一筆的編號
+func (d *Db) InsertRates(userId int, rates []bundle.Rate) (int, error) {
+	base, err := d.GetCurrency(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	first := 0
+	s := `INSERT INTO rates (user_id, base, currency, date, rate)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, base, currency, date) DO UPDATE SET rate=EXCLUDED.rate
+			RETURNING id`
+	for i, r := range rates {
+		if r.Currency == base {
+			return 0, errors.New(bundle.CodeCurrency)
+		}
+
+		var id int
+		if err := tx.QueryRow(s, userId, base, r.Currency, r.Date, r.Rate).Scan(&id); err != nil {
+			return 0, errors.New(bundle.CodeDb)
+		}
+
+		if i == 0 {
+			first = id
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return first, nil
+}
+
+// 更新基準幣別，統計改用新幣別的匯率
+//
+// 帳戶期初餘額、轉帳、預算與單筆提醒金額以基準幣別的最小單位記錄，還有不是新幣別的金額時不能變更
+func (d *Db) UpdateCurrency(userId int, code string) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	var base string
+	s := `SELECT currency FROM users WHERE id=$1 FOR UPDATE`
+	err = tx.Get(&base, s, userId)
+	if err == sql.ErrNoRows {
+		return errors.New(bundle.CodeNoData)
+	} else if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if base == code {
+		return nil
+	}
+
+	var used bool
+	s = `SELECT EXISTS (SELECT 1 FROM accounts WHERE user_id=$1 AND opening<>0 AND currency<>$2)
+				OR EXISTS (SELECT 1 FROM transfers WHERE user_id=$1 AND currency<>$2)
+				OR EXISTS (SELECT 1 FROM budgets WHERE user_id=$1 AND amount<>0)
+				OR EXISTS (SELECT 1 FROM alert_settings WHERE user_id=$1 AND bill_threshold<>0)`
+	if err := tx.Get(&used, s, userId, code); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if used {
+		return errors.New(bundle.CodeBaseCurrency)
+	}
+
+	s = `UPDATE users SET currency=$1 WHERE id=$2`
+	if _, err := tx.Exec(s, code, userId); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
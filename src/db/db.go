@@ -31,17 +31,25 @@ type Db struct {
 	db *sqlx.DB
 }
 
-func NewDb(host, user, password, dbname string) *Db {
+// 連線並更新資料表
+func NewDb(host, user, password, dbname string) (*Db, error) {
 	connect := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", host, 5432, user, password, dbname)
 
 	db, err := sqlx.Connect("postgres", connect)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("connect database: %w", err)
 	}
 
-	return &Db{
+	d := &Db{
 		db: db,
 	}
+
+	if err := d.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return d, nil
 }
 
 // 確認子類別持有者
@@ -200,6 +208,10 @@ func (d *Db) CreateUser(username, password string) (int, error) {
 			return -1, err
 		}
 
+		if _, err = createAccount(tx, userId); err != nil {
+			return -1, err
+		}
+
 		tx.Commit()
 		return userId, nil
 	}
@@ -309,11 +321,85 @@ func (d *Db) GetAllType(userId int) ([]bundle.AllType, error) {
 	return ats, err
 }
 
+// 取得可能重複的帳單，子類別、金額、日期相同且不只一筆
+func (d *Db) GetDuplicateCandidates(userId int, start, end string) ([]bundle.PreviewItem, error) {
+	items := make([]bundle.PreviewItem, 0)
+
+	s := `SELECT b.id, m.id AS "main_id", m.name AS "main_name", 
+				s.id AS "sub_id", s.name AS "sub_name", b.name, 
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+			FROM bills AS b
+			LEFT JOIN sub_types AS s
+			ON b.sub_id=s.id
+			LEFT JOIN main_types AS m
+			ON m.id=s.main_id
+			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3
+				AND (b.sub_id, b.price, b.date) IN (
+					SELECT sub_id, price, date
+					FROM bills
+					WHERE user_id=$1 AND date BETWEEN $2 AND $3
+					GROUP BY sub_id, price, date
+					HAVING COUNT(1) > 1)
+			ORDER BY b.date, b.sub_id, b.price, b.id`
+
+	err := d.db.Select(&items, s, userId, start, end)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return items, err
+}
+
+// 取得子類別、金額、日期相同的帳單
+func (d *Db) GetSameItems(userId, subId, price int, date string) ([]bundle.PreviewItem, error) {
+	items := make([]bundle.PreviewItem, 0)
+
+	s := `SELECT b.id, m.id AS "main_id", m.name AS "main_name", 
+				s.id AS "sub_id", s.name AS "sub_name", b.name, 
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+			FROM bills AS b
+			LEFT JOIN sub_types AS s
+			ON b.sub_id=s.id
+			LEFT JOIN main_types AS m
+			ON m.id=s.main_id
+			WHERE b.user_id=$1 AND b.sub_id=$2 AND b.price=$3 AND b.date=$4
+			ORDER BY b.id`
+
+	err := d.db.Select(&items, s, userId, subId, price, date)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return items, err
+}
+
+// 取得全部帳單，排除已刪除的子類別
+func (d *Db) GetAllItems(userId int) ([]bundle.Item, error) {
+	items := make([]bundle.Item, 0)
+
+	s := `SELECT b.id, b.name, s.main_id, b.sub_id, b.price, b.remark, b.date
+			FROM bills AS b
+			INNER JOIN sub_types AS s
+			ON s.id=b.sub_id
+			WHERE b.user_id=$1 AND NOT s.deleted
+			ORDER BY b.date, b.id`
+
+	err := d.db.Select(&items, s, userId)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return items, err
+}
+
 // 取得單項目
 func (d *Db) GetItem(userId, itemId int) (bundle.Item, error) {
 	var item bundle.Item
 
-	s := `SELECT b.id, b.name, main_id, sub_id, price, remark, date
+	s := `SELECT b.id, b.name, main_id, sub_id, price, remark, date, account_id,
+				currency, currency_scale(currency) AS "scale", refund, COALESCE(refund_of, 0) AS "refund_of"
 			FROM bills AS b
 			LEFT JOIN sub_types AS s
 			ON s.id=b.sub_id
@@ -337,7 +423,8 @@ func (d *Db) GetPerviewItemsByDate(userId int, start, end string) ([]bundle.Prev
 
 	s := `SELECT b.id, m.id AS "main_id", m.name AS "main_name", 
 				s.id AS "sub_id", s.name AS "sub_name", b.name, 
-				b.price, s.increase, TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
 			FROM bills AS b
 			LEFT JOIN sub_types AS s
 			ON b.sub_id=s.id
@@ -384,17 +471,29 @@ func (d *Db) GetSubType(userId, main_id int) ([]bundle.Sub, error) {
 	return arr, err
 }
 
-// 取得主類別總和，排除收入
-func (d *Db) GetSumByMainType(userId int, start, end string) ([]bundle.MainSumMonthly, error) {
-	arr := make([]bundle.MainSumMonthly, 0)
-
-	s := `SELECT SUM(b.price) AS sum, m.name
-			FROM bills AS b
+// 支出總和，排除收入，轉帳不計入，退款從同類別扣除，換算成基準幣別的最小單位
+//
+// 以 NUMERIC 計算，加總後才四捨五入。主類別總和與預算使用相同的條件
+const (
+	expenseSum  = `ROUND(SUM(` + refundSign + ` * ` + convertedPrice + `))`
+	expenseFrom = `FROM bills AS b
 			LEFT JOIN sub_types AS s
 			ON b.sub_id=s.id
 			LEFT JOIN main_types AS m
 			ON m.id=s.main_id
-			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3 AND s.increase<0
+			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3 AND s.increase<0`
+)
+
+// 取得主類別總和，計算方式見 expenseSum
+func (d *Db) GetSumByMainType(userId int, start, end string) ([]bundle.MainSumMonthly, error) {
+	arr := make([]bundle.MainSumMonthly, 0)
+
+	if err := d.checkRates(userId, start, end); err != nil {
+		return arr, err
+	}
+
+	s := `SELECT ` + expenseSum + ` AS sum, m.name
+			` + expenseFrom + `
 			GROUP BY m.id`
 
 	err := d.db.Select(&arr, s, userId, start, end)
@@ -405,11 +504,18 @@ func (d *Db) GetSumByMainType(userId int, start, end string) ([]bundle.MainSumMo
 	return arr, err
 }
 
-// 取得月結總金額
+// 取得月結總金額，轉帳不計入，退款從同類別扣除，換算成基準幣別的最小單位
+//
+// 以 NUMERIC 計算，加總後才四捨五入
 func (d *Db) GetSumByMonth(userId int, start, end string) ([]bundle.Monthly, error) {
 	items := make([]bundle.Monthly, 0)
 
-	s := `SELECT SUM(s.increase * b.price) AS "sum", DATE_TRUNC('month', b.date) AS "date"
+	if err := d.checkRates(userId, start, end); err != nil {
+		return items, err
+	}
+
+	s := `SELECT ROUND(SUM(s.increase * ` + refundSign + ` * ` + convertedPrice + `)) AS "sum",
+				DATE_TRUNC('month', b.date) AS "date"
 			FROM bills AS b
 			LEFT JOIN sub_types AS s
 			ON b.sub_id=s.id
@@ -427,21 +533,37 @@ func (d *Db) GetSumByMonth(userId int, start, end string) ([]bundle.Monthly, err
 	return items, err
 }
 
-// 新增帳單項目
-func (d *Db) InsertItem(userId int, name string, subId int, price int, remark, date string) error {
-	err := d.checkSub(userId, subId)
+// 新增帳單項目，帳戶為 0 時使用預設帳戶，幣別空白時使用基準幣別，回傳帳單編號
+func (d *Db) InsertItem(userId int, item bundle.Bill) (int, error) {
+	err := d.checkSub(userId, item.SubId)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	if err := d.checkRefund(userId, item); err != nil {
+		return 0, err
 	}
 
-	s := `INSERT INTO bills (user_id, name, sub_id, price, remark, date) 
-			VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err = d.db.Exec(s, userId, name, subId, price, remark, date)
+	accountId, err := checkAccount(d.db, userId, item.AccountId)
 	if err != nil {
-		return errors.New(bundle.CodeDb)
+		return 0, err
 	}
 
-	return nil
+	nameFold, namePhonetic, remarkFold := searchColumns(item.Name, item.Remark)
+
+	s := `INSERT INTO bills (user_id, name, sub_id, price, remark, date, 
+				name_fold, name_phonetic, remark_fold, account_id, currency, refund, refund_of) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), ` + userCurrency + `),
+				$12, NULLIF($13, 0))
+			RETURNING id`
+	var id int
+	err = d.db.QueryRow(s, userId, item.Name, item.SubId, item.Price, item.Remark, item.Date,
+		nameFold, namePhonetic, remarkFold, accountId, item.Currency, item.Refund, item.RefundOf).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
 }
 
 // 新增主類型
@@ -491,23 +613,24 @@ func (d *Db) InsertSubType(userId, mainId int, subName string, increase bool) (i
 	return id, nil
 }
 
-// 模糊搜尋名稱
+// 名稱包含關鍵字
 func (d *Db) LikeName(userId int, keyword, start, end string) ([]bundle.PreviewItem, error) {
 	items := make([]bundle.PreviewItem, 0)
 
 	s := `SELECT b.id, m.id AS "main_id", m.name AS "main_name", 
 				s.id AS "sub_id", s.name AS "sub_name", b.name, 
-				b.price, s.increase, TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
 			FROM bills AS b
 			LEFT JOIN sub_types AS s
 			ON b.sub_id=s.id
 			LEFT JOIN main_types AS m
 			ON m.id=s.main_id
 			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3 
-				AND b.name LIKE $4 
+				AND b.name LIKE $4 ESCAPE '\'
 			ORDER BY b.date, b.id`
 
-	err := d.db.Select(&items, s, userId, start, end, keyword)
+	err := d.db.Select(&items, s, userId, start, end, "%"+escapeLike(keyword)+"%")
 	if err != nil {
 		err = errors.New(bundle.CodeDb)
 	}
@@ -534,16 +657,33 @@ func (d *Db) Login(username string) (int, string, error) {
 	return login.Id, login.Password, err
 }
 
-// 更新帳單項目
-func (d *Db) UpdateItem(userId, itemId int, name string, subId int, price int, remark, date string) error {
-	err := d.checkSub(userId, subId)
+// 更新帳單項目，帳戶為 0、幣別空白時不變更
+func (d *Db) UpdateItem(userId int, item bundle.Bill) error {
+	err := d.checkSub(userId, item.SubId)
 	if err != nil {
 		return err
 	}
 
-	s := `UPDATE bills SET name=$1, sub_id=$2, price=$3, remark=$4, date=$7
+	if item.AccountId != 0 {
+		if _, err := checkAccount(d.db, userId, item.AccountId); err != nil {
+			return err
+		}
+	}
+
+	if err := d.checkRefund(userId, item); err != nil {
+		return err
+	}
+
+	nameFold, namePhonetic, remarkFold := searchColumns(item.Name, item.Remark)
+
+	s := `UPDATE bills SET name=$1, sub_id=$2, price=$3, remark=$4, date=$7,
+				name_fold=$8, name_phonetic=$9, remark_fold=$10,
+				account_id=COALESCE(NULLIF($11, 0), account_id),
+				currency=COALESCE(NULLIF($12, ''), currency),
+				refund=$13, refund_of=NULLIF($14, 0)
 			WHERE user_id=$5 AND id=$6`
-	r, err := d.db.Exec(s, name, subId, price, remark, userId, itemId, date)
+	r, err := d.db.Exec(s, item.Name, item.SubId, item.Price, item.Remark, userId, item.Id, item.Date,
+		nameFold, namePhonetic, remarkFold, item.AccountId, item.Currency, item.Refund, item.RefundOf)
 	if err != nil {
 		return errors.New(bundle.CodeHold)
 	}
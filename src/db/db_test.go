@@ -5,11 +5,35 @@ import (
 	"log"
 	"testing"
 	"time"
+
+	"me.daily/src/bundle"
 )
 
 // docker run -it --rm --name test_postgres -p 5434:5432 -e POSTGRES_USER=postgres -e POSTGRES_PASSWORD=postgres postgres:14.4
 func newDb() *Db {
-	return NewDb("127.0.0.1", "postgres", "postgres", "postgres")
+	d, err := NewDb("127.0.0.1", "postgres", "postgres", "postgres")
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestDeleteAccount(t *testing.T) {
+	d := newDb()
+	err := d.DeleteAccount(1, 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestDeleteBudget(t *testing.T) {
+	d := newDb()
+	err := d.DeleteBudget(1, -1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
 }
 
 func TestDeleteItem(t *testing.T) {
@@ -28,6 +52,31 @@ func TestDeleteMainType(t *testing.T) {
 	}
 }
 
+func TestDeleteRate(t *testing.T) {
+	d := newDb()
+	err := d.DeleteRate(1, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestDeleteRecurring(t *testing.T) {
+	d := newDb()
+	err := d.DeleteRecurring(1, -1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestDeleteRule(t *testing.T) {
+	d := newDb()
+	err := d.DeleteRule(1, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 func TestDeleteSubType(t *testing.T) {
 	d := newDb()
 	err := d.DeleteSubType(1, 1)
@@ -36,6 +85,89 @@ func TestDeleteSubType(t *testing.T) {
 	}
 }
 
+func TestDeleteTransfer(t *testing.T) {
+	d := newDb()
+	err := d.DeleteTransfer(1, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func TestEachItem(t *testing.T) {
+	d := newDb()
+	count := 0
+	err := d.EachItem(1, "2000-01-01", "2100-01-01", func(item bundle.PreviewItem) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(count)
+}
+
+func TestGetAccounts(t *testing.T) {
+	d := newDb()
+	list, err := d.GetAccounts(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, a := range list {
+		fmt.Println(a.Name, a.Type, a.Balance)
+	}
+}
+
+func TestGetAccountItems(t *testing.T) {
+	d := newDb()
+	opening, list, err := d.GetAccountItems(1, 1, "2022-10-01", "2022-10-31")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(opening)
+	for _, e := range list {
+		fmt.Println(e.Date, e.Name, e.Price, e.Balance)
+	}
+}
+
+func TestGetArchive(t *testing.T) {
+	d := newDb()
+	a, err := d.GetArchive(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(len(a.MainTypes), len(a.SubTypes), len(a.Bills), len(a.Rules))
+}
+
+func TestGetAlerts(t *testing.T) {
+	d := newDb()
+	arr, unread, err := d.GetAlerts(1, true)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(arr, unread)
+}
+
+func TestGetAlertSetting(t *testing.T) {
+	d := newDb()
+	a, err := d.GetAlertSetting(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(a)
+}
+
 func TestGetAllType(t *testing.T) {
 	d := newDb()
 	all, err := d.GetAllType(1)
@@ -51,6 +183,97 @@ func TestGetAllType(t *testing.T) {
 	}
 }
 
+func TestGetAllItems(t *testing.T) {
+	d := newDb()
+	items, err := d.GetAllItems(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(len(items))
+}
+
+func TestGetBillExpense(t *testing.T) {
+	d := newDb()
+	name, amount, err := d.GetBillExpense(1, 1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(name, amount)
+}
+
+func TestGetBudgets(t *testing.T) {
+	d := newDb()
+	arr, err := d.GetBudgets(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(arr)
+}
+
+func TestGetBudgetSpent(t *testing.T) {
+	d := newDb()
+	sums, err := d.GetBudgetSpent(1, bundle.Budget{MainId: 1, Period: bundle.BudgetMonth}, "2022-01-01", "2022-12-31")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(sums)
+}
+
+func TestGetCurrency(t *testing.T) {
+	d := newDb()
+	code, err := d.GetCurrency(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(code)
+}
+
+func TestGetDueRecurring(t *testing.T) {
+	d := newDb()
+	arr, err := d.GetDueRecurring("2022-10-31")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(arr)
+}
+
+func TestGetDuplicateCandidates(t *testing.T) {
+	d := newDb()
+	now := time.Now()
+	end := now.Format("2006-01-02")
+	start := now.AddDate(-1, 0, 0).Format("2006-01-02")
+	items, err := d.GetDuplicateCandidates(1, start, end)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(len(items))
+}
+
+func TestGetFitIds(t *testing.T) {
+	d := newDb()
+	exist, err := d.GetFitIds(1, []string{"test"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(exist)
+}
+
 func TestGetItem(t *testing.T) {
 	d := newDb()
 	item, err := d.GetItem(1, -10)
@@ -89,6 +312,65 @@ func TestGetMainType(t *testing.T) {
 	}
 }
 
+func TestGetRates(t *testing.T) {
+	d := newDb()
+	list, err := d.GetRates(1, "", "2022-01-01", "2022-12-31")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, r := range list {
+		fmt.Println(r.Base, r.Currency, r.Date, r.Rate)
+	}
+}
+
+func TestGetRecurring(t *testing.T) {
+	d := newDb()
+	arr, err := d.GetRecurring(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(arr)
+}
+
+func TestGetRecurringExceptions(t *testing.T) {
+	d := newDb()
+	arr, err := d.GetRecurringExceptions(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(arr)
+}
+
+func TestGetRules(t *testing.T) {
+	d := newDb()
+	rules, err := d.GetRules(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, r := range rules {
+		fmt.Println(r.Id, r.Keyword, r.SubId, r.Priority)
+	}
+}
+
+func TestGetSameItems(t *testing.T) {
+	d := newDb()
+	items, err := d.GetSameItems(1, 6, 10, "2022-10-10")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(len(items))
+}
+
 func TestGetSubType(t *testing.T) {
 	d := newDb()
 	all, err := d.GetSubType(1, -1)
@@ -134,9 +416,80 @@ func TestGetSumByMonth(t *testing.T) {
 	}
 }
 
+func TestGetTransfers(t *testing.T) {
+	d := newDb()
+	list, err := d.GetTransfers(1, "2022-10-01", "2022-10-31")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, tr := range list {
+		fmt.Println(tr.Date, tr.FromId, tr.ToId, tr.Amount)
+	}
+}
+
+func TestImportItems(t *testing.T) {
+	d := newDb()
+	ids, err := d.ImportItems(1, 0, []bundle.ImportRow{
+		{Date: "2022-10-10", Name: "test", MainId: 2, SubId: 6, Increase: -1, Price: 10},
+		{Date: "2022-10-11", Name: "test", MainName: "test", SubName: "test", Increase: -1, Price: 20, FitId: "test"},
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(ids)
+}
+
+func TestInsertAccount(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertAccount(1, "test", bundle.AccountBank, 1000)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
+func TestInsertAlert(t *testing.T) {
+	d := newDb()
+	created, err := d.InsertAlert(1, bundle.Alert{Kind: bundle.AlertBill, Key: "bill:1", ItemId: 1, Message: "test"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(created)
+}
+
+func TestInsertBudget(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertBudget(1, bundle.Budget{MainId: 1, Period: bundle.BudgetMonth, Amount: 8000, Start: "2022-10-01"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
 func TestInsertItem(t *testing.T) {
 	d := newDb()
-	err := d.InsertItem(1, "test", 6, 10, "", "2022-10-10")
+	i, err := d.InsertItem(1, bundle.Bill{Name: "test", SubId: 6, Price: 1050, Date: "2022-10-10", Currency: "USD"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
+func TestInsertItemRefund(t *testing.T) {
+	d := newDb()
+	_, err := d.InsertItem(1, bundle.Bill{Name: "test", SubId: 6, Price: 50, Date: "2022-10-11", Refund: true, RefundOf: 1})
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -154,6 +507,48 @@ func TestInsertMainType(t *testing.T) {
 	fmt.Println(i)
 }
 
+func TestInsertRates(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertRates(1, []bundle.Rate{
+		{Currency: "USD", Date: "2022-10-01", Rate: 31.5},
+		{Currency: "JPY", Date: "2022-10-01", Rate: 0.22},
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
+func TestInsertRecurring(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertRecurring(1, bundle.Recurring{SubId: 6, Name: "test", Price: 300, Currency: "TWD",
+		Frequency: bundle.RecurMonthly, Interval: 1, Nth: -1, Weekday: 5, Start: "2022-10-01", Count: 12})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
+func TestInsertRule(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertRule(1, bundle.Rule{
+		Field:   bundle.RuleFieldName,
+		Match:   bundle.RuleMatchContains,
+		Keyword: "test",
+		SubId:   6,
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
 func TestInsertSubType(t *testing.T) {
 	d := newDb()
 	i, err := d.InsertSubType(1, 1, "test", true)
@@ -165,9 +560,142 @@ func TestInsertSubType(t *testing.T) {
 	fmt.Println(i)
 }
 
+func TestInsertTransfer(t *testing.T) {
+	d := newDb()
+	i, err := d.InsertTransfer(1, bundle.Transfer{FromId: 2, ToId: 1, Amount: 500, Date: "2022-10-10"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(i)
+}
+
+func TestMaterializeRecurring(t *testing.T) {
+	d := newDb()
+	arr, err := d.GetDueRecurring("2022-10-31")
+	if err != nil || len(arr) == 0 {
+		log.Fatal(err)
+		return
+	}
+
+	r := arr[0]
+	build := func([]bundle.RecurringException) []bundle.Bill {
+		return []bundle.Bill{{SubId: r.SubId, Name: r.Name, Price: r.Price, Date: "2022-10-28", Currency: r.Currency}}
+	}
+
+	// 重複執行不會多建立帳單
+	for i := 0; i < 2; i++ {
+		created, err := d.MaterializeRecurring(r, "2022-10-31", build)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		fmt.Println(len(created))
+	}
+}
+
+func TestReadAlerts(t *testing.T) {
+	d := newDb()
+	err := d.ReadAlerts(1, []int{1})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestRestoreArchive(t *testing.T) {
+	d := newDb()
+	a, err := d.GetArchive(1)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	r, err := d.RestoreArchive(1, a, false)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println(r.MainTypes, r.SubTypes, r.Bills, r.Rules)
+}
+
+func TestSearchItems(t *testing.T) {
+	d := newDb()
+	now := time.Now()
+	items, err := d.SearchItems(1, bundle.SearchQuery{
+		Keyword: "jcc",
+		Mode:    bundle.SearchModeFuzzy,
+		Name:    true,
+		Start:   now.AddDate(-1, 0, 0).Format("2006-01-02"),
+		End:     now.Format("2006-01-02"),
+		Limit:   50,
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, item := range items {
+		fmt.Println(item.Id, item.Name)
+	}
+}
+
+func TestSuggestName(t *testing.T) {
+	d := newDb()
+	since := time.Now().AddDate(-1, 0, 0).Format("2006-01-02")
+	list, err := d.SuggestName(1, "午", since, 10)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	for _, s := range list {
+		fmt.Println(s.Name, s.SubId, s.Price, s.Count, s.LastDate)
+	}
+}
+
+func TestUpdateAccount(t *testing.T) {
+	d := newDb()
+	err := d.UpdateAccount(1, 1, "test", bundle.AccountCreditCard, -500)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestUpdateAlertSetting(t *testing.T) {
+	d := newDb()
+	err := d.UpdateAlertSetting(1, bundle.AlertSetting{BillThreshold: 3000, Webhook: "https://example.com/hook"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestUpdateBudget(t *testing.T) {
+	d := newDb()
+	err := d.UpdateBudget(1, bundle.Budget{Id: -1, SubId: 6, Period: bundle.BudgetWeek, Amount: 2000, Rollover: true, Start: "2022-10-17"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestUpdateCurrency(t *testing.T) {
+	d := newDb()
+	err := d.UpdateCurrency(1, "TWD")
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
 func TestUpdateItem(t *testing.T) {
 	d := newDb()
-	err := d.UpdateItem(1, -10, "test", 1, 100, "remark", "2020-01-011")
+	err := d.UpdateItem(1, bundle.Bill{Id: -10, Name: "test", SubId: 1, Price: 100, Remark: "remark", Date: "2020-01-011"})
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -183,6 +711,40 @@ func TestUpdateMainType(t *testing.T) {
 	}
 }
 
+func TestUpdateOccurrence(t *testing.T) {
+	d := newDb()
+	err := d.UpdateOccurrence(1, bundle.RecurringException{RecurringId: -1, Date: "2022-11-25", Skip: true})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestUpdateRecurring(t *testing.T) {
+	d := newDb()
+	err := d.UpdateRecurring(1, bundle.Recurring{Id: -1, SubId: 6, Name: "test", Price: 350, Currency: "TWD",
+		Frequency: bundle.RecurWeekly, Interval: 2, Start: "2022-10-03", End: "2022-12-31"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
+func TestUpdateRule(t *testing.T) {
+	d := newDb()
+	err := d.UpdateRule(1, bundle.Rule{
+		Id:      -1,
+		Field:   bundle.RuleFieldAny,
+		Match:   bundle.RuleMatchRegex,
+		Keyword: "^test",
+		Remark:  "test",
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
+
 func TestUpdateSubType(t *testing.T) {
 	d := newDb()
 	err := d.UpdateSubType(1, -1, "Test", false)
@@ -191,3 +753,12 @@ func TestUpdateSubType(t *testing.T) {
 		return
 	}
 }
+
+func TestUpdateTransfer(t *testing.T) {
+	d := newDb()
+	err := d.UpdateTransfer(1, bundle.Transfer{Id: 1, FromId: 1, ToId: 2, Amount: 300, Date: "2022-10-11"})
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 依日期逐筆讀取帳單，不會一次載入全部資料
+func (d *Db) EachItem(userId int, start, end string, fn func(bundle.PreviewItem) error) error {
+	s := `SELECT b.id, m.id AS "main_id", m.name AS "main_name",
+				s.id AS "sub_id", s.name AS "sub_name", b.name,
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+			FROM bills AS b
+			LEFT JOIN sub_types AS s
+			ON b.sub_id=s.id
+			LEFT JOIN main_types AS m
+			ON m.id=s.main_id
+			WHERE b.user_id=$1 AND b.date BETWEEN $2 AND $3
+			ORDER BY b.date, b.id`
+
+	rows, err := d.db.Queryx(s, userId, start, end)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item bundle.PreviewItem
+		if err := rows.StructScan(&item); err != nil {
+			return errors.New(bundle.CodeDb)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
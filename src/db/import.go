@@ -0,0 +1,147 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"me.daily/src/bundle"
+)
+
+// 匯入帳單到指定帳戶，需要時建立主類別與子類別，全部成功才寫入
+//
+// 交易編號已存在的資料略過，回傳實際新增的帳單編號
+func (d *Db) ImportItems(userId, accountId int, rows []bundle.ImportRow) ([]int, error) {
+	ids := make([]int, 0, len(rows))
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	accountId, err = checkAccount(tx, userId, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	mains := make(map[string]int)
+	subs := make(map[string]int)
+
+	s := `INSERT INTO bills (user_id, name, sub_id, price, remark, date,
+				name_fold, name_phonetic, remark_fold, fit_id, account_id, currency)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11,
+				COALESCE(NULLIF($12, ''), ` + userCurrency + `))
+			ON CONFLICT (user_id, fit_id) WHERE fit_id IS NOT NULL DO NOTHING
+			RETURNING id`
+
+	for _, r := range rows {
+		if len(r.Error) > 0 {
+			return nil, errors.New(bundle.CodeImport)
+		}
+
+		mainId := r.MainId
+		if mainId == 0 {
+			var ok bool
+			if mainId, ok = mains[r.MainName]; !ok {
+				if mainId, err = importMainType(tx, userId, r.MainName); err != nil {
+					return nil, err
+				}
+				mains[r.MainName] = mainId
+			}
+		}
+
+		subId := r.SubId
+		if subId == 0 {
+			key := strconv.Itoa(mainId) + ":" + r.SubName
+			var ok bool
+			if subId, ok = subs[key]; !ok {
+				if subId, err = importSubType(tx, userId, mainId, r.SubName, r.Increase); err != nil {
+					return nil, err
+				}
+				subs[key] = subId
+			}
+		}
+
+		var id int
+		nameFold, namePhonetic, remarkFold := searchColumns(r.Name, r.Remark)
+		err := tx.QueryRow(s, userId, r.Name, subId, r.Price, r.Remark, r.Date, nameFold, namePhonetic, remarkFold, r.FitId, accountId, r.Currency).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, errors.New(bundle.CodeDb)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+
+	return ids, nil
+}
+
+// 已經匯入過的交易編號
+func (d *Db) GetFitIds(userId int, ids []string) (map[string]bool, error) {
+	exist := make(map[string]bool)
+	if len(ids) == 0 {
+		return exist, nil
+	}
+
+	list := make([]string, 0)
+	s := `SELECT fit_id FROM bills WHERE user_id=$1 AND fit_id = ANY($2)`
+	if err := d.db.Select(&list, s, userId, pq.Array(ids)); err != nil {
+		return exist, errors.New(bundle.CodeDb)
+	}
+
+	for _, id := range list {
+		exist[id] = true
+	}
+
+	return exist, nil
+}
+
+// 取得或建立主類別
+func importMainType(tx *sqlx.Tx, userId int, name string) (int, error) {
+	var id int
+	s := `SELECT id FROM main_types WHERE user_id=$1 AND name=$2 AND NOT deleted`
+	err := tx.Get(&id, s, userId, name)
+	if err == nil {
+		return id, nil
+	} else if err != sql.ErrNoRows {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	s = `INSERT INTO main_types (user_id, name)
+			VALUES ($1, $2) RETURNING id`
+	if err := tx.QueryRow(s, userId, name).Scan(&id); err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 取得或建立子類別
+func importSubType(tx *sqlx.Tx, userId, mainId int, name string, increase int) (int, error) {
+	var id int
+	s := `SELECT id FROM sub_types
+			WHERE user_id=$1 AND main_id=$2 AND name=$3 AND NOT deleted`
+	err := tx.Get(&id, s, userId, mainId, name)
+	if err == nil {
+		return id, nil
+	} else if err != sql.ErrNoRows {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	s = `INSERT INTO sub_types (name, user_id, main_id, increase)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`
+	if err := tx.QueryRow(s, name, userId, mainId, increase).Scan(&id); err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,305 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 搜尋欄位的版本，searchColumns 或讀音表變更時增加
+const searchVersion = 2
+
+// 資料表異動，每一句都必須可以重複執行
+var migrations = []string{
+	// 搜尋用欄位，由 searchColumns 產生，pg_trgm 由 createTrgm 安裝
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS name_fold TEXT`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS name_phonetic TEXT`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS remark_fold TEXT`,
+	`CREATE INDEX IF NOT EXISTS bills_name_fold_trgm ON bills USING GIN (name_fold gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS bills_name_phonetic_trgm ON bills USING GIN (name_phonetic gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS bills_remark_fold_trgm ON bills USING GIN (remark_fold gin_trgm_ops)`,
+	// 新的帳單使用目前的版本，舊版本的搜尋欄位由 fillSearchColumns 重新產生
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS search_version INT NOT NULL DEFAULT 1`,
+	`ALTER TABLE bills ALTER COLUMN search_version SET DEFAULT ` + strconv.Itoa(searchVersion),
+
+	// 匯入對帳單的交易編號
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS fit_id TEXT`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS bills_fit_id ON bills (user_id, fit_id) WHERE fit_id IS NOT NULL`,
+
+	// 名稱對應規則
+	`CREATE TABLE IF NOT EXISTS rules (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		keyword TEXT NOT NULL,
+		sub_id INT NOT NULL,
+		priority INT NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS rules_user_id ON rules (user_id)`,
+
+	// 規則的比對條件與動作，sub_id 為 0 表示不變更子類別
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS match_field TEXT NOT NULL DEFAULT 'name'`,
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS match_type TEXT NOT NULL DEFAULT 'contains'`,
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS min_price INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS max_price INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS rename TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE rules ADD COLUMN IF NOT EXISTS remark TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE rules ALTER COLUMN sub_id SET DEFAULT 0`,
+
+	// 帳戶，既有的使用者建立預設帳戶，舊帳單歸到預設帳戶
+	`CREATE TABLE IF NOT EXISTS accounts (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL DEFAULT 'cash',
+		opening INT NOT NULL DEFAULT 0,
+		deleted BOOLEAN NOT NULL DEFAULT false
+	)`,
+	`CREATE INDEX IF NOT EXISTS accounts_user_id ON accounts (user_id)`,
+	`INSERT INTO accounts (user_id, name)
+		SELECT u.id, '` + defaultAccount + `' FROM users AS u
+		WHERE NOT EXISTS (SELECT 1 FROM accounts AS a WHERE a.user_id=u.id)`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS account_id INT`,
+	`UPDATE bills AS b SET account_id=(SELECT MIN(a.id) FROM accounts AS a WHERE a.user_id=b.user_id)
+		WHERE account_id IS NULL`,
+	`CREATE INDEX IF NOT EXISTS bills_account_id ON bills (account_id, date)`,
+
+	// 帳戶間轉帳
+	`CREATE TABLE IF NOT EXISTS transfers (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		from_id INT NOT NULL,
+		to_id INT NOT NULL,
+		amount INT NOT NULL,
+		remark TEXT NOT NULL DEFAULT '',
+		date DATE NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS transfers_user_id ON transfers (user_id, date)`,
+
+	// 幣別與匯率，舊資料為預設幣別
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT '` + currency.Default + `'`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT '` + currency.Default + `'`,
+	// 帳戶期初餘額與轉帳金額的幣別，舊資料為目前的基準幣別
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE transfers ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT ''`,
+	`UPDATE accounts AS a SET currency=u.currency FROM users AS u WHERE a.user_id=u.id AND a.currency=''`,
+	`UPDATE transfers AS t SET currency=u.currency FROM users AS u WHERE t.user_id=u.id AND t.currency=''`,
+	`CREATE TABLE IF NOT EXISTS rates (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		base TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		date DATE NOT NULL,
+		rate NUMERIC(20, 10) NOT NULL
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS rates_currency_date ON rates (user_id, base, currency, date)`,
+
+	// 退款與調整，金額從同類別扣除，原帳單刪除時保留退款
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS refund BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS refund_of INT REFERENCES bills (id) ON DELETE SET NULL`,
+	`CREATE INDEX IF NOT EXISTS bills_refund_of ON bills (refund_of) WHERE refund_of IS NOT NULL`,
+
+	// 幣別的小數位數，金額以最小單位儲存
+	`CREATE OR REPLACE FUNCTION currency_scale(cur TEXT) RETURNS INT AS $$
+		SELECT CASE cur ` + scaleCases() + ` ELSE ` + strconv.Itoa(currency.DefaultScale) + ` END
+	$$ LANGUAGE SQL IMMUTABLE`,
+
+	// 帳單的最小單位換算成使用者基準幣別最小單位的比例，使用帳單日期當天或之前最近的匯率，沒有時為 NULL
+	`CREATE OR REPLACE FUNCTION bill_rate(uid INT, cur TEXT, d DATE) RETURNS NUMERIC AS $$
+		SELECT CASE WHEN cur=u.currency THEN 1 ELSE
+			(SELECT r.rate FROM rates AS r
+				WHERE r.user_id=uid AND r.base=u.currency AND r.currency=cur AND r.date<=d
+				ORDER BY r.date DESC LIMIT 1)
+			* POWER(10::NUMERIC, currency_scale(u.currency) - currency_scale(cur)) END
+		FROM users AS u WHERE u.id=uid
+	$$ LANGUAGE SQL STABLE`,
+
+	// 預算，main_id 與 sub_id 只有一個不為 0
+	`CREATE TABLE IF NOT EXISTS budgets (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		main_id INT NOT NULL DEFAULT 0,
+		sub_id INT NOT NULL DEFAULT 0,
+		period TEXT NOT NULL DEFAULT 'month',
+		amount INT NOT NULL,
+		rollover BOOLEAN NOT NULL DEFAULT false,
+		start DATE NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS budgets_user_id ON budgets (user_id)`,
+
+	// 提醒與站內收件匣，key 用來去除重複
+	`CREATE TABLE IF NOT EXISTS alerts (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		kind TEXT NOT NULL,
+		key TEXT NOT NULL,
+		message TEXT NOT NULL,
+		budget_id INT NOT NULL DEFAULT 0,
+		item_id INT NOT NULL DEFAULT 0,
+		read BOOLEAN NOT NULL DEFAULT false,
+		created TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS alerts_key ON alerts (user_id, key)`,
+	`CREATE TABLE IF NOT EXISTS alert_settings (
+		user_id INT PRIMARY KEY,
+		bill_threshold INT NOT NULL DEFAULT 0,
+		webhook TEXT NOT NULL DEFAULT '',
+		email TEXT NOT NULL DEFAULT ''
+	)`,
+
+	// 定期帳單範本，materialized 為已建立帳單的最後一天
+	`CREATE TABLE IF NOT EXISTS recurring (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		sub_id INT NOT NULL,
+		name TEXT NOT NULL,
+		price INT NOT NULL,
+		remark TEXT NOT NULL DEFAULT '',
+		account_id INT NOT NULL DEFAULT 0,
+		currency TEXT NOT NULL DEFAULT '',
+		frequency TEXT NOT NULL DEFAULT 'monthly',
+		step INT NOT NULL DEFAULT 1,
+		nth INT NOT NULL DEFAULT 0,
+		weekday INT NOT NULL DEFAULT 0,
+		start DATE NOT NULL,
+		end_date DATE,
+		count INT NOT NULL DEFAULT 0,
+		materialized DATE
+	)`,
+	`CREATE INDEX IF NOT EXISTS recurring_user_id ON recurring (user_id)`,
+	`CREATE TABLE IF NOT EXISTS recurring_exceptions (
+		recurring_id INT NOT NULL REFERENCES recurring (id) ON DELETE CASCADE,
+		date DATE NOT NULL,
+		skip BOOLEAN NOT NULL DEFAULT false,
+		name TEXT NOT NULL DEFAULT '',
+		price INT NOT NULL DEFAULT 0,
+		remark TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (recurring_id, date)
+	)`,
+	// 同一個範本同一天只會建立一筆帳單
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS recurring_id INT REFERENCES recurring (id) ON DELETE SET NULL`,
+	`ALTER TABLE bills ADD COLUMN IF NOT EXISTS recurring_date DATE`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS bills_recurring ON bills (recurring_id, recurring_date) WHERE recurring_id IS NOT NULL`,
+
+	// 已執行的資料轉換，每個名稱只執行一次
+	`CREATE TABLE IF NOT EXISTS data_migrations (
+		name TEXT PRIMARY KEY,
+		applied TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+}
+
+// 金額換算成最小單位，空白的幣別與預算、規則、提醒門檻都是使用者的基準幣別
+var minorUnits = []string{
+	`UPDATE bills SET price=price*` + scaleFactor("currency"),
+	`UPDATE accounts SET opening=opening*` + scaleFactor("currency"),
+	`UPDATE transfers SET amount=amount*` + scaleFactor("currency"),
+	`UPDATE recurring AS r SET price=r.price*` + scaleFactor("COALESCE(NULLIF(r.currency, ''), u.currency)") + `
+		FROM users AS u WHERE r.user_id=u.id`,
+	`UPDATE recurring_exceptions AS e SET price=e.price*` + scaleFactor("COALESCE(NULLIF(r.currency, ''), u.currency)") + `
+		FROM recurring AS r INNER JOIN users AS u ON r.user_id=u.id WHERE e.recurring_id=r.id`,
+	`UPDATE budgets AS b SET amount=b.amount*` + scaleFactor("u.currency") + `
+		FROM users AS u WHERE b.user_id=u.id`,
+	`UPDATE rules AS r SET min_price=r.min_price*` + scaleFactor("u.currency") + `,
+		max_price=r.max_price*` + scaleFactor("u.currency") + `
+		FROM users AS u WHERE r.user_id=u.id`,
+	`UPDATE alert_settings AS a SET bill_threshold=a.bill_threshold*` + scaleFactor("u.currency") + `
+		FROM users AS u WHERE a.user_id=u.id`,
+}
+
+// 更新資料表
+func (d *Db) migrate() error {
+	if err := d.createTrgm(); err != nil {
+		return err
+	}
+
+	for _, s := range migrations {
+		if _, err := d.db.Exec(s); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+
+	if err := d.runOnce("minor_units", minorUnits); err != nil {
+		return err
+	}
+
+	return d.fillSearchColumns()
+}
+
+// 執行資料轉換，與記錄名稱在同一個交易內，已執行過或其他程序同時執行完成時略過
+func (d *Db) runOnce(name string, statements []string) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", name, err)
+	}
+	defer tx.Rollback()
+
+	s := `INSERT INTO data_migrations (name) VALUES ($1) ON CONFLICT DO NOTHING`
+	result, err := tx.Exec(s, name)
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", name, err)
+	}
+
+	if row, _ := result.RowsAffected(); row == 0 {
+		return nil
+	}
+
+	for _, s := range statements {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("migrate %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrate %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// 安裝 pg_trgm，已安裝時略過
+//
+// 建立 extension 需要資料庫管理權限，應用程式的帳號通常沒有，失敗時說明如何手動安裝
+func (d *Db) createTrgm() error {
+	var installed bool
+	s := `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname='pg_trgm')`
+	if err := d.db.Get(&installed, s); err != nil {
+		return fmt.Errorf("check pg_trgm extension: %w", err)
+	}
+
+	if installed {
+		return nil
+	}
+
+	if _, err := d.db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		return fmt.Errorf("pg_trgm extension is not installed and could not be created, "+
+			"run \"CREATE EXTENSION pg_trgm\" as a database superuser: %w", err)
+	}
+
+	return nil
+}
+
+// 補上舊資料的搜尋欄位，版本較舊的重新產生
+func (d *Db) fillSearchColumns() error {
+	rows := make([]struct {
+		Id     int
+		Name   string
+		Remark string
+	}, 0)
+
+	s := `SELECT id, name, remark FROM bills WHERE name_fold IS NULL OR search_version<$1`
+	if err := d.db.Select(&rows, s, searchVersion); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	s = `UPDATE bills SET name_fold=$1, name_phonetic=$2, remark_fold=$3, search_version=$5 WHERE id=$4`
+	for _, r := range rows {
+		nameFold, namePhonetic, remarkFold := searchColumns(r.Name, r.Remark)
+		if _, err := d.db.Exec(s, nameFold, namePhonetic, remarkFold, r.Id, searchVersion); err != nil {
+			return errors.New(bundle.CodeDb)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 定期帳單欄位
+const recurringColumns = `r.id, r.user_id, r.sub_id, r.name, r.price, r.remark, r.account_id, r.currency,
+				r.frequency, r.step, r.nth, r.weekday, TO_CHAR(r.start, 'yyyy-mm-dd') AS "start",
+				COALESCE(TO_CHAR(r.end_date, 'yyyy-mm-dd'), '') AS "end_date", r.count,
+				COALESCE(TO_CHAR(r.materialized, 'yyyy-mm-dd'), '') AS "materialized"`
+
+// 確認定期帳單的類別與帳戶持有者
+func (d *Db) checkRecurring(userId int, r bundle.Recurring) error {
+	if err := d.checkSub(userId, r.SubId); err != nil {
+		return err
+	}
+
+	if r.AccountId != 0 {
+		if _, err := checkAccount(d.db, userId, r.AccountId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 刪除定期帳單，已建立的帳單保留
+func (d *Db) DeleteRecurring(userId, id int) error {
+	s := `DELETE FROM recurring WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
+
+// 取得到 date 為止還沒建立完帳單的定期帳單，包含全部使用者，排除類別已刪除的範本
+func (d *Db) GetDueRecurring(date string) ([]bundle.Recurring, error) {
+	arr := make([]bundle.Recurring, 0)
+
+	s := `SELECT ` + recurringColumns + `
+			FROM recurring AS r
+			INNER JOIN sub_types AS s
+			ON r.sub_id=s.id
+			WHERE NOT s.deleted AND r.start<=$1 AND (r.materialized IS NULL OR r.materialized<$1)
+			ORDER BY r.user_id, r.id`
+	err := d.db.Select(&arr, s, date)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 取得定期帳單，排除類別已刪除的範本
+func (d *Db) GetRecurring(userId int) ([]bundle.Recurring, error) {
+	arr := make([]bundle.Recurring, 0)
+
+	s := `SELECT ` + recurringColumns + `
+			FROM recurring AS r
+			INNER JOIN sub_types AS s
+			ON r.sub_id=s.id
+			WHERE r.user_id=$1 AND NOT s.deleted
+			ORDER BY r.id`
+	err := d.db.Select(&arr, s, userId)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 取得使用者定期帳單的例外
+func (d *Db) GetRecurringExceptions(userId int) ([]bundle.RecurringException, error) {
+	arr := make([]bundle.RecurringException, 0)
+
+	s := `SELECT e.recurring_id, TO_CHAR(e.date, 'yyyy-mm-dd') AS "date", e.skip, e.name, e.price, e.remark
+			FROM recurring_exceptions AS e
+			INNER JOIN recurring AS r
+			ON e.recurring_id=r.id
+			WHERE r.user_id=$1
+			ORDER BY e.recurring_id, e.date`
+	err := d.db.Select(&arr, s, userId)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 新增定期帳單
+func (d *Db) InsertRecurring(userId int, r bundle.Recurring) (int, error) {
+	if err := d.checkRecurring(userId, r); err != nil {
+		return 0, err
+	}
+
+	var id int
+	s := `INSERT INTO recurring (user_id, sub_id, name, price, remark, account_id, currency,
+				frequency, step, nth, weekday, start, end_date, count)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, '')::DATE, $14)
+			RETURNING id`
+	err := d.db.QueryRow(s, userId, r.SubId, r.Name, r.Price, r.Remark, r.AccountId, r.Currency,
+		r.Frequency, r.Interval, r.Nth, r.Weekday, r.Start, r.End, r.Count).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 建立定期帳單到 through 為止的帳單，並記錄已建立到 through，回傳實際新增的帳單與編號
+//
+// 鎖定範本後讀取例外，由 build 依例外產生帳單，與 UpdateOccurrence 不會同時進行；
+// 帳單日期即為該次的日期，以 (recurring_id, recurring_date) 去除重複，重複執行不會多建立帳單；範本的帳戶已刪除時使用預設帳戶
+func (d *Db) MaterializeRecurring(r bundle.Recurring, through string,
+	build func([]bundle.RecurringException) []bundle.Bill) ([]bundle.Bill, error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	var id int
+	s := `SELECT id FROM recurring WHERE id=$1 FOR UPDATE`
+	if err := tx.Get(&id, s, r.Id); err == sql.ErrNoRows {
+		return nil, errors.New(bundle.CodeNoData)
+	} else if err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+
+	exceptions := make([]bundle.RecurringException, 0)
+	s = `SELECT recurring_id, TO_CHAR(date, 'yyyy-mm-dd') AS "date", skip, name, price, remark
+			FROM recurring_exceptions
+			WHERE recurring_id=$1`
+	if err := tx.Select(&exceptions, s, r.Id); err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+
+	bills := build(exceptions)
+	created := make([]bundle.Bill, 0, len(bills))
+
+	accountId, err := checkAccount(tx, r.UserId, r.AccountId)
+	if err != nil && r.AccountId != 0 {
+		accountId, err = checkAccount(tx, r.UserId, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s = `INSERT INTO bills (user_id, name, sub_id, price, remark, date,
+				name_fold, name_phonetic, remark_fold, account_id, currency, recurring_id, recurring_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), ` + userCurrency + `), $12, $13)
+			ON CONFLICT (recurring_id, recurring_date) WHERE recurring_id IS NOT NULL DO NOTHING
+			RETURNING id`
+	for _, b := range bills {
+		nameFold, namePhonetic, remarkFold := searchColumns(b.Name, b.Remark)
+
+		err := tx.QueryRow(s, r.UserId, b.Name, b.SubId, b.Price, b.Remark, b.Date,
+			nameFold, namePhonetic, remarkFold, accountId, b.Currency, r.Id, b.Date).Scan(&b.Id)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, errors.New(bundle.CodeDb)
+		}
+
+		created = append(created, b)
+	}
+
+	s = `UPDATE recurring SET materialized=$2 WHERE id=$1`
+	if _, err := tx.Exec(s, r.Id, through); err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.New(bundle.CodeDb)
+	}
+
+	return created, nil
+}
+
+// 略過或修改定期帳單的某一次，已建立帳單的日期不能修改
+//
+// 沒有略過也沒有修改時刪除例外；鎖定範本，避免同時建立該次的帳單
+func (d *Db) UpdateOccurrence(userId int, e bundle.RecurringException) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+	defer tx.Rollback()
+
+	var done bool
+	s := `SELECT COALESCE(materialized>=$3, false) FROM recurring WHERE user_id=$1 AND id=$2 FOR UPDATE`
+	err = tx.Get(&done, s, userId, e.RecurringId, e.Date)
+	if err == sql.ErrNoRows {
+		return errors.New(bundle.CodeNoData)
+	} else if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if done {
+		return errors.New(bundle.CodeRecurring)
+	}
+
+	if !e.Skip && len(e.Name) == 0 && e.Price == 0 && len(e.Remark) == 0 {
+		s = `DELETE FROM recurring_exceptions WHERE recurring_id=$1 AND date=$2`
+		_, err = tx.Exec(s, e.RecurringId, e.Date)
+	} else {
+		s = `INSERT INTO recurring_exceptions (recurring_id, date, skip, name, price, remark)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (recurring_id, date) DO UPDATE SET skip=$3, name=$4, price=$5, remark=$6`
+		_, err = tx.Exec(s, e.RecurringId, e.Date, e.Skip, e.Name, e.Price, e.Remark)
+	}
+
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	return nil
+}
+
+// 更新定期帳單，已建立的帳單不變更
+func (d *Db) UpdateRecurring(userId int, r bundle.Recurring) error {
+	if err := d.checkRecurring(userId, r); err != nil {
+		return err
+	}
+
+	s := `UPDATE recurring SET sub_id=$3, name=$4, price=$5, remark=$6, account_id=$7, currency=$8,
+				frequency=$9, step=$10, nth=$11, weekday=$12, start=$13, end_date=NULLIF($14, '')::DATE, count=$15
+			WHERE user_id=$1 AND id=$2`
+	res, err := d.db.Exec(s, userId, r.Id, r.SubId, r.Name, r.Price, r.Remark, r.AccountId, r.Currency,
+		r.Frequency, r.Interval, r.Nth, r.Weekday, r.Start, r.End, r.Count)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := res.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 退款的金額從同類別扣除，需要 bills AS b
+const refundSign = `(CASE WHEN b.refund THEN -1 ELSE 1 END)`
+
+// 確認退款的原帳單，需要相同的子類別與幣別，且退款總額不超過原金額
+//
+// 修改時 item.Id 為退款本身，不計入已退款的金額
+func (d *Db) checkRefund(userId int, item bundle.Bill) error {
+	if item.RefundOf == 0 {
+		return nil
+	}
+
+	if !item.Refund || item.RefundOf == item.Id {
+		return errors.New(bundle.CodeRefund)
+	}
+
+	var origin struct {
+		SubId    int    `db:"sub_id"`
+		Price    int    `db:"price"`
+		Currency string `db:"currency"`
+		Refund   bool   `db:"refund"`
+		Refunded int    `db:"refunded"`
+	}
+
+	s := `SELECT b.sub_id, b.price, b.currency, b.refund,
+				COALESCE((SELECT SUM(r.price) FROM bills AS r
+					WHERE r.refund_of=b.id AND r.id<>$3), 0) AS "refunded"
+			FROM bills AS b
+			WHERE b.user_id=$1 AND b.id=$2`
+	err := d.db.Get(&origin, s, userId, item.RefundOf, item.Id)
+	if err == sql.ErrNoRows {
+		return errors.New(bundle.CodeRefund)
+	} else if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	if origin.Refund || origin.SubId != item.SubId || origin.Refunded+item.Price > origin.Price {
+		return errors.New(bundle.CodeRefund)
+	}
+
+	// 修改時幣別空白表示不變更
+	if len(item.Currency) > 0 && origin.Currency != item.Currency {
+		return errors.New(bundle.CodeRefund)
+	}
+
+	return nil
+}
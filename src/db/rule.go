@@ -0,0 +1,93 @@
+package db
+
+import (
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 規則欄位
+const ruleColumns = `r.id, r.match_field, r.match_type, r.keyword, r.min_price, r.max_price,
+				r.sub_id, r.rename, r.remark, r.priority`
+
+// 刪除規則
+func (d *Db) DeleteRule(userId, id int) error {
+	s := `DELETE FROM rules WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
+
+// 取得規則，依比對順序排列，排除子類別已刪除的規則
+func (d *Db) GetRules(userId int) ([]bundle.Rule, error) {
+	arr := make([]bundle.Rule, 0)
+
+	s := `SELECT ` + ruleColumns + `
+			FROM rules AS r
+			LEFT JOIN sub_types AS s
+			ON r.sub_id=s.id
+			WHERE r.user_id=$1 AND (r.sub_id=0 OR NOT s.deleted)
+			ORDER BY r.priority, r.id`
+	err := d.db.Select(&arr, s, userId)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 新增規則
+func (d *Db) InsertRule(userId int, r bundle.Rule) (int, error) {
+	if r.SubId != 0 {
+		if err := d.checkSub(userId, r.SubId); err != nil {
+			return 0, err
+		}
+	}
+
+	var id int
+	s := `INSERT INTO rules (user_id, match_field, match_type, keyword, min_price, max_price,
+				sub_id, rename, remark, priority)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := d.db.QueryRow(s, userId, r.Field, r.Match, r.Keyword, r.MinPrice, r.MaxPrice,
+		r.SubId, r.Rename, r.Remark, r.Priority).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 更新規則
+func (d *Db) UpdateRule(userId int, r bundle.Rule) error {
+	if r.SubId != 0 {
+		if err := d.checkSub(userId, r.SubId); err != nil {
+			return err
+		}
+	}
+
+	s := `UPDATE rules SET match_field=$3, match_type=$4, keyword=$5, min_price=$6, max_price=$7,
+				sub_id=$8, rename=$9, remark=$10, priority=$11
+			WHERE user_id=$1 AND id=$2`
+	result, err := d.db.Exec(s, userId, r.Id, r.Field, r.Match, r.Keyword, r.MinPrice, r.MaxPrice,
+		r.SubId, r.Rename, r.Remark, r.Priority)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := result.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
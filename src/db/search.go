@@ -0,0 +1,181 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/transformer"
+)
+
+// 名稱另外存放的拼音與注音
+var phonetics = []*transformer.Phonetic{
+	transformer.PinyinInitials,
+	transformer.ZhuyinInitials,
+	transformer.Pinyin,
+	transformer.Zhuyin,
+}
+
+// 搜尋欄位
+//
+// 中文沒有分詞，tsvector 無法比對部分字詞，改用 pg_trgm 建立索引。
+// 名稱與備註先經過全形、繁簡、大小寫統一，名稱另外存放拼音與注音，多音字的每種讀音都存放
+func searchColumns(name, remark string) (nameFold, namePhonetic, remarkFold string) {
+	nameFold = foldKeyword(name)
+	remarkFold = foldKeyword(remark)
+
+	forms := make([]string, 0, len(phonetics))
+	for _, p := range phonetics {
+		forms = append(forms, p.Variants(nameFold)...)
+	}
+	namePhonetic = strings.Join(forms, " ")
+
+	return
+}
+
+// 關鍵字統一成搜尋欄位的格式
+func foldKeyword(s string) string {
+	f, _, err := transform.String(transformer.NewChineseFold(), s)
+	if err != nil {
+		return s
+	}
+	return f
+}
+
+// LIKE 跳脫 % _ \
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// 依序包含每個字的 LIKE 條件，與模糊比對的規則相同，例如 "jcc" 為 "%j%c%c%"
+func subsequenceLike(s string) string {
+	var b strings.Builder
+	b.WriteString("%")
+	for _, r := range s {
+		b.WriteString(escapeLike(string(r)))
+		b.WriteString("%")
+	}
+
+	return b.String()
+}
+
+// 搜尋候選帳單，依相似度排序，最多 q.Limit 筆
+//
+// 資料庫只做篩選，service 比對會符合的帳單都在候選內：模糊搜尋找出依序包含每個字的帳單，
+// 名稱另外比對關鍵字的拼音與注音，再加上 pg_trgm 相似的帳單。排序與分頁由 service 在全部候選上進行
+func (d *Db) SearchItems(userId int, q bundle.SearchQuery) ([]bundle.PreviewItem, error) {
+	items := make([]bundle.PreviewItem, 0)
+
+	keyword := foldKeyword(q.Keyword)
+	args := map[string]interface{}{
+		"user_id":     userId,
+		"start":       q.Start,
+		"end":         q.End,
+		"keyword":     keyword,
+		"like":        "%" + escapeLike(keyword) + "%",
+		"subsequence": subsequenceLike(keyword),
+		"limit":       q.Limit,
+	}
+
+	// 搜尋欄位
+	columns := make([]string, 0)
+	if q.Name {
+		columns = append(columns, "b.name_fold")
+	}
+	if q.Remark {
+		columns = append(columns, "b.remark_fold")
+	}
+	if len(columns) == 0 {
+		return items, errors.New(bundle.CodeFormat)
+	}
+
+	conditions := make([]string, 0)
+	similarity := make([]string, 0)
+	for _, c := range columns {
+		switch q.Mode {
+		case bundle.SearchModeExact:
+			conditions = append(conditions, c+" = :keyword")
+		case bundle.SearchModeSubstring:
+			conditions = append(conditions, c+` LIKE :like ESCAPE '\'`)
+		default:
+			conditions = append(conditions, fmt.Sprintf(`%s LIKE :subsequence ESCAPE '\' OR :keyword <%% %s`, c, c))
+		}
+		similarity = append(similarity, fmt.Sprintf("word_similarity(:keyword, %s)", c))
+	}
+
+	// 模糊搜尋時名稱包含拼音注音，關鍵字也轉成相同的形式
+	if q.Name && q.Mode == bundle.SearchModeFuzzy {
+		for i, t := range phonetics {
+			form, _, err := transform.String(t, keyword)
+			if err != nil {
+				continue
+			}
+
+			name := fmt.Sprintf("phonetic%d", i)
+			args[name] = subsequenceLike(form)
+			conditions = append(conditions, fmt.Sprintf(`b.name_phonetic LIKE :%s ESCAPE '\'`, name))
+		}
+		conditions = append(conditions, ":keyword <% b.name_phonetic")
+		similarity = append(similarity, "word_similarity(:keyword, b.name_phonetic)")
+	}
+
+	s := fmt.Sprintf(`SELECT b.id, m.id AS "main_id", m.name AS "main_name",
+				s.id AS "sub_id", s.name AS "sub_name", b.name,
+				b.price, s.increase, b.refund, COALESCE(b.refund_of, 0) AS "refund_of", b.remark, b.account_id,
+				b.currency, currency_scale(b.currency) AS "scale", TO_CHAR(b.date, 'yyyy-mm-dd') AS "date"
+			FROM bills AS b
+			LEFT JOIN sub_types AS s
+			ON b.sub_id=s.id
+			LEFT JOIN main_types AS m
+			ON m.id=s.main_id
+			WHERE b.user_id=:user_id AND b.date BETWEEN :start AND :end
+				AND (%s)
+			ORDER BY GREATEST(%s) DESC, b.date, b.id
+			LIMIT :limit`,
+		strings.Join(conditions, " OR "), strings.Join(similarity, ", "))
+
+	s, list, err := sqlx.Named(s, args)
+	if err != nil {
+		return items, errors.New(bundle.CodeDb)
+	}
+
+	err = d.db.Select(&items, d.db.Rebind(s), list...)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return items, err
+}
+
+// 依前綴建議名稱，常用且最近使用的在前面
+//
+// 同時比對拼音注音，例如 "jc" 可以找到 "計程車"
+func (d *Db) SuggestName(userId int, prefix, since string, limit int) ([]bundle.NameSuggestion, error) {
+	arr := make([]bundle.NameSuggestion, 0)
+
+	keyword := escapeLike(foldKeyword(prefix))
+
+	s := `SELECT b.name, COUNT(1) AS "count", TO_CHAR(MAX(b.date), 'yyyy-mm-dd') AS "last_date",
+				(ARRAY_AGG(b.sub_id ORDER BY b.date DESC, b.id DESC))[1] AS "sub_id",
+				(ARRAY_AGG(b.price ORDER BY b.date DESC, b.id DESC))[1] AS "price"
+			FROM bills AS b
+			INNER JOIN sub_types AS s
+			ON b.sub_id=s.id
+			WHERE b.user_id=$1 AND b.date >= $2 AND NOT s.deleted
+				AND (b.name_fold LIKE $3 ESCAPE '\' 
+					OR b.name_phonetic LIKE $3 ESCAPE '\' 
+					OR b.name_phonetic LIKE $4 ESCAPE '\')
+			GROUP BY b.name
+			ORDER BY "count" DESC, MAX(b.date) DESC
+			LIMIT $5`
+
+	err := d.db.Select(&arr, s, userId, since, keyword+"%", "% "+keyword+"%", limit)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
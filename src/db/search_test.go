@@ -0,0 +1,60 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEscapeLike(t *testing.T) {
+	if s := escapeLike(`50%_off\`); s != `50\%\_off\\` {
+		t.Fatalf("unexpected %q", s)
+	}
+}
+
+func TestSearchColumns(t *testing.T) {
+	nameFold, namePhonetic, remarkFold := searchColumns("計程車ＫＴＶ", "公司")
+	if nameFold != "计程车ktv" {
+		t.Fatalf("unexpected name %q", nameFold)
+	}
+
+	for _, form := range []string{"jccktv", "ㄐㄔㄔktv", "jichengchektv"} {
+		if !strings.Contains(namePhonetic, form) {
+			t.Errorf("%q not in %q", form, namePhonetic)
+		}
+	}
+
+	if remarkFold != "公司" {
+		t.Fatalf("unexpected remark %q", remarkFold)
+	}
+}
+
+func TestSearchNamedQuery(t *testing.T) {
+	s, args, err := sqlx.Named(`SELECT 1 WHERE a LIKE :like ESCAPE '\' OR :keyword <% a`, map[string]interface{}{
+		"like":    "%a%",
+		"keyword": "a",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != `SELECT 1 WHERE a LIKE ? ESCAPE '\' OR ? <% a` || len(args) != 2 {
+		t.Fatalf("unexpected %q %v", s, args)
+	}
+}
+
+func TestSubsequenceLike(t *testing.T) {
+	cases := map[string]string{
+		"jcc":  "%j%c%c%",
+		"計程車":  "%計%程%車%",
+		"50%_": `%5%0%\%%\_%`,
+		"":     "%",
+	}
+
+	for s, expected := range cases {
+		if like := subsequenceLike(s); like != expected {
+			t.Errorf("subsequenceLike(%q) = %q, want %q", s, like, expected)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"errors"
+
+	"me.daily/src/bundle"
+)
+
+// 確認轉出與轉入帳戶
+func (d *Db) checkTransfer(userId, fromId, toId int) error {
+	if fromId == toId {
+		return errors.New(bundle.CodeTransfer)
+	}
+
+	if _, err := checkAccount(d.db, userId, fromId); err != nil {
+		return err
+	}
+
+	if _, err := checkAccount(d.db, userId, toId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 刪除轉帳
+func (d *Db) DeleteTransfer(userId, id int) error {
+	s := `DELETE FROM transfers WHERE user_id=$1 AND id=$2`
+	r, err := d.db.Exec(s, userId, id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
+
+// 取得日期區間內的轉帳
+func (d *Db) GetTransfers(userId int, start, end string) ([]bundle.Transfer, error) {
+	arr := make([]bundle.Transfer, 0)
+
+	s := `SELECT id, from_id, to_id, amount, remark, TO_CHAR(date, 'yyyy-mm-dd') AS "date"
+			FROM transfers
+			WHERE user_id=$1 AND date BETWEEN $2 AND $3
+			ORDER BY date, id`
+	err := d.db.Select(&arr, s, userId, start, end)
+	if err != nil {
+		err = errors.New(bundle.CodeDb)
+	}
+
+	return arr, err
+}
+
+// 新增轉帳
+func (d *Db) InsertTransfer(userId int, t bundle.Transfer) (int, error) {
+	if err := d.checkTransfer(userId, t.FromId, t.ToId); err != nil {
+		return 0, err
+	}
+
+	var id int
+	s := `INSERT INTO transfers (user_id, from_id, to_id, amount, remark, date, currency)
+			VALUES ($1, $2, $3, $4, $5, $6, ` + userCurrency + `) RETURNING id`
+	err := d.db.QueryRow(s, userId, t.FromId, t.ToId, t.Amount, t.Remark, t.Date).Scan(&id)
+	if err != nil {
+		return 0, errors.New(bundle.CodeDb)
+	}
+
+	return id, nil
+}
+
+// 更新轉帳，金額改為目前的基準幣別
+func (d *Db) UpdateTransfer(userId int, t bundle.Transfer) error {
+	if err := d.checkTransfer(userId, t.FromId, t.ToId); err != nil {
+		return err
+	}
+
+	s := `UPDATE transfers SET from_id=$1, to_id=$2, amount=$3, remark=$4, date=$5,
+				currency=(SELECT currency FROM users WHERE id=$6)
+			WHERE user_id=$6 AND id=$7`
+	r, err := d.db.Exec(s, t.FromId, t.ToId, t.Amount, t.Remark, t.Date, userId, t.Id)
+	if err != nil {
+		return errors.New(bundle.CodeDb)
+	}
+
+	row, _ := r.RowsAffected()
+
+	if row == 0 {
+		return errors.New(bundle.CodeNoData)
+	}
+
+	return nil
+}
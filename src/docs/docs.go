@@ -0,0 +1,4760 @@
+// Code generated by swaggo/swag. DO NOT EDIT.
+
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/api/account": {
+            "put": {
+                "description": "修改帳戶名稱、類型與期初餘額",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改帳戶",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateAccountResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立帳戶，type 為 cash、bank、credit_card、e_wallet，預設 cash，opening 為基準幣別的期初餘額，信用卡可以是負數",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立帳戶",
+                "parameters": [
+                    {
+                        "description": "建立帳戶",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateAccountResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/account/{account_id}": {
+            "delete": {
+                "description": "刪除帳戶，帳單保留在原帳戶。不能刪除最後一個帳戶，回傳 E-029",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除帳戶",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "帳戶編號",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteAccountResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/account/{account_id}/items": {
+            "get": {
+                "description": "取得帳戶在日期區間內的帳單、轉帳與逐筆餘額，opening 為區間開始前的餘額，未指定日期時取得全部",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得帳戶明細",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "帳戶編號",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetAccountItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/accounts": {
+            "get": {
+                "description": "取得帳戶與目前餘額，餘額為期初餘額加上收入與轉入，減去支出與轉出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得帳戶",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetAccountsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/alert/setting": {
+            "get": {
+                "description": "取得單筆提醒金額與通知方式，金額為基準幣別的最小單位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得提醒設定",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetAlertSettingResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改單筆提醒金額與通知方式。bill_threshold 為基準幣別的金額，0 表示不提醒；webhook 必須是 http 或 https 網址且不可指向本機或內部網路，會以 JSON POST 提醒內容；\nemail 需要伺服器設定寄信伺服器。站內收件匣一定會收到提醒，格式錯誤回傳 E-036",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改提醒設定",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateAlertSettingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateAlertSettingResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/alerts": {
+            "get": {
+                "description": "取得站內收件匣最近 100 筆提醒與未讀數量。預算達到 80% 與 100%，或單筆支出超過提醒金額時產生，同一期間同一門檻只提醒一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得提醒",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "只取得未讀",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetAlertsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/alerts/read": {
+            "put": {
+                "description": "將提醒標為已讀，alert_ids 空白時全部已讀",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "提醒已讀",
+                "parameters": [
+                    {
+                        "description": "已讀",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ReadAlertsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ReadAlertsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/all": {
+            "get": {
+                "description": "取得全部類別",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得全部類別",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetAllTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/backup": {
+            "get": {
+                "description": "匯出帳號全部資料，包含已刪除的類別、定期帳單的例外、基準幣別與提醒設定，每個區段附上 SHA-256 檢查碼",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "備份帳號",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/archive.Archive"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/budget": {
+            "put": {
+                "description": "修改預算，欄位規則與建立預算相同",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改預算",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateBudgetResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立主類別或子類別的預算，main_id 與 sub_id 只能指定一個。period 為 week、month、year，預設 month，每週從星期一開始。\namount 為基準幣別的金額，格式與項目的 price 相同。start 預設今天，會調整到所在期間的第一天，rollover 為 true 時從 start 開始累計未用完的金額。錯誤回傳 E-035",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立預算",
+                "parameters": [
+                    {
+                        "description": "建立預算",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateBudgetResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/budget/{budget_id}": {
+            "delete": {
+                "description": "刪除預算",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除預算",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "預算編號",
+                        "name": "budget_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteBudgetResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/budgets": {
+            "get": {
+                "description": "取得預算，不包含類別已刪除的預算，金額為基準幣別的最小單位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得預算",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetBudgetsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/budgets/status": {
+            "get": {
+                "description": "取得日期所在期間的支出、剩餘與預估的期末支出，支出的計算方式與主類別總和相同，缺少匯率時回傳 E-032",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得預算狀態",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "日期，預設今天",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetBudgetStatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/currency": {
+            "get": {
+                "description": "取得基準幣別與小數位數，統計與帳戶餘額都換算成基準幣別的最小單位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得基準幣別",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetCurrencyResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改基準幣別，統計改用新基準幣別的匯率換算。帳戶期初餘額、轉帳、預算與單筆提醒金額以基準幣別記錄，還有這些金額時回傳 E-038",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改基準幣別",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateCurrencyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/duplicates": {
+            "get": {
+                "description": "取得子類別、金額、日期相同且名稱相似的帳單",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得重複帳單",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetDuplicatesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/export": {
+            "get": {
+                "description": "依日期逐筆匯出帳單，包含主類別、子類別、收支、備註。省略日期時匯出全部，bom 為 true 時 CSV 開頭加上 UTF-8 BOM 讓 Excel 正確顯示中文。參數錯誤時回傳 bundle.ErrorResponse",
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "匯出帳單",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "格式",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "CSV 加上 UTF-8 BOM",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/import/csv": {
+            "post": {
+                "description": "依欄位對應匯入帳單，金額可以有小數但不可超過幣別的小數位數，每一行的錯誤記錄在 rows[].error。與現有帳單的子類別、金額、日期相同且名稱相近的資料標記 rows[].skipped 並略過，force 時不檢查。dry_run 時只回傳預覽，否則有任何一筆錯誤就全部不寫入並回傳 E-025",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "匯入 CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV 檔案",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "欄位對應，bundle.CsvMapping 的 JSON",
+                        "name": "mapping",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只預覽不寫入",
+                        "name": "dry_run",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "不檢查重複",
+                        "name": "force",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/import/ofx": {
+            "post": {
+                "description": "匯入銀行或信用卡 OFX 對帳單，依規則、預設子類別決定類別。已匯入過的交易 (FITID) 略過，找不到類別的不匯入",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "匯入 OFX",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "OFX 檔案",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "沒有符合規則時使用的子類別",
+                        "name": "sub_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "匯入的帳戶，預設為預設帳戶",
+                        "name": "account_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只預覽不寫入",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.StatementImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/import/qif": {
+            "post": {
+                "description": "匯入 QIF 對帳單，依規則、檔案內的類別名稱、預設子類別決定類別。已匯入過的交易略過，找不到類別的不匯入",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "匯入 QIF",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "QIF 檔案",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "mdy",
+                            "dmy",
+                            "ymd"
+                        ],
+                        "type": "string",
+                        "default": "mdy",
+                        "description": "日期順序",
+                        "name": "date_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "沒有符合規則時使用的子類別",
+                        "name": "sub_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "匯入的帳戶，預設為預設帳戶",
+                        "name": "account_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只預覽不寫入",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.StatementImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/import/rates": {
+            "post": {
+                "description": "匯入匯率 CSV，第一行為標題 date,currency,rate，同一天已有匯率時覆蓋。\n格式錯誤時全部不寫入並回傳 E-025，原因記錄在 error",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "匯入匯率",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV 檔案",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ImportRatesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/item": {
+            "put": {
+                "description": "修改項目，currency 空白時不變更，price、refund 與 refund_of 的規則與建立項目相同",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改項目",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateItemResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立項目，先依規則修改類別、名稱與備註，有相同子類別、金額、日期且名稱相似的帳單時回傳 E-022，force 為 true 時略過檢查。\nprice 為十進位數字或字串，例如 \"45.5\"，小數位數不可超過幣別，否則回傳 E-033。currency 省略時使用基準幣別，代碼錯誤回傳 E-031。\nrefund 為 true 時是退款或調整，金額從同類別的統計扣除。refund_of 可以連結原帳單，需要相同的子類別與幣別，退款總額不可超過原金額，否則回傳 E-034",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立項目",
+                "parameters": [
+                    {
+                        "description": "建立項目",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateItemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/item/quick": {
+            "post": {
+                "description": "解析一行文字成項目，例如 \"昨天 午餐 排骨飯 120 #公司\"，commit 為 true 時直接建立，名稱須 2 到 32 字、備註最多 64 字，否則回傳格式錯誤",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "快速輸入項目",
+                "parameters": [
+                    {
+                        "description": "快速輸入",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.QuickItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.QuickItemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/item/{item_id}": {
+            "get": {
+                "description": "取得單一項目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得單一項目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "項目編號",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetItemResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "刪除項目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除項目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "項目編號",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteMainTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/items": {
+            "get": {
+                "description": "由日期取得預覽項目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "由日期取得預覽項目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "關鍵字",
+                        "name": "content",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "description": "登入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "login"
+                ],
+                "summary": "登入",
+                "parameters": [
+                    {
+                        "description": "登入",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout": {
+            "get": {
+                "description": "登出",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "登出",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/main": {
+            "get": {
+                "description": "取得全部主類別",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得全部主類別",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetMainTypeResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改主類別名稱",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改主類別名稱",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateMainTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateMainTypeResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立主類別",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立主類別",
+                "parameters": [
+                    {
+                        "description": "建立主類別",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateMainTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateMainTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/main/{main_id}": {
+            "delete": {
+                "description": "刪除主類別名稱",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除主類別名稱",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "主類別編號",
+                        "name": "main_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteMainTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rate": {
+            "post": {
+                "description": "建立一筆外幣對基準幣別的匯率，1 單位外幣等於 rate 單位基準幣別，同一天已有匯率時覆蓋。幣別與基準幣別相同時回傳 E-031",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立匯率",
+                "parameters": [
+                    {
+                        "description": "建立匯率",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRateResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rate/{rate_id}": {
+            "delete": {
+                "description": "刪除匯率",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除匯率",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "匯率編號",
+                        "name": "rate_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteRateResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rates": {
+            "get": {
+                "description": "取得目前基準幣別的匯率，未指定幣別時取得全部幣別，未指定日期時取得全部",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得匯率",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幣別",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetRatesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recurring": {
+            "get": {
+                "description": "取得定期帳單範本，materialized 為已建立帳單的最後一天",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得定期帳單",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetRecurringResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改定期帳單，欄位規則與建立定期帳單相同，已建立的帳單不變更",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改定期帳單",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateRecurringRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateRecurringResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立定期帳單範本，排程會在發生當天建立帳單，停機期間的帳單在重新啟動後補上。frequency 為 daily、weekly、monthly、yearly，預設 monthly，interval 為每幾個週期一次，預設 1。\n每月與每年預設是 start 的同一天，沒有這一天時為月底；nth 為 1 到 4 時是第 nth 個星期 weekday，-1 為最後一個，weekday 0 為星期日。end 與 count 為 0 或空白表示不限。\nprice 與 currency 的格式與建立項目相同，account_id 為 0 時使用預設帳戶。錯誤回傳 E-037",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立定期帳單",
+                "parameters": [
+                    {
+                        "description": "建立定期帳單",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRecurringRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRecurringResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recurring/occurrence": {
+            "put": {
+                "description": "略過或修改定期帳單某一天的名稱、金額與備註，空白表示不變更，skip 為 false 且沒有修改時取消。\ndate 必須是會發生的日期，已建立帳單的日期請直接修改帳單，錯誤回傳 E-037",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "略過或修改定期帳單的某一次",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateOccurrenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateOccurrenceResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recurring/upcoming": {
+            "get": {
+                "description": "取得今天到 end 之間還沒建立帳單的定期帳單，依日期排列，已套用略過與修改，金額為幣別的最小單位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得即將發生的定期帳單",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "結束日期，預設 30 天後",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetUpcomingResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recurring/{recurring_id}": {
+            "delete": {
+                "description": "刪除定期帳單與單次的例外，已建立的帳單保留",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除定期帳單",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "定期帳單編號",
+                        "name": "recurring_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteRecurringResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/restore": {
+            "post": {
+                "description": "還原 /api/backup 的備份檔，編號重新對應到目前的帳號，全部成功才寫入。mode 為 replace 時先清除目前的資料，merge 時沿用同名的類別，略過交易編號重複或內容與既有帳單相同的帳單，保留目前的基準幣別與提醒設定，備份的基準幣別不同時回傳 E-038。舊版備份的金額依幣別換算成最小單位。檢查碼不符回傳 E-026，版本不相容回傳 E-027",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "還原備份",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "備份檔",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "merge",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "default": "merge",
+                        "description": "還原方式",
+                        "name": "mode",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.RestoreResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rule": {
+            "get": {
+                "description": "取得名稱對應規則，依比對順序排列",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得規則",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetRulesResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改規則，欄位與建立規則相同",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改規則",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateRuleResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立規則，field 為比對欄位(name、remark、any)，match 為比對方式(contains、regex、fuzzy)，\n可再限制金額範圍(幣別的最小單位，0 表示不限)。符合時修改子類別、名稱並加上備註，sub_id 為 0 表示不變更。\n建立項目、快速輸入與匯入時依 priority 由小到大比對，套用第一個符合的規則，格式錯誤回傳 E-028",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立規則",
+                "parameters": [
+                    {
+                        "description": "建立規則",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateRuleResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rule/test": {
+            "post": {
+                "description": "以既有帳單測試規則，回傳符合的帳單與套用後的結果，不寫入資料。\nstart、end 預設為最近一年，list 最多 200 筆，total 為全部符合的筆數",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "測試規則",
+                "parameters": [
+                    {
+                        "description": "測試規則",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.TestRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.TestRuleResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rule/{rule_id}": {
+            "delete": {
+                "description": "刪除名稱對應規則",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除規則",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "規則編號",
+                        "name": "rule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteRuleResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/search/name": {
+            "get": {
+                "description": "模糊搜尋名稱，依序包含關鍵字每個字的名稱都會找到，可用拼音、注音或首字母搜尋 (例如 jcc、ㄐㄔㄔ)。\n由資料庫索引找出最多 1000 筆候選，全部依相關程度排序後再分頁；只有字形相似 (例如打錯字) 的帳單沒有 score，排在最後。\n候選超過 1000 筆時 truncated 為 true，請縮小日期範圍或使用更長的關鍵字",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "模糊搜尋名稱",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "關鍵字",
+                        "name": "content",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "頁數",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "每頁筆數",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/search/remake": {
+            "get": {
+                "description": "搜尋備註，可同時搜尋名稱。fuzzy 依序包含關鍵字每個字即符合，substring 為包含，exact 為完全相同，都先統一全形、繁簡與大小寫。\n最多 1000 筆候選全部依相關程度排序後再分頁，候選超過 1000 筆時 truncated 為 true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "搜尋備註",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "關鍵字",
+                        "name": "content",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "fuzzy",
+                            "substring",
+                            "exact"
+                        ],
+                        "type": "string",
+                        "default": "fuzzy",
+                        "description": "搜尋模式",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "同時搜尋名稱",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "頁數",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "每頁筆數",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spend/month/{count}": {
+            "get": {
+                "description": "取得前幾個月收支總和，外幣帳單依帳單日期的匯率換算成基準幣別，缺少匯率時回傳 E-032",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得前幾個月收支總和",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "月份數量(最多12)",
+                        "name": "count",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetSpendByMonthlyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub": {
+            "put": {
+                "description": "修改子類別名稱",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改子類別名稱",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateSubTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateSubTypeResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立子類別",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立子類別",
+                "parameters": [
+                    {
+                        "description": "建立子類別",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateSubTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateSubTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{main_id}": {
+            "get": {
+                "description": "取得全部子類別",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得全部子類別",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "主類別編號",
+                        "name": "main_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetSubTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{sub_id}": {
+            "delete": {
+                "description": "刪除子類別名稱",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除子類別名稱",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "子類別編號",
+                        "name": "sub_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteSubTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/suggest/category": {
+            "get": {
+                "description": "依過去的帳單預測名稱所屬的子類別，附上信心度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得類別建議",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "項目名稱",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "備註",
+                        "name": "remark",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetCategorySuggestionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/suggest/name": {
+            "get": {
+                "description": "依前綴取得常用的項目名稱，附上最後一次使用的子類別與金額",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得名稱建議",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "名稱前綴，可用拼音或注音",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetNameSuggestionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sum/main": {
+            "get": {
+                "description": "取得這個月的主類別總和，外幣帳單依帳單日期的匯率換算成基準幣別，缺少匯率時回傳 E-032",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得這個月的主類別總和",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetSumByMainTypeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfer": {
+            "put": {
+                "description": "修改轉帳帳戶、金額、日期與備註",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "update"
+                ],
+                "summary": "修改轉帳",
+                "parameters": [
+                    {
+                        "description": "修改",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.UpdateTransferResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "建立帳戶間轉帳，例如提款或繳信用卡費。轉帳只影響帳戶餘額，不計入收入與支出，轉出與轉入帳戶相同時回傳 E-030",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立轉帳",
+                "parameters": [
+                    {
+                        "description": "建立轉帳",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateTransferResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfer/{transfer_id}": {
+            "delete": {
+                "description": "刪除轉帳",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delete"
+                ],
+                "summary": "刪除轉帳",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "轉帳編號",
+                        "name": "transfer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.DeleteTransferResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transfers": {
+            "get": {
+                "description": "取得日期區間內的轉帳，未指定日期時取得全部",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "get"
+                ],
+                "summary": "取得轉帳",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "起始日期",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束日期",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.GetTransfersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user": {
+            "post": {
+                "description": "建立使用者",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "create"
+                ],
+                "summary": "建立使用者",
+                "parameters": [
+                    {
+                        "description": "建立使用者",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/bundle.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/bundle.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "archive.Archive": {
+            "type": "object",
+            "properties": {
+                "checksums": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "created": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Account": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "目前餘額",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opening": {
+                    "description": "期初餘額",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.AccountEntry": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "帳戶",
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "幣別與小數位數，price 除以 10 的 scale 次方為實際金額",
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "increase": {
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "main_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Highlight"
+                    }
+                },
+                "price": {
+                    "description": "幣別的最小單位",
+                    "type": "integer"
+                },
+                "refund": {
+                    "description": "退款或調整，金額從同類別扣除，refund_of 為原帳單，0 表示沒有",
+                    "type": "boolean"
+                },
+                "refund_of": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "remark_highlight": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Highlight"
+                    }
+                },
+                "scale": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "搜尋結果，沒有 score 表示沒有排序，0 也是有效的分數",
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "type": "integer"
+                },
+                "sub_name": {
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Alert": {
+            "type": "object",
+            "properties": {
+                "budget_id": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "bundle.AllType": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "subs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Sub"
+                    }
+                }
+            }
+        },
+        "bundle.Budget": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "類別名稱",
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "rollover": {
+                    "type": "boolean"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "carry": {
+                    "description": "前期結轉",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "description": "本期可用，預算加上結轉",
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "類別名稱",
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "projected": {
+                    "description": "依目前速度預估的期末支出",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "剩餘，超支時為負數",
+                    "type": "integer"
+                },
+                "rollover": {
+                    "type": "boolean"
+                },
+                "spent": {
+                    "description": "本期支出，退款已扣除",
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CategorySuggestion": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "description": "信心度 0 ~ 1",
+                    "type": "number"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateAccountRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1,
+                    "example": "現金"
+                },
+                "opening": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "cash",
+                        "bank",
+                        "credit_card",
+                        "e_wallet"
+                    ],
+                    "example": "cash"
+                }
+            }
+        },
+        "bundle.CreateAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.CreateBudgetRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "8000"
+                },
+                "main_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "week",
+                        "month",
+                        "year"
+                    ],
+                    "example": "month"
+                },
+                "rollover": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                }
+            }
+        },
+        "bundle.CreateBudgetResponse": {
+            "type": "object",
+            "properties": {
+                "budget_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.CreateItemRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "name",
+                "price",
+                "remark",
+                "sub_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "帳戶，0 表示預設帳戶",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "currency": {
+                    "description": "幣別，空白表示基準幣別",
+                    "type": "string",
+                    "maxLength": 3,
+                    "example": "TWD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "force": {
+                    "description": "忽略重複檢查",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2,
+                    "example": "name"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "45.5"
+                },
+                "refund": {
+                    "description": "退款或調整，金額從同類別扣除",
+                    "type": "boolean",
+                    "example": false
+                },
+                "refund_of": {
+                    "description": "原帳單，0 表示沒有，需要相同的子類別與幣別",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 0,
+                    "example": ""
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.CreateItemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "duplicates": {
+                    "description": "可能重複的帳單",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.PreviewItem"
+                    }
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "description": "套用的規則",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateMainTypeRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                }
+            }
+        },
+        "bundle.CreateMainTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.CreateRateRequest": {
+            "type": "object",
+            "required": [
+                "currency",
+                "date",
+                "rate"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 30.5
+                }
+            }
+        },
+        "bundle.CreateRateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "rate_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateRecurringRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "price",
+                "start",
+                "sub_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "count": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "currency": {
+                    "type": "string",
+                    "example": ""
+                },
+                "end": {
+                    "type": "string",
+                    "example": ""
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "interval": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1,
+                    "example": "房租"
+                },
+                "nth": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "price": {
+                    "type": "string",
+                    "example": "15000"
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "weekday": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.CreateRecurringResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "recurring_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateRuleRequest": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "enum": [
+                        "name",
+                        "remark",
+                        "any"
+                    ],
+                    "example": "name"
+                },
+                "keyword": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "7-ELEVEN"
+                },
+                "match": {
+                    "type": "string",
+                    "enum": [
+                        "contains",
+                        "regex",
+                        "fuzzy"
+                    ],
+                    "example": "contains"
+                },
+                "max_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "min_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "rename": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": ""
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.CreateRuleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateSubTypeRequest": {
+            "type": "object",
+            "required": [
+                "main_id",
+                "name"
+            ],
+            "properties": {
+                "increase": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "main_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2,
+                    "example": "name"
+                }
+            }
+        },
+        "bundle.CreateSubTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateTransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "date",
+                "from_id",
+                "to_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "1000"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "from_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "to_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "bundle.CreateTransferResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "description": "使用者密碼",
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3,
+                    "example": "password"
+                },
+                "token": {
+                    "description": "通行證",
+                    "type": "string",
+                    "example": "token"
+                },
+                "username": {
+                    "description": "使用者帳號",
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3,
+                    "example": "username"
+                }
+            }
+        },
+        "bundle.DeleteAccountResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteBudgetResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteMainTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteRateResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteRecurringResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteRuleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteSubTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DeleteTransferResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.DuplicateGroup": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.PreviewItem"
+                    }
+                }
+            }
+        },
+        "bundle.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.GetAccountItemsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.AccountEntry"
+                    }
+                },
+                "opening": {
+                    "description": "區間開始前的餘額",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.GetAccountsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Account"
+                    }
+                }
+            }
+        },
+        "bundle.GetAlertSettingResponse": {
+            "type": "object",
+            "properties": {
+                "bill_threshold": {
+                    "description": "基準幣別的最小單位，0 表示不提醒",
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "webhook": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.GetAlertsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Alert"
+                    }
+                },
+                "unread": {
+                    "description": "未讀數量",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.GetAllTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.AllType"
+                    }
+                }
+            }
+        },
+        "bundle.GetBudgetStatusResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.BudgetStatus"
+                    }
+                }
+            }
+        },
+        "bundle.GetBudgetsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Budget"
+                    }
+                }
+            }
+        },
+        "bundle.GetCategorySuggestionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.CategorySuggestion"
+                    }
+                }
+            }
+        },
+        "bundle.GetCurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "scale": {
+                    "description": "小數位數，統計與餘額都是基準幣別的最小單位",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.GetDuplicatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.DuplicateGroup"
+                    }
+                }
+            }
+        },
+        "bundle.GetItemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "item": {
+                    "$ref": "#/definitions/bundle.Item"
+                }
+            }
+        },
+        "bundle.GetItemsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.PreviewItem"
+                    }
+                },
+                "truncated": {
+                    "description": "搜尋的候選超過上限，只排序了相似度最高的部分",
+                    "type": "boolean"
+                }
+            }
+        },
+        "bundle.GetMainTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Main"
+                    }
+                }
+            }
+        },
+        "bundle.GetNameSuggestionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.NameSuggestion"
+                    }
+                }
+            }
+        },
+        "bundle.GetRatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Rate"
+                    }
+                }
+            }
+        },
+        "bundle.GetRecurringResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Recurring"
+                    }
+                }
+            }
+        },
+        "bundle.GetRulesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Rule"
+                    }
+                }
+            }
+        },
+        "bundle.GetSpendByMonthlyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Monthly"
+                    }
+                }
+            }
+        },
+        "bundle.GetSubTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Sub"
+                    }
+                }
+            }
+        },
+        "bundle.GetSumByMainTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.MainSumMonthly"
+                    }
+                }
+            }
+        },
+        "bundle.GetTransfersResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Transfer"
+                    }
+                }
+            }
+        },
+        "bundle.GetUpcomingResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Occurrence"
+                    }
+                }
+            }
+        },
+        "bundle.Highlight": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.ImportRatesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "新增或更新的筆數",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "無法匯入的原因",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "committed": {
+                    "description": "已寫入",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "有錯誤的筆數",
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.ImportRow"
+                    }
+                },
+                "skipped": {
+                    "description": "與現有帳單重複而略過的筆數",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.ImportRow": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "空白表示基準幣別",
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "無法匯入的原因",
+                    "type": "string"
+                },
+                "fit_id": {
+                    "description": "銀行交易編號，重複匯入時略過",
+                    "type": "string"
+                },
+                "increase": {
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "來源行號",
+                    "type": "integer"
+                },
+                "main_id": {
+                    "description": "0 表示需要建立",
+                    "type": "integer"
+                },
+                "main_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "description": "已經匯入過或與現有帳單重複",
+                    "type": "boolean"
+                },
+                "sub_id": {
+                    "description": "0 表示需要建立",
+                    "type": "integer"
+                },
+                "sub_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.Item": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "幣別的最小單位",
+                    "type": "integer"
+                },
+                "refund": {
+                    "type": "boolean"
+                },
+                "refund_of": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "scale": {
+                    "description": "幣別的小數位數",
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "description": "使用者密碼",
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3,
+                    "example": "password"
+                },
+                "token": {
+                    "description": "通行證",
+                    "type": "string",
+                    "example": "token"
+                },
+                "username": {
+                    "description": "使用者帳號",
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3,
+                    "example": "username"
+                }
+            }
+        },
+        "bundle.Main": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.MainSumMonthly": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "sum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Monthly": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "sum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.NameSuggestion": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "使用次數",
+                    "type": "integer"
+                },
+                "last_date": {
+                    "description": "最後使用日期",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "最後一次使用的金額",
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "description": "最後一次使用的子類別",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Occurrence": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "edited": {
+                    "description": "有修改該次的內容",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "recurring_id": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "scale": {
+                    "type": "integer"
+                },
+                "skip": {
+                    "type": "boolean"
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.PreviewItem": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "帳戶",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "幣別與小數位數，price 除以 10 的 scale 次方為實際金額",
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "increase": {
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer"
+                },
+                "main_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Highlight"
+                    }
+                },
+                "price": {
+                    "description": "幣別的最小單位",
+                    "type": "integer"
+                },
+                "refund": {
+                    "description": "退款或調整，金額從同類別扣除，refund_of 為原帳單，0 表示沒有",
+                    "type": "boolean"
+                },
+                "refund_of": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "remark_highlight": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.Highlight"
+                    }
+                },
+                "scale": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "搜尋結果，沒有 score 表示沒有排序，0 也是有效的分數",
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "type": "integer"
+                },
+                "sub_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.QuickItemRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "commit": {
+                    "description": "是否直接建立",
+                    "type": "boolean",
+                    "example": false
+                },
+                "force": {
+                    "description": "忽略重複檢查",
+                    "type": "boolean",
+                    "example": false
+                },
+                "text": {
+                    "description": "例如 \"昨天 午餐 排骨飯 120 #公司 // 備註\"",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "昨天 午餐 排骨飯 120 #公司"
+                }
+            }
+        },
+        "bundle.QuickItemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "committed": {
+                    "description": "已建立",
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "description": "可能重複的帳單",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.PreviewItem"
+                    }
+                },
+                "item": {
+                    "description": "解析結果",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/bundle.CreateItemRequest"
+                        }
+                    ]
+                },
+                "rule_id": {
+                    "description": "套用的規則",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Rate": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "基準幣別",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "bundle.ReadAlertsRequest": {
+            "type": "object",
+            "properties": {
+                "alert_ids": {
+                    "description": "空白表示全部",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "bundle.ReadAlertsResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.Recurring": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "count": {
+                    "description": "0 表示不限次數",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "end": {
+                    "description": "空白表示沒有結束日期",
+                    "type": "string"
+                },
+                "frequency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "每幾個週期一次",
+                    "type": "integer"
+                },
+                "materialized": {
+                    "description": "已建立帳單到這一天，空白表示尚未建立",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nth": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "sub_id": {
+                    "type": "integer"
+                },
+                "weekday": {
+                    "description": "0 為星期日",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.RestoreResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "integer"
+                },
+                "bills": {
+                    "type": "integer"
+                },
+                "budgets": {
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "main_types": {
+                    "type": "integer"
+                },
+                "rates": {
+                    "type": "integer"
+                },
+                "recurring": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "integer"
+                },
+                "sub_types": {
+                    "type": "integer"
+                },
+                "transfers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Rule": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "比對欄位，預設名稱",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "keyword": {
+                    "type": "string"
+                },
+                "match": {
+                    "description": "比對方式，預設包含",
+                    "type": "string"
+                },
+                "max_price": {
+                    "description": "幣別的最小單位，0 表示不限",
+                    "type": "integer"
+                },
+                "min_price": {
+                    "description": "幣別的最小單位，0 表示不限",
+                    "type": "integer"
+                },
+                "priority": {
+                    "description": "數字小的先比對",
+                    "type": "integer"
+                },
+                "remark": {
+                    "description": "附加的備註",
+                    "type": "string"
+                },
+                "rename": {
+                    "description": "空白表示不變更",
+                    "type": "string"
+                },
+                "sub_id": {
+                    "description": "0 表示不變更",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.RuleTestResult": {
+            "type": "object",
+            "properties": {
+                "item": {
+                    "description": "原本的帳單",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/bundle.PreviewItem"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "sub_id": {
+                    "description": "套用後",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.StatementImportResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "committed": {
+                    "description": "已寫入",
+                    "type": "boolean"
+                },
+                "created": {
+                    "description": "新增筆數",
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.ImportRow"
+                    }
+                },
+                "skipped": {
+                    "description": "已匯入過而略過的筆數",
+                    "type": "integer"
+                },
+                "unmatched": {
+                    "description": "找不到類別或格式錯誤的筆數",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Sub": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "increase": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.TestRuleRequest": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "field": {
+                    "type": "string",
+                    "enum": [
+                        "name",
+                        "remark",
+                        "any"
+                    ],
+                    "example": "name"
+                },
+                "keyword": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "7-ELEVEN"
+                },
+                "match": {
+                    "type": "string",
+                    "enum": [
+                        "contains",
+                        "regex",
+                        "fuzzy"
+                    ],
+                    "example": "contains"
+                },
+                "max_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "min_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "rename": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": ""
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.TestRuleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bundle.RuleTestResult"
+                    }
+                },
+                "total": {
+                    "description": "符合的筆數，list 最多回傳 200 筆",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.Transfer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "from_id": {
+                    "description": "轉出帳戶",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "to_id": {
+                    "description": "轉入帳戶",
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.UpdateAccountRequest": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "name"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1,
+                    "example": "現金"
+                },
+                "opening": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "cash",
+                        "bank",
+                        "credit_card",
+                        "e_wallet"
+                    ],
+                    "example": "cash"
+                }
+            }
+        },
+        "bundle.UpdateAccountResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateAlertSettingRequest": {
+            "type": "object",
+            "properties": {
+                "bill_threshold": {
+                    "type": "string",
+                    "example": "3000"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "me@example.com"
+                },
+                "webhook": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "https://example.com/hook"
+                }
+            }
+        },
+        "bundle.UpdateAlertSettingResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateBudgetRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "budget_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "8000"
+                },
+                "budget_id": {
+                    "type": "integer"
+                },
+                "main_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "week",
+                        "month",
+                        "year"
+                    ],
+                    "example": "month"
+                },
+                "rollover": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                }
+            }
+        },
+        "bundle.UpdateBudgetResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateCurrencyRequest": {
+            "type": "object",
+            "required": [
+                "currency"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "TWD"
+                }
+            }
+        },
+        "bundle.UpdateCurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateItemRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "item_id",
+                "name",
+                "price",
+                "remark",
+                "sub_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "帳戶，0 表示不變更",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "currency": {
+                    "description": "幣別，空白表示不變更",
+                    "type": "string",
+                    "maxLength": 3,
+                    "example": ""
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2,
+                    "example": "name"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "45.5"
+                },
+                "refund": {
+                    "description": "退款或調整與原帳單",
+                    "type": "boolean",
+                    "example": false
+                },
+                "refund_of": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 0,
+                    "example": ""
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.UpdateItemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateMainTypeRequest": {
+            "type": "object",
+            "required": [
+                "main_id",
+                "name"
+            ],
+            "properties": {
+                "main_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                }
+            }
+        },
+        "bundle.UpdateMainTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateOccurrenceRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "recurring_id"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": ""
+                },
+                "price": {
+                    "type": "string",
+                    "example": ""
+                },
+                "recurring_id": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "skip": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "bundle.UpdateOccurrenceResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateRecurringRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "price",
+                "recurring_id",
+                "start",
+                "sub_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "count": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "currency": {
+                    "type": "string",
+                    "example": ""
+                },
+                "end": {
+                    "type": "string",
+                    "example": ""
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "interval": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1,
+                    "example": "房租"
+                },
+                "nth": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "price": {
+                    "type": "string",
+                    "example": "15000"
+                },
+                "recurring_id": {
+                    "type": "integer"
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "weekday": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.UpdateRecurringResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateRuleRequest": {
+            "type": "object",
+            "required": [
+                "rule_id"
+            ],
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "enum": [
+                        "name",
+                        "remark",
+                        "any"
+                    ],
+                    "example": "name"
+                },
+                "keyword": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "7-ELEVEN"
+                },
+                "match": {
+                    "type": "string",
+                    "enum": [
+                        "contains",
+                        "regex",
+                        "fuzzy"
+                    ],
+                    "example": "contains"
+                },
+                "max_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "min_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "rename": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": ""
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "bundle.UpdateRuleResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateSubTypeRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "sub_id"
+            ],
+            "properties": {
+                "increase": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                },
+                "sub_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.UpdateSubTypeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        },
+        "bundle.UpdateTransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "date",
+                "from_id",
+                "to_id",
+                "transfer_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "1000"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2006-01-02"
+                },
+                "from_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": ""
+                },
+                "to_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "transfer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "bundle.UpdateTransferResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "錯誤代號",
+                    "type": "string"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "GoDaily",
+	Description:      "記帳 Api",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
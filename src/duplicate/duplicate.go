@@ -0,0 +1,102 @@
+package duplicate
+
+import (
+	"me.daily/src/bundle"
+	"me.daily/src/fuzzy"
+	"me.daily/src/transformer"
+)
+
+// 相似的最低分數，避免只有一個字相同就視為相似
+const similarScore = 20
+
+// 名稱是否相似，任一方可以模糊搜尋到另一方且分數足夠即視為相似
+func Similar(a, b string) bool {
+	t := transformer.NewChineseFold()
+	if m, ok := fuzzy.FuzzyMatch(a, b, t); ok && m.Score >= similarScore {
+		return true
+	}
+
+	m, ok := fuzzy.FuzzyMatch(b, a, t)
+	return ok && m.Score >= similarScore
+}
+
+// 找出與名稱相似的帳單，候選帳單須已符合相同子類別、金額與日期
+func Filter(name string, candidates []bundle.PreviewItem) []bundle.PreviewItem {
+	list := make([]bundle.PreviewItem, 0)
+	for _, c := range candidates {
+		if Similar(name, c.Name) {
+			list = append(list, c)
+		}
+	}
+
+	return list
+}
+
+// 依子類別、金額、日期分組，再以名稱相似度分群，只回傳兩筆以上的群組
+func Group(items []bundle.PreviewItem) [][]bundle.PreviewItem {
+	type key struct {
+		subId int
+		price int
+		date  string
+	}
+
+	keys := make([]key, 0)
+	buckets := make(map[key][]bundle.PreviewItem)
+	for _, item := range items {
+		k := key{item.SubId, item.Price, item.Date}
+		if _, ok := buckets[k]; !ok {
+			keys = append(keys, k)
+		}
+		buckets[k] = append(buckets[k], item)
+	}
+
+	groups := make([][]bundle.PreviewItem, 0)
+	for _, k := range keys {
+		for _, g := range cluster(buckets[k]) {
+			if len(g) > 1 {
+				groups = append(groups, g)
+			}
+		}
+	}
+
+	return groups
+}
+
+// 相似的名稱分在同一群 (union-find)
+func cluster(items []bundle.PreviewItem) [][]bundle.PreviewItem {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if Similar(items[i].Name, items[j].Name) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	groups := make([][]bundle.PreviewItem, 0)
+	for i, item := range items {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], item)
+	}
+
+	return groups
+}
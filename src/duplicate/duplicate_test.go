@@ -0,0 +1,53 @@
+package duplicate
+
+import (
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+func TestSimilar(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"排骨飯", "排骨飯", true},
+		{"排骨便當", "排骨", true},
+		{"計程車", "计程车", true},
+		{"排骨飯", "雞腿飯", false},
+		{"排骨飯", "排骨便當", false},
+		{"排骨便當", "排骨飯", false},
+		{"排骨便當", "排骨當", true},
+		{"奶茶", "茶", false},
+	}
+
+	for _, c := range cases {
+		if got := Similar(c.a, c.b); got != c.want {
+			t.Errorf("Similar(%q, %q) = %v", c.a, c.b, got)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	items := []bundle.PreviewItem{
+		{Id: 1, SubId: 3, Price: 120, Date: "2023-04-11", Name: "排骨飯"},
+		{Id: 2, SubId: 3, Price: 120, Date: "2023-04-11", Name: "排骨"},
+		{Id: 3, SubId: 3, Price: 120, Date: "2023-04-11", Name: "雞腿飯"},
+		{Id: 4, SubId: 3, Price: 100, Date: "2023-04-11", Name: "排骨飯"},
+		{Id: 5, SubId: 5, Price: 20, Date: "2023-04-12", Name: "捷運"},
+		{Id: 6, SubId: 5, Price: 20, Date: "2023-04-12", Name: "捷運"},
+	}
+
+	groups := Group(items)
+	if len(groups) != 2 {
+		t.Fatalf("unexpected %v", groups)
+	}
+
+	if len(groups[0]) != 2 || groups[0][0].Id != 1 || groups[0][1].Id != 2 {
+		t.Fatalf("unexpected first group %v", groups[0])
+	}
+
+	if len(groups[1]) != 2 || groups[1][0].Id != 5 {
+		t.Fatalf("unexpected second group %v", groups[1])
+	}
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"me.daily/src/bundle"
+)
+
+// Excel 依 BOM 判斷 UTF-8，沒有時中文會變成亂碼
+var utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCsvWriter(w io.Writer, bom bool) (*csvWriter, error) {
+	if bom {
+		if _, err := w.Write(utf8Bom); err != nil {
+			return nil, err
+		}
+	}
+
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (c *csvWriter) Write(item bundle.PreviewItem) error {
+	return c.w.Write(record(item))
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"errors"
+	"io"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 匯出格式
+const (
+	FormatCsv  = "csv"
+	FormatJson = "json"
+	FormatXlsx = "xlsx"
+)
+
+var ErrFormat = errors.New("unsupported format") // 不支援的格式
+
+// 欄位標題，新欄位加在最後，不影響既有的欄位位置
+var header = []string{"日期", "主類別", "子類別", "名稱", "收支", "金額", "備註", "幣別", "退款"}
+
+// 逐筆寫入帳單，Close 寫入結尾
+type Writer interface {
+	Write(item bundle.PreviewItem) error
+	Close() error
+}
+
+// 建立匯出格式的 Writer，bom 只對 CSV 有效
+func NewWriter(w io.Writer, format string, bom bool) (Writer, error) {
+	switch format {
+	case FormatCsv:
+		return newCsvWriter(w, bom)
+	case FormatJson:
+		return newJsonWriter(w), nil
+	case FormatXlsx:
+		return newXlsxWriter(w)
+	}
+
+	return nil, ErrFormat
+}
+
+// 檔案類型
+func ContentType(format string) string {
+	switch format {
+	case FormatCsv:
+		return "text/csv; charset=utf-8"
+	case FormatJson:
+		return "application/json; charset=utf-8"
+	case FormatXlsx:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "application/octet-stream"
+}
+
+// 收支以正負號表示，退款與類別的方向相反
+func sign(increase int, refund bool) string {
+	if (increase > 0) != refund {
+		return "+"
+	}
+	return "-"
+}
+
+// 退款標記
+func refundMark(refund bool) string {
+	if refund {
+		return "是"
+	}
+	return ""
+}
+
+// 一筆帳單的欄位，順序與 header 相同，金額依幣別的小數位數輸出，例如 "45.50"
+func record(item bundle.PreviewItem) []string {
+	return []string{
+		item.Date,
+		item.MainName,
+		item.SubName,
+		item.Name,
+		sign(item.Increase, item.Refund),
+		currency.Format(item.Price, item.Currency),
+		item.Remark,
+		item.Currency,
+		refundMark(item.Refund),
+	}
+}
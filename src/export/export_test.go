@@ -0,0 +1,142 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+var testItems = []bundle.PreviewItem{
+	{Id: 1, MainName: "餐費", SubName: "午餐", Name: "排骨飯, 大", Increase: -1, Price: 120, Remark: "公司", Date: "2022-10-01", Currency: "TWD"},
+	{Id: 2, MainName: "收入", SubName: "薪水", Name: "<十月>", Increase: 1, Price: 50000, Date: "2022-10-05", Currency: "TWD"},
+	{Id: 3, MainName: "餐費", SubName: "晚餐", Name: "Burger", Increase: -1, Price: 4550, Date: "2022-10-06", Currency: "USD", Scale: 2},
+	{Id: 4, MainName: "餐費", SubName: "午餐", Name: "排骨飯退款", Increase: -1, Price: 20, Date: "2022-10-07", Currency: "TWD", Refund: true, RefundOf: 1},
+}
+
+func export(t *testing.T, format string, bom bool, items []bundle.PreviewItem) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range items {
+		if err := w.Write(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCsv(t *testing.T) {
+	data := export(t, FormatCsv, true, testItems)
+	if !bytes.HasPrefix(data, utf8Bom) {
+		t.Fatal("missing BOM")
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data[len(utf8Bom):])).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		header,
+		{"2022-10-01", "餐費", "午餐", "排骨飯, 大", "-", "120", "公司", "TWD", ""},
+		{"2022-10-05", "收入", "薪水", "<十月>", "+", "50000", "", "TWD", ""},
+		{"2022-10-06", "餐費", "晚餐", "Burger", "-", "45.50", "", "USD", ""},
+		{"2022-10-07", "餐費", "午餐", "排骨飯退款", "+", "20", "", "TWD", "是"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("unexpected records %v", records)
+	}
+
+	if bytes.HasPrefix(export(t, FormatCsv, false, nil), utf8Bom) {
+		t.Fatal("unexpected BOM")
+	}
+}
+
+func TestJson(t *testing.T) {
+	for _, items := range [][]bundle.PreviewItem{nil, testItems} {
+		var list []bundle.PreviewItem
+		if err := json.Unmarshal(export(t, FormatJson, false, items), &list); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(list) != len(items) || (len(items) > 0 && !reflect.DeepEqual(list, items)) {
+			t.Fatalf("unexpected list %v", list)
+		}
+	}
+}
+
+func TestXlsx(t *testing.T) {
+	data := export(t, FormatXlsx, false, testItems)
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+
+	for _, p := range xlsxParts {
+		if _, ok := files[p.name]; !ok {
+			t.Fatalf("missing %s", p.name)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sheet.Rows) != len(testItems)+1 {
+		t.Fatalf("unexpected rows %d", len(sheet.Rows))
+	}
+
+	price := sheet.Rows[2].Cells[priceColumn]
+	if price.Type != "n" || price.Value != "50000" {
+		t.Fatalf("unexpected price %+v", price)
+	}
+
+	if name := sheet.Rows[2].Cells[3].Inline; name != "<十月>" {
+		t.Fatalf("unexpected name %q", name)
+	}
+
+	if price := sheet.Rows[3].Cells[priceColumn]; price.Value != "45.50" {
+		t.Fatalf("unexpected price %+v", price)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if _, err := NewWriter(io.Discard, "pdf", false); err != ErrFormat {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"me.daily/src/bundle"
+)
+
+// 輸出 JSON 陣列，一筆一行
+type jsonWriter struct {
+	w     *bufio.Writer
+	e     *json.Encoder
+	count int
+}
+
+func newJsonWriter(w io.Writer) *jsonWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonWriter{w: bw, e: json.NewEncoder(bw)}
+}
+
+func (j *jsonWriter) Write(item bundle.PreviewItem) error {
+	sep := ","
+	if j.count == 0 {
+		sep = "["
+	}
+	j.count++
+
+	if _, err := j.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	// Encode 會在結尾加上換行
+	return j.e.Encode(item)
+}
+
+func (j *jsonWriter) Close() error {
+	end := "]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+
+	if _, err := j.w.WriteString(end); err != nil {
+		return err
+	}
+
+	return j.w.Flush()
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+
+	"me.daily/src/bundle"
+)
+
+// 最小的 XLSX 結構，只有一個工作表，字串直接寫在儲存格內 (inlineStr)
+//
+// 工作表最後寫入，邊查詢邊輸出，不需要把整份資料留在記憶體
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="GoDaily" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// 金額欄位，以數字儲存方便加總
+const priceColumn = 5
+
+type xlsxWriter struct {
+	z *zip.Writer
+	w *bufio.Writer
+}
+
+func newXlsxWriter(w io.Writer) (*xlsxWriter, error) {
+	z := zip.NewWriter(w)
+
+	for _, p := range xlsxParts {
+		f, err := z.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	x := &xlsxWriter{z: z, w: bufio.NewWriter(f)}
+	x.w.WriteString(xml.Header)
+	x.w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err := x.row(header, -1); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(item bundle.PreviewItem) error {
+	return x.row(record(item), priceColumn)
+}
+
+func (x *xlsxWriter) Close() error {
+	x.w.WriteString(`</sheetData></worksheet>`)
+	if err := x.w.Flush(); err != nil {
+		return err
+	}
+
+	return x.z.Close()
+}
+
+// 寫入一列，number 為數字欄位，-1 表示全部是字串
+//
+// bufio.Writer 發生錯誤後每次寫入都會回傳同一個錯誤，只需要檢查最後一次
+func (x *xlsxWriter) row(cells []string, number int) error {
+	x.w.WriteString("<row>")
+	for i, v := range cells {
+		if i == number {
+			x.w.WriteString(`<c t="n"><v>`)
+			x.w.WriteString(v)
+			x.w.WriteString(`</v></c>`)
+			continue
+		}
+
+		x.w.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(x.w, []byte(v))
+		x.w.WriteString(`</t></is></c>`)
+	}
+	_, err := x.w.WriteString("</row>")
+	return err
+}
@@ -0,0 +1,196 @@
+package fuzzy
+
+import (
+	"golang.org/x/text/transform"
+	"me.daily/src/transformer"
+)
+
+// 分數權重
+const (
+	scoreMatch      = 10 // 每個符合的字
+	scoreContiguous = 10 // 連續符合
+	scorePrefix     = 5  // 開頭符合
+)
+
+// 搜尋結果
+type Match struct {
+	Score     int   // 相關程度，越大越相關
+	Positions []int // 符合的字元位置 (rune)，以轉換後的來源計算
+}
+
+// 將符合位置合併成區間 [start, end)
+func (m Match) Ranges() [][2]int {
+	ranges := make([][2]int, 0)
+	for _, p := range m.Positions {
+		l := len(ranges)
+		if l > 0 && ranges[l-1][1] == p {
+			ranges[l-1][1] = p + 1
+		} else {
+			ranges = append(ranges, [2]int{p, p + 1})
+		}
+	}
+
+	return ranges
+}
+
+// 模糊搜尋並計算分數
+//
+// 從每個可能的起點嘗試比對，取分數最高的結果
+func FuzzyMatch(source, target string, t transform.Transformer) (Match, bool) {
+	s, p := transformRunes(source, target, t)
+	if len(p) > len(s) {
+		return Match{}, false
+	}
+
+	if len(p) == 0 {
+		return Match{Score: score(s, p, nil)}, true
+	}
+
+	var best Match
+	found := false
+	for start := range s {
+		if s[start] != p[0] {
+			continue
+		}
+
+		positions := greedy(s, p, start)
+		if positions == nil {
+			// 後面的起點只會更少字可以比對
+			break
+		}
+
+		m := Match{Score: score(s, p, positions), Positions: positions}
+		if !found || m.Score > best.Score {
+			best = m
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// 子字串搜尋並計算分數
+func ContainsMatch(source, target string, t transform.Transformer) (Match, bool) {
+	s, p := transformRunes(source, target, t)
+
+	for start := 0; start+len(p) <= len(s); start++ {
+		if equalRunes(s[start:start+len(p)], p) {
+			positions := make([]int, len(p))
+			for i := range positions {
+				positions[i] = start + i
+			}
+
+			return Match{Score: score(s, p, positions), Positions: positions}, true
+		}
+	}
+
+	return Match{}, false
+}
+
+// 完全相同並計算分數
+func EqualMatch(source, target string, t transform.Transformer) (Match, bool) {
+	s, p := transformRunes(source, target, t)
+	if !equalRunes(s, p) {
+		return Match{}, false
+	}
+
+	positions := make([]int, len(p))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	return Match{Score: score(s, p, positions), Positions: positions}, true
+}
+
+func transformRunes(source, target string, t transform.Transformer) ([]rune, []rune) {
+	if t == nil {
+		t = transformer.Nop
+	}
+
+	return []rune(stringTransform(source, t)), []rune(stringTransform(target, t))
+}
+
+// 由起點依序比對，失敗回傳 nil
+func greedy(s, p []rune, start int) []int {
+	positions := make([]int, 0, len(p))
+	index := start
+	for _, r := range p {
+		for index < len(s) && s[index] != r {
+			index++
+		}
+
+		if index == len(s) {
+			return nil
+		}
+
+		positions = append(positions, index)
+		index++
+	}
+
+	return positions
+}
+
+// 計算分數：符合字數、連續、開頭，扣除編輯距離
+func score(s, p []rune, positions []int) int {
+	score := len(positions) * scoreMatch
+
+	for i := 1; i < len(positions); i++ {
+		if positions[i] == positions[i-1]+1 {
+			score += scoreContiguous
+		}
+	}
+
+	if len(positions) > 0 && positions[0] == 0 {
+		score += scorePrefix
+	}
+
+	return score - levenshtein(s, p)
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 編輯距離
+//
+//	https://en.wikipedia.org/wiki/Levenshtein_distance
+func levenshtein(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			tmp := row[j]
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			row[j] = minInt(minInt(row[j]+1, row[j-1]+1), prev+cost)
+			prev = tmp
+		}
+	}
+
+	return row[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	m, ok := FuzzyMatch("早午餐", "早餐", nil)
+	if !ok {
+		t.Fatal("expected match")
+	}
+
+	if !reflect.DeepEqual(m.Positions, []int{0, 2}) {
+		t.Fatalf("unexpected positions %v", m.Positions)
+	}
+
+	if _, ok := FuzzyMatch("午餐", "早餐", nil); ok {
+		t.Fatal("unexpected match")
+	}
+}
+
+func TestFuzzyMatchAgreesWithFuzzySearch(t *testing.T) {
+	cases := [][2]string{
+		{"早餐", "早餐"},
+		{"早午餐", "早餐"},
+		{"計程車", "計車"},
+		{"午餐", "早餐"},
+		{"KTV", "ktv"},
+		{"", ""},
+		{"abc", ""},
+		{"ab", "abc"},
+	}
+
+	for _, c := range cases {
+		_, ok := FuzzyMatch(c[0], c[1], nil)
+		if ok != FuzzySearch(c[0], c[1], nil) {
+			t.Errorf("FuzzyMatch(%q, %q) = %v", c[0], c[1], ok)
+		}
+	}
+}
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	exact, _ := FuzzyMatch("早餐", "早餐", nil)
+	prefix, _ := FuzzyMatch("早餐店", "早餐", nil)
+	contiguous, _ := FuzzyMatch("吃早餐", "早餐", nil)
+	scattered, _ := FuzzyMatch("早午餐", "早餐", nil)
+
+	if !(exact.Score > prefix.Score && prefix.Score > contiguous.Score && contiguous.Score > scattered.Score) {
+		t.Fatalf("unexpected ranking %d %d %d %d", exact.Score, prefix.Score, contiguous.Score, scattered.Score)
+	}
+}
+
+func TestFuzzyMatchBestStart(t *testing.T) {
+	// 第一個 "早" 只能分散比對，第二個可以連續
+	m, ok := FuzzyMatch("早上吃早餐", "早餐", nil)
+	if !ok {
+		t.Fatal("expected match")
+	}
+
+	if !reflect.DeepEqual(m.Ranges(), [][2]int{{3, 5}}) {
+		t.Fatalf("unexpected ranges %v", m.Ranges())
+	}
+}
+
+func TestContainsMatch(t *testing.T) {
+	m, ok := ContainsMatch("公司午餐聚會", "午餐", nil)
+	if !ok || !reflect.DeepEqual(m.Ranges(), [][2]int{{2, 4}}) {
+		t.Fatalf("unexpected result %v %v", ok, m)
+	}
+
+	if _, ok := ContainsMatch("早午餐", "早餐", nil); ok {
+		t.Fatal("unexpected match")
+	}
+}
+
+func TestEqualMatch(t *testing.T) {
+	if _, ok := EqualMatch("午餐", "午餐", nil); !ok {
+		t.Fatal("expected match")
+	}
+
+	if _, ok := EqualMatch("午餐聚會", "午餐", nil); ok {
+		t.Fatal("unexpected match")
+	}
+}
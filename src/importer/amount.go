@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"strings"
+
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+	"me.daily/src/transformer"
+)
+
+// 去除千分位、貨幣符號與空白
+var amountCleaner = strings.NewReplacer(",", "", " ", "", "$", "", "NT", "", "元", "")
+
+// 解析金額並換算成幣別的最小單位，支援 "-1,200"、"(1200)"、"NT$ 1200.00"
+//
+// 小數位數超過幣別時視為錯誤
+func parseAmount(s, code string) (int, error) {
+	s, _, err := transform.String(transformer.Narrow, s)
+	if err != nil {
+		return 0, ErrAmount
+	}
+	s = amountCleaner.Replace(s)
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	n, err := currency.ParseAmount(s, code)
+	if err != nil {
+		return 0, ErrAmount
+	}
+
+	if negative {
+		n = -n
+	}
+
+	return n, nil
+}
+
+// 依正負號慣例轉成金額與收支，收支 1 為收入、-1 為支出、0 為不限
+func direction(amount int, sign string) (int, int) {
+	price := amount
+	if price < 0 {
+		price = -price
+	}
+
+	if amount == 0 {
+		return price, 0
+	}
+
+	switch sign {
+	case bundle.SignAbsolute:
+		return price, 0
+	case bundle.SignExpensePositive:
+		if amount > 0 {
+			return price, -1
+		}
+		return price, 1
+	default:
+		if amount < 0 {
+			return price, -1
+		}
+		return price, 1
+	}
+}
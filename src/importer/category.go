@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"strings"
+
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/transformer"
+)
+
+// 子類別，Id 為 0 表示匯入時建立
+type category struct {
+	mainId   int
+	mainName string
+	mainKey  string
+	subId    int
+	subName  string
+	subKey   string
+	increase int
+}
+
+// 依名稱比對類別，全形、繁簡、大小寫視為相同
+type categories struct {
+	list   []*category
+	create bool
+}
+
+func newCategories(types []bundle.AllType, create bool) *categories {
+	c := &categories{create: create}
+	for _, t := range types {
+		for _, sub := range t.Subs {
+			increase := -1
+			if sub.Increase {
+				increase = 1
+			}
+
+			c.list = append(c.list, &category{
+				mainId:   t.Id,
+				mainName: t.Name,
+				mainKey:  fold(t.Name),
+				subId:    sub.Id,
+				subName:  sub.Name,
+				subKey:   fold(sub.Name),
+				increase: increase,
+			})
+		}
+	}
+	return c
+}
+
+// 找出 row 的類別，increase 為 0 表示收支不限
+//
+// 允許建立時，找不到的類別會加入清單，之後相同名稱的資料使用同一個
+func (c *categories) resolve(row *bundle.ImportRow, increase int) error {
+	mainKey := fold(row.MainName)
+	subKey := fold(row.SubName)
+	if len(subKey) == 0 {
+		return ErrCategory
+	}
+
+	var found *category
+	for _, cat := range c.list {
+		if cat.subKey != subKey || (len(mainKey) > 0 && cat.mainKey != mainKey) {
+			continue
+		}
+		if found != nil {
+			return ErrAmbiguous
+		}
+		found = cat
+	}
+
+	if found == nil {
+		if !c.create || len(mainKey) == 0 {
+			return ErrCategory
+		}
+
+		found = &category{
+			mainName: strings.TrimSpace(row.MainName),
+			mainKey:  mainKey,
+			subName:  strings.TrimSpace(row.SubName),
+			subKey:   subKey,
+			increase: increase,
+		}
+		if found.increase == 0 {
+			found.increase = -1
+		}
+
+		// 使用既有或先前建立的主類別
+		for _, cat := range c.list {
+			if cat.mainKey == mainKey {
+				found.mainId = cat.mainId
+				found.mainName = cat.mainName
+				break
+			}
+		}
+
+		c.list = append(c.list, found)
+	}
+
+	return found.apply(row, increase)
+}
+
+// 使用指定的子類別
+func (c *categories) assign(row *bundle.ImportRow, subId int, increase int) error {
+	for _, cat := range c.list {
+		if cat.subId == subId {
+			return cat.apply(row, increase)
+		}
+	}
+
+	return ErrCategory
+}
+
+// 寫入類別，並確認收支與正負號相同
+func (cat *category) apply(row *bundle.ImportRow, increase int) error {
+	row.MainId = cat.mainId
+	row.MainName = cat.mainName
+	row.SubId = cat.subId
+	row.SubName = cat.subName
+	row.Increase = cat.increase
+
+	if increase != 0 && increase != cat.increase {
+		return ErrSign
+	}
+
+	return nil
+}
+
+// 名稱統一成比對用的格式
+func fold(s string) string {
+	s = strings.TrimSpace(s)
+	f, _, err := transform.String(transformer.NewChineseFold(), s)
+	if err != nil {
+		return s
+	}
+	return f
+}
@@ -0,0 +1,251 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+	"me.daily/src/rule"
+)
+
+var (
+	ErrEncoding   = errors.New("unsupported encoding")         // 不支援的編碼
+	ErrDelimiter  = errors.New("invalid delimiter")            // 分隔符號必須是一個字元
+	ErrColumn     = errors.New("column not found")             // 找不到欄位
+	ErrTooMany    = errors.New("too many rows")                // 超過筆數上限
+	ErrEmpty      = errors.New("no rows")                      // 沒有資料
+	ErrDate       = errors.New("invalid date")                 // 日期格式錯誤
+	ErrAmount     = errors.New("invalid amount")               // 金額格式錯誤
+	ErrCategory   = errors.New("category not found")           // 找不到類別
+	ErrAmbiguous  = errors.New("ambiguous category")           // 符合的子類別不只一個，需要指定主類別
+	ErrSign       = errors.New("sign does not match category") // 正負號與類別收支不符
+	ErrConvention = errors.New("unknown sign convention")      // 不支援的正負號慣例
+	ErrCurrency   = errors.New("invalid currency")             // 幣別代碼錯誤
+)
+
+// 一次匯入的筆數上限
+const MaxRows = 5000
+
+// 日期格式，例如 yyyy/mm/dd 轉成 2006/01/02
+var dateLayout = strings.NewReplacer(
+	"yyyy", "2006",
+	"yy", "06",
+	"mm", "01",
+	"dd", "02",
+	"m", "1",
+	"d", "2",
+)
+
+// 解析 CSV，每一行轉成一筆資料，錯誤記錄在該行的 Error
+//
+// 符合規則時以規則的子類別取代檔案內的類別，沒有幣別欄位時金額以 base 的小數位數解析。
+// 只有檔案本身或欄位對應有問題時才回傳 error
+func ParseCsv(r io.Reader, m bundle.CsvMapping, base string, types []bundle.AllType, e *rule.Engine) ([]bundle.ImportRow, error) {
+	switch m.Sign {
+	case "", bundle.SignExpenseNegative, bundle.SignExpensePositive, bundle.SignAbsolute:
+	default:
+		return nil, ErrConvention
+	}
+
+	r, err := decode(r, m.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+
+	if len(m.Delimiter) > 0 {
+		d, size := utf8.DecodeRuneInString(m.Delimiter)
+		if size != len(m.Delimiter) || d == utf8.RuneError {
+			return nil, ErrDelimiter
+		}
+		cr.Comma = d
+	}
+
+	var header []string
+	if m.Header {
+		record, err := cr.Read()
+		if err != nil {
+			return nil, ErrEmpty
+		}
+		header = make([]string, len(record))
+		for i, h := range record {
+			header[i] = fold(h)
+		}
+	}
+
+	var cols columns
+	if cols.date, err = column(header, m.Date, true); err != nil {
+		return nil, err
+	}
+	if cols.amount, err = column(header, m.Amount, true); err != nil {
+		return nil, err
+	}
+	if cols.main, err = column(header, m.Main, false); err != nil {
+		return nil, err
+	}
+	if cols.sub, err = column(header, m.Sub, true); err != nil {
+		return nil, err
+	}
+	if cols.name, err = column(header, m.Name, false); err != nil {
+		return nil, err
+	}
+	if cols.remark, err = column(header, m.Remark, false); err != nil {
+		return nil, err
+	}
+	if cols.currency, err = column(header, m.Currency, false); err != nil {
+		return nil, err
+	}
+
+	format := m.DateFormat
+	if len(format) == 0 {
+		format = "yyyy-mm-dd"
+	}
+	layout := dateLayout.Replace(strings.ToLower(format))
+
+	cats := newCategories(types, m.Create)
+	rows := make([]bundle.ImportRow, 0)
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if blank(record) {
+			continue
+		}
+
+		if len(rows) == MaxRows {
+			return nil, ErrTooMany
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := parseRecord(record, cols, layout, m.Sign, base, cats, e)
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return rows, nil
+}
+
+// 欄位位置，-1 表示沒有
+type columns struct {
+	date, amount, main, sub, name, remark, currency int
+}
+
+// 轉成 UTF-8，並去除 BOM
+func decode(r io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf-8", "utf8":
+		return transform.NewReader(r, unicode.BOMOverride(transform.Nop)), nil
+	case "big5":
+		return transform.NewReader(r, traditionalchinese.Big5.NewDecoder()), nil
+	}
+
+	return nil, ErrEncoding
+}
+
+// 找出欄位位置，先比對標題，再當成欄位編號
+func column(header []string, spec string, required bool) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) == 0 {
+		if required {
+			return -1, ErrColumn
+		}
+		return -1, nil
+	}
+
+	key := fold(spec)
+	for i, h := range header {
+		if h == key {
+			return i, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(spec); err == nil && n > 0 {
+		return n - 1, nil
+	}
+
+	return -1, ErrColumn
+}
+
+func blank(record []string) bool {
+	for _, f := range record {
+		if len(strings.TrimSpace(f)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRecord(record []string, cols columns, layout, sign, base string, cats *categories, e *rule.Engine) bundle.ImportRow {
+	var row bundle.ImportRow
+
+	get := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.Name = get(cols.name)
+	row.Remark = get(cols.remark)
+	row.MainName = get(cols.main)
+	row.SubName = get(cols.sub)
+
+	date, err := time.Parse(layout, get(cols.date))
+	if err != nil {
+		row.Error = ErrDate.Error()
+		return row
+	}
+	row.Date = date.Format("2006-01-02")
+
+	// 空白表示基準幣別
+	if code := get(cols.currency); len(code) > 0 {
+		if row.Currency, err = currency.Normalize(code); err != nil {
+			row.Error = ErrCurrency.Error()
+			return row
+		}
+	}
+
+	code := row.Currency
+	if len(code) == 0 {
+		code = base
+	}
+
+	amount, err := parseAmount(get(cols.amount), code)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+
+	price, increase := direction(amount, sign)
+	row.Price = price
+
+	if err := applyRule(&row, e, cats, increase); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+
+	return row
+}
@@ -0,0 +1,249 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+	"me.daily/src/bundle"
+	"me.daily/src/rule"
+)
+
+var testTypes = []bundle.AllType{
+	{Id: 1, Name: "收入", Subs: []bundle.Sub{{Id: 11, Name: "薪水", Increase: true}}},
+	{Id: 2, Name: "餐費", Subs: []bundle.Sub{{Id: 21, Name: "午餐"}, {Id: 22, Name: "飲料"}}},
+	{Id: 3, Name: "交通", Subs: []bundle.Sub{{Id: 31, Name: "計程車"}}},
+	{Id: 4, Name: "日用", Subs: []bundle.Sub{{Id: 41, Name: "其他"}}},
+	{Id: 5, Name: "其他", Subs: []bundle.Sub{{Id: 51, Name: "其他"}}},
+}
+
+func TestParseCsvHeader(t *testing.T) {
+	data := "\ufeff日期,金額,類別,名稱,備註\n" +
+		"2022/10/01,-120,午餐,排骨飯,公司\n" +
+		"2022/10/02,\"50,000\",薪水,,\n" +
+		"\n" +
+		"2022/10/03,-3０,饮料,,\n"
+
+	rows, err := ParseCsv(strings.NewReader(data), bundle.CsvMapping{
+		Header:     true,
+		DateFormat: "yyyy/mm/dd",
+		Date:       "日期",
+		Amount:     "金額",
+		Sub:        "類別",
+		Name:       "名稱",
+		Remark:     "備註",
+	}, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bundle.ImportRow{
+		{Line: 2, Date: "2022-10-01", Name: "排骨飯", MainId: 2, MainName: "餐費", SubId: 21, SubName: "午餐", Increase: -1, Price: 120, Remark: "公司"},
+		{Line: 3, Date: "2022-10-02", Name: "薪水", MainId: 1, MainName: "收入", SubId: 11, SubName: "薪水", Increase: 1, Price: 50000},
+		{Line: 5, Date: "2022-10-03", Name: "飲料", MainId: 2, MainName: "餐費", SubId: 22, SubName: "飲料", Increase: -1, Price: 30},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestParseCsvRowErrors(t *testing.T) {
+	data := "10/01/2022;120;午餐\n" +
+		"13/01/2022;120;午餐\n" +
+		"10/02/2022;12.5;午餐\n" +
+		"10/03/2022;120;早餐\n" +
+		"10/04/2022;120;其他\n" +
+		"10/05/2022;120;薪水\n"
+
+	rows, err := ParseCsv(strings.NewReader(data), bundle.CsvMapping{
+		Delimiter:  ";",
+		DateFormat: "mm/dd/yyyy",
+		Sign:       bundle.SignExpensePositive,
+		Date:       "1",
+		Amount:     "2",
+		Sub:        "3",
+	}, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"", ErrDate.Error(), ErrAmount.Error(), ErrCategory.Error(), ErrAmbiguous.Error(), ErrSign.Error()}
+	if len(rows) != len(expected) {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	for i, e := range expected {
+		if rows[i].Error != e || rows[i].Line != i+1 {
+			t.Errorf("row %d = %+v, want error %q", i, rows[i], e)
+		}
+	}
+}
+
+func TestParseCsvCreate(t *testing.T) {
+	data := "2022-10-01,-300,娛樂,KTV\n" +
+		"2022-10-02,-200,娛樂,ｋｔｖ\n" +
+		"2022-10-03,-100,餐費,消夜\n" +
+		"2022-10-04,-100,,電影\n"
+
+	m := bundle.CsvMapping{Date: "1", Amount: "2", Main: "3", Sub: "4"}
+	rows, err := ParseCsv(strings.NewReader(data), m, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if r.Error != ErrCategory.Error() {
+			t.Fatalf("unexpected row %+v", r)
+		}
+	}
+
+	m.Create = true
+	rows, err = ParseCsv(strings.NewReader(data), m, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新的主類別與子類別，第二筆沿用第一筆的名稱
+	if rows[0].MainId != 0 || rows[0].SubId != 0 || rows[0].SubName != "KTV" || rows[0].Error != "" {
+		t.Errorf("unexpected row %+v", rows[0])
+	}
+	if rows[1].MainName != "娛樂" || rows[1].SubName != "KTV" || rows[1].Error != "" {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+
+	// 既有主類別下新的子類別
+	if rows[2].MainId != 2 || rows[2].SubId != 0 || rows[2].Increase != -1 || rows[2].Error != "" {
+		t.Errorf("unexpected row %+v", rows[2])
+	}
+
+	// 沒有主類別無法建立
+	if rows[3].Error != ErrCategory.Error() {
+		t.Errorf("unexpected row %+v", rows[3])
+	}
+}
+
+func TestParseCsvRules(t *testing.T) {
+	data := "2022-10-01,-120,不存在,7-ELEVEN 台北\n" +
+		"2022-10-02,-120,午餐,排骨飯\n"
+
+	e := rule.New([]bundle.Rule{{Id: 1, Keyword: "7-eleven", SubId: 41, Rename: "超商"}})
+	rows, err := ParseCsv(strings.NewReader(data), bundle.CsvMapping{Date: "1", Amount: "2", Sub: "3", Name: "4"}, "TWD", testTypes, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 規則的子類別優先於檔案內的類別
+	if rows[0].SubId != 41 || rows[0].Name != "超商" || rows[0].Error != "" {
+		t.Errorf("unexpected row %+v", rows[0])
+	}
+	if rows[1].SubId != 21 || rows[1].Name != "排骨飯" {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+}
+
+func TestParseCsvBig5(t *testing.T) {
+	data, err := traditionalchinese.Big5.NewEncoder().String("日期,金額,類別\n2022-10-01,(85),計程車\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ParseCsv(bytes.NewReader([]byte(data)), bundle.CsvMapping{
+		Header:   true,
+		Encoding: "big5",
+		Date:     "日期",
+		Amount:   "金額",
+		Sub:      "類別",
+	}, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 1 || rows[0].SubId != 31 || rows[0].Price != 85 || rows[0].Error != "" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+}
+
+func TestParseCsvCurrency(t *testing.T) {
+	data := "日期,金額,類別,幣別\n" +
+		"2022-10-01,-120,午餐,\n" +
+		"2022-10-02,-800,午餐,jpy\n" +
+		"2022-10-03,-5,午餐,US\n"
+
+	rows, err := ParseCsv(strings.NewReader(data), bundle.CsvMapping{
+		Header:   true,
+		Date:     "日期",
+		Amount:   "金額",
+		Sub:      "類別",
+		Currency: "幣別",
+	}, "TWD", testTypes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if rows[0].Currency != "" || rows[0].Error != "" {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Currency != "JPY" || rows[1].Price != 800 || rows[1].Error != "" {
+		t.Errorf("row 1 = %+v", rows[1])
+	}
+	if rows[2].Error != ErrCurrency.Error() {
+		t.Errorf("row 2 = %+v", rows[2])
+	}
+}
+
+func TestParseCsvMapping(t *testing.T) {
+	data := "日期,金額,類別\n2022-10-01,-85,計程車\n"
+
+	cases := []struct {
+		m   bundle.CsvMapping
+		err error
+	}{
+		{bundle.CsvMapping{Header: true, Date: "日期", Amount: "金額"}, ErrColumn},
+		{bundle.CsvMapping{Header: true, Date: "日期", Amount: "價格", Sub: "類別"}, ErrColumn},
+		{bundle.CsvMapping{Date: "1", Amount: "2", Sub: "3", Encoding: "latin1"}, ErrEncoding},
+		{bundle.CsvMapping{Date: "1", Amount: "2", Sub: "3", Delimiter: ";;"}, ErrDelimiter},
+		{bundle.CsvMapping{Date: "1", Amount: "2", Sub: "3", Sign: "debit"}, ErrConvention},
+	}
+
+	for _, c := range cases {
+		if _, err := ParseCsv(strings.NewReader(data), c.m, "TWD", testTypes, nil); err != c.err {
+			t.Errorf("ParseCsv(%+v) = %v, want %v", c.m, err, c.err)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]int{
+		"120":         120,
+		"-1,200":      -1200,
+		"(1200)":      -1200,
+		"NT$ 1200.00": 1200,
+		"１２０元":        120,
+		"+35":         35,
+	}
+
+	for s, expected := range cases {
+		if v, err := parseAmount(s, "TWD"); err != nil || v != expected {
+			t.Errorf("parseAmount(%q) = %d, %v", s, v, err)
+		}
+	}
+
+	// 美元有兩位小數
+	if v, err := parseAmount("$(1,234.5)", "USD"); err != nil || v != -123450 {
+		t.Errorf("parseAmount USD = %d, %v", v, err)
+	}
+
+	for _, s := range []string{"", "abc", "1.5", "1e20", "3000000000"} {
+		if _, err := parseAmount(s, "TWD"); err != ErrAmount {
+			t.Errorf("parseAmount(%q) expected error", s)
+		}
+	}
+}
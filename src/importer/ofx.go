@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// XML 跳脫字元
+var ofxUnescape = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&")
+
+// 一筆交易
+type ofxTransaction struct {
+	date, amount, fitId, name, memo string
+}
+
+// 解析 OFX 對帳單，支援 SGML (1.x) 與 XML (2.x)
+//
+// 1.x 的欄位沒有結束標籤，只看 <STMTTRN> 區塊內的欄位，不需要完整的語法樹。
+// 沒有 CURDEF 時金額以 base 的小數位數解析
+func ParseOfx(r io.Reader, base string) ([]bundle.ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一個標籤之前是 1.x 的標頭
+	header := string(data)
+	if i := bytes.IndexByte(data, '<'); i >= 0 {
+		header = string(data[:i])
+	}
+
+	// XML 宣告也在 2.x 的開頭
+	if i := bytes.Index(data, []byte("<OFX")); i >= 0 {
+		header += string(data[:i])
+	}
+
+	body, _, err := transform.String(ofxDecoder(header), string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	account, curDef := "", ""
+	var cur *ofxTransaction
+	list := make([]ofxTransaction, 0)
+
+	rest := body
+	for {
+		i := strings.IndexByte(rest, '<')
+		if i < 0 {
+			break
+		}
+		rest = rest[i+1:]
+
+		j := strings.IndexByte(rest, '>')
+		if j < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(rest[:j]))
+		rest = rest[j+1:]
+
+		text := rest
+		if k := strings.IndexByte(rest, '<'); k >= 0 {
+			text = rest[:k]
+		}
+		value := strings.TrimSpace(ofxUnescape.Replace(text))
+
+		switch tag {
+		case "STMTTRN":
+			cur = &ofxTransaction{}
+		case "/STMTTRN":
+			if cur != nil {
+				list = append(list, *cur)
+				cur = nil
+			}
+		case "ACCTID":
+			if cur == nil {
+				account = value
+			}
+		case "CURDEF":
+			curDef = value
+		case "DTPOSTED", "TRNAMT", "FITID", "NAME", "MEMO":
+			if cur == nil {
+				continue
+			}
+			switch tag {
+			case "DTPOSTED":
+				cur.date = value
+			case "TRNAMT":
+				cur.amount = value
+			case "FITID":
+				cur.fitId = value
+			case "NAME":
+				cur.name = value
+			case "MEMO":
+				cur.memo = value
+			}
+		}
+	}
+
+	if len(list) == 0 {
+		return nil, ErrEmpty
+	}
+
+	ids := newSyntheticIds("ofx")
+	rows := make([]bundle.ImportRow, len(list))
+	for i, t := range list {
+		row := &rows[i]
+		row.Line = i + 1
+		row.Name = t.name
+		row.Remark = t.memo
+		if len(row.Name) == 0 {
+			row.Name, row.Remark = t.memo, ""
+		}
+		if row.Remark == row.Name {
+			row.Remark = ""
+		}
+
+		// 日期格式 YYYYMMDDHHMMSS.XXX[時區]，只取日期
+		date, err := time.Parse("20060102", prefix(t.date, 8))
+		if err != nil {
+			row.Error = ErrDate.Error()
+			continue
+		}
+		row.Date = date.Format("2006-01-02")
+
+		// 對帳單的幣別，沒有或無法辨識時使用基準幣別
+		row.Currency, _ = currency.Normalize(curDef)
+		code := row.Currency
+		if len(code) == 0 {
+			code = base
+		}
+
+		amount, err := parseAmount(t.amount, code)
+		if err != nil {
+			row.Error = err.Error()
+			continue
+		}
+		row.Price, row.Increase = direction(amount, bundle.SignExpenseNegative)
+
+		// FITID 只在同一個帳戶內唯一
+		if len(t.fitId) > 0 {
+			row.FitId = "ofx:" + account + ":" + t.fitId
+		} else {
+			row.FitId = ids.next(row)
+		}
+	}
+
+	return rows, nil
+}
+
+// 依標頭決定編碼，1.x 以 CHARSET 指定代碼頁，2.x 為 XML 宣告
+func ofxDecoder(header string) transform.Transformer {
+	h := strings.ToUpper(header)
+
+	switch {
+	case strings.Contains(h, "950") || strings.Contains(h, "BIG5"):
+		return traditionalchinese.Big5.NewDecoder()
+	case strings.Contains(h, "CHARSET:1252") && !strings.Contains(h, "ENCODING:UTF-8"):
+		return charmap.Windows1252.NewDecoder()
+	}
+
+	return unicode.BOMOverride(transform.Nop)
+}
+
+func prefix(s string, n int) string {
+	if len(s) < n {
+		return s
+	}
+	return s[:n]
+}
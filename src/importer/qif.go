@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+)
+
+// QIF 日期順序
+const (
+	OrderMDY = "mdy" // 月/日/年，預設
+	OrderDMY = "dmy" // 日/月/年
+	OrderYMD = "ymd" // 年/月/日
+)
+
+// 交易紀錄的帳戶類型，其他類型 (類別清單、投資) 略過
+var qifTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// 解析 QIF 對帳單
+//
+// 每行第一個字元為欄位代號，^ 為一筆結束。L 欄位的類別以 "主類別:子類別" 表示，
+// 轉帳 [帳戶] 不當作類別。QIF 沒有幣別，金額以 base 的小數位數解析
+func ParseQif(r io.Reader, order, base string) ([]bundle.ImportRow, error) {
+	switch order {
+	case "":
+		order = OrderMDY
+	case OrderMDY, OrderDMY, OrderYMD:
+	default:
+		return nil, ErrDate
+	}
+
+	scanner := bufio.NewScanner(transform.NewReader(r, unicode.BOMOverride(transform.Nop)))
+
+	ids := newSyntheticIds("qif")
+	rows := make([]bundle.ImportRow, 0)
+
+	enabled := true
+	started := false
+	var row bundle.ImportRow
+	var date, amount string
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+
+		code, value := text[0], strings.TrimSpace(text[1:])
+
+		if code == '!' {
+			if t, ok := cutPrefixFold(value, "type:"); ok {
+				enabled = qifTypes[strings.ToLower(strings.TrimSpace(t))]
+			} else {
+				// !Account、!Option 之後是帳戶清單或設定
+				enabled = false
+			}
+			continue
+		}
+
+		if !enabled {
+			continue
+		}
+
+		if !started {
+			row = bundle.ImportRow{Line: line}
+			date, amount = "", ""
+			started = true
+		}
+
+		switch code {
+		case 'D':
+			date = value
+		case 'T', 'U':
+			if len(amount) == 0 {
+				amount = value
+			}
+		case 'P':
+			row.Name = value
+		case 'M':
+			row.Remark = value
+		case 'L':
+			if !strings.HasPrefix(value, "[") {
+				main, sub, ok := strings.Cut(value, ":")
+				if ok {
+					row.MainName, row.SubName = main, sub
+				} else {
+					row.SubName = main
+				}
+			}
+		case '^':
+			finishQif(&row, date, amount, order, base, ids)
+			rows = append(rows, row)
+			started = false
+
+			if len(rows) > MaxRows {
+				return nil, ErrTooMany
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return rows, nil
+}
+
+func finishQif(row *bundle.ImportRow, date, amount, order, base string, ids *syntheticIds) {
+	if len(row.Name) == 0 {
+		row.Name, row.Remark = row.Remark, ""
+	}
+
+	d, err := parseQifDate(date, order)
+	if err != nil {
+		row.Error = err.Error()
+		return
+	}
+	row.Date = d.Format("2006-01-02")
+
+	a, err := parseAmount(amount, base)
+	if err != nil {
+		row.Error = err.Error()
+		return
+	}
+	row.Price, row.Increase = direction(a, bundle.SignExpenseNegative)
+
+	// QIF 沒有交易編號，以內容產生
+	row.FitId = ids.next(row)
+}
+
+// 解析 QIF 日期，例如 "12/31/2022"、"12/31'22"、"31.12.2022"
+//
+// 兩位數年份 70 以後為 19xx
+func parseQifDate(s, order string) (time.Time, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\'' || r == ' '
+	})
+	if len(parts) != 3 {
+		return time.Time{}, ErrDate
+	}
+
+	n := make([]int, 3)
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, ErrDate
+		}
+		n[i] = v
+	}
+
+	var y, m, d int
+	switch order {
+	case OrderDMY:
+		d, m, y = n[0], n[1], n[2]
+	case OrderYMD:
+		y, m, d = n[0], n[1], n[2]
+	default:
+		m, d, y = n[0], n[1], n[2]
+	}
+
+	if y < 70 {
+		y += 2000
+	} else if y < 100 {
+		y += 1900
+	}
+
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if t.Year() != y || int(t.Month()) != m || t.Day() != d {
+		return time.Time{}, ErrDate
+	}
+
+	return t, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
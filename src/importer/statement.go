@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"me.daily/src/bundle"
+	"me.daily/src/rule"
+)
+
+// 沒有交易編號時以日期、金額、名稱、備註產生，同一個檔案內相同的內容依序編號
+type syntheticIds struct {
+	prefix string
+	seen   map[string]int
+}
+
+func newSyntheticIds(prefix string) *syntheticIds {
+	return &syntheticIds{prefix: prefix, seen: make(map[string]int)}
+}
+
+func (s *syntheticIds) next(row *bundle.ImportRow) string {
+	key := fmt.Sprintf("%s\x00%d\x00%s\x00%s", row.Date, row.Increase*row.Price, row.Name, row.Remark)
+	n := s.seen[key]
+	s.seen[key] = n + 1
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, n)))
+	return s.prefix + ":" + hex.EncodeToString(sum[:16])
+}
+
+// 決定對帳單每一筆的類別
+//
+// 依序使用規則、檔案內的類別名稱、預設子類別 subId，都沒有時記錄 ErrCategory
+func Match(rows []bundle.ImportRow, e *rule.Engine, types []bundle.AllType, subId int) {
+	cats := newCategories(types, false)
+
+	for i := range rows {
+		row := &rows[i]
+		if len(row.Error) > 0 {
+			continue
+		}
+
+		err := applyRule(row, e, cats, row.Increase)
+		if err == ErrCategory && subId > 0 {
+			err = cats.assign(row, subId, row.Increase)
+		}
+
+		if err != nil {
+			row.Error = err.Error()
+		} else if len(row.Name) == 0 {
+			row.Name = row.SubName
+		}
+	}
+}
+
+// 套用規則後決定類別，規則沒有指定子類別時依名稱比對
+func applyRule(row *bundle.ImportRow, e *rule.Engine, cats *categories, increase int) error {
+	subId := 0
+	if r, ok := e.Apply(rule.Input{Name: row.Name, Remark: row.Remark, Price: row.Price}); ok {
+		row.Name = r.Name
+		row.Remark = r.Remark
+		subId = r.SubId
+	}
+
+	var err error
+	switch {
+	case subId > 0:
+		err = cats.assign(row, subId, increase)
+	case len(row.SubName) > 0:
+		err = cats.resolve(row, increase)
+	default:
+		err = ErrCategory
+	}
+
+	if err == nil && len(row.Name) == 0 {
+		row.Name = row.SubName
+	}
+
+	return err
+}
+
+// 標記已經匯入過的資料，同一個檔案內重複的交易編號也略過
+func MarkSkipped(rows []bundle.ImportRow, existing map[string]bool) {
+	seen := make(map[string]bool)
+	for i := range rows {
+		id := rows[i].FitId
+		if len(id) == 0 {
+			continue
+		}
+
+		if existing[id] || seen[id] {
+			rows[i].Skipped = true
+		}
+		seen[id] = true
+	}
+}
+
+// 全部的交易編號
+func FitIds(rows []bundle.ImportRow) []string {
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if len(r.FitId) > 0 {
+			ids = append(ids, r.FitId)
+		}
+	}
+	return ids
+}
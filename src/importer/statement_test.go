@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+	"me.daily/src/bundle"
+	"me.daily/src/rule"
+)
+
+const testOfxSgml = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+CHARSET:950
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>TWD
+<BANKACCTFROM><BANKID>012<ACCTID>123456<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20221001
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20221001120000.000[+8:CST]
+<TRNAMT>-120.00
+<FITID>A001
+<NAME>統一超商
+<MEMO>午餐
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20221005
+<TRNAMT>50000
+<FITID>A002
+<NAME>薪資轉入
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20221006
+<TRNAMT>-85
+<NAME>台灣大車隊
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const testOfxXml = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CCACCTFROM><ACCTID>9999</ACCTID></CCACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20221002</DTPOSTED><TRNAMT>-300</TRNAMT><FITID>X1</FITID><NAME>KTV &amp; Bar</NAME></STMTTRN>
+</BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>
+`
+
+const testQif = `!Type:Bank
+D10/01/2022
+T-120.00
+P統一超商
+M午餐
+L餐費:午餐
+^
+D10/05'22
+T50,000.00
+P薪資轉入
+^
+D10/06/2022
+T-85
+P台灣大車隊
+L[信用卡]
+^
+D10/06/2022
+T-85
+P台灣大車隊
+L[信用卡]
+^
+D13/40/2022
+T-1
+^
+!Account
+NChecking
+TBank
+^
+`
+
+func TestParseOfxSgml(t *testing.T) {
+	data, err := traditionalchinese.Big5.NewEncoder().String(testOfxSgml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ParseOfx(strings.NewReader(data), "TWD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	r := rows[0]
+	if r.Date != "2022-10-01" || r.Price != 120 || r.Increase != -1 || r.Name != "統一超商" || r.Remark != "午餐" || r.FitId != "ofx:123456:A001" || r.Currency != "TWD" {
+		t.Errorf("unexpected row %+v", r)
+	}
+
+	if rows[1].Increase != 1 || rows[1].Price != 50000 {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+
+	// 沒有 FITID 時由內容產生
+	if !strings.HasPrefix(rows[2].FitId, "ofx:") || rows[2].FitId == "ofx:123456:" {
+		t.Errorf("unexpected fit id %q", rows[2].FitId)
+	}
+}
+
+func TestParseOfxXml(t *testing.T) {
+	rows, err := ParseOfx(strings.NewReader(testOfxXml), "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 1 || rows[0].Name != "KTV & Bar" || rows[0].FitId != "ofx:9999:X1" || rows[0].Price != 30000 || rows[0].Currency != "" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	if _, err := ParseOfx(strings.NewReader("<OFX></OFX>"), "TWD"); err != ErrEmpty {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestParseQif(t *testing.T) {
+	rows, err := ParseQif(strings.NewReader(testQif), "", "TWD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 5 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	r := rows[0]
+	if r.Line != 2 || r.Date != "2022-10-01" || r.Price != 120 || r.Increase != -1 || r.MainName != "餐費" || r.SubName != "午餐" {
+		t.Errorf("unexpected row %+v", r)
+	}
+
+	if rows[1].Date != "2022-10-05" || rows[1].Price != 50000 || rows[1].Increase != 1 {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+
+	// 轉帳不是類別，內容相同的兩筆編號不同
+	if rows[2].SubName != "" || rows[2].FitId == rows[3].FitId {
+		t.Errorf("unexpected rows %+v %+v", rows[2], rows[3])
+	}
+
+	if rows[4].Error != ErrDate.Error() {
+		t.Errorf("unexpected row %+v", rows[4])
+	}
+
+	// 同一個檔案產生的編號固定
+	again, _ := ParseQif(strings.NewReader(testQif), OrderMDY, "TWD")
+	for i := range rows {
+		if rows[i].FitId != again[i].FitId {
+			t.Fatalf("unstable fit id %q %q", rows[i].FitId, again[i].FitId)
+		}
+	}
+}
+
+func TestParseQifDate(t *testing.T) {
+	cases := []struct {
+		s, order, expected string
+	}{
+		{"12/31/2022", OrderMDY, "2022-12-31"},
+		{"12/31'22", OrderMDY, "2022-12-31"},
+		{" 1/ 2/99", OrderMDY, "1999-01-02"},
+		{"31.12.2022", OrderDMY, "2022-12-31"},
+		{"2022-12-31", OrderYMD, "2022-12-31"},
+	}
+
+	for _, c := range cases {
+		d, err := parseQifDate(c.s, c.order)
+		if err != nil || d.Format("2006-01-02") != c.expected {
+			t.Errorf("parseQifDate(%q, %q) = %v, %v", c.s, c.order, d, err)
+		}
+	}
+
+	for _, s := range []string{"", "2/30/2022", "1/2", "a/b/c"} {
+		if _, err := parseQifDate(s, OrderMDY); err != ErrDate {
+			t.Errorf("parseQifDate(%q) expected error", s)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rows := []bundle.ImportRow{
+		{Name: "統一超商 台北", Increase: -1, Price: 120},
+		{Name: "薪資轉入", Increase: 1, Price: 50000},
+		{Name: "不明商店", Increase: -1, Price: 10, SubName: "計程車"},
+		{Name: "不明商店", Increase: -1, Price: 10},
+		{Name: "統一超商", Increase: 1, Price: 10},
+		{Name: "", Increase: -1, Error: ErrDate.Error()},
+	}
+	rules := []bundle.Rule{
+		{Id: 1, Keyword: "超商", SubId: 41, Priority: 1},
+		{Id: 2, Keyword: "統一超商", SubId: 21},
+		{Id: 3, Keyword: "薪資", SubId: 11},
+	}
+
+	Match(rows, rule.New(rules), testTypes, 0)
+
+	// 優先度高的規則先比對
+	if rows[0].SubId != 21 || rows[0].MainName != "餐費" || rows[0].Error != "" {
+		t.Errorf("unexpected row %+v", rows[0])
+	}
+	if rows[1].SubId != 11 || rows[1].Error != "" {
+		t.Errorf("unexpected row %+v", rows[1])
+	}
+	if rows[2].SubId != 31 || rows[2].Error != "" {
+		t.Errorf("unexpected row %+v", rows[2])
+	}
+	if rows[3].Error != ErrCategory.Error() {
+		t.Errorf("unexpected row %+v", rows[3])
+	}
+	if rows[4].Error != ErrSign.Error() {
+		t.Errorf("unexpected row %+v", rows[4])
+	}
+	if rows[5].Error != ErrDate.Error() {
+		t.Errorf("unexpected row %+v", rows[5])
+	}
+
+	// 預設子類別
+	rows = []bundle.ImportRow{{Increase: -1, Price: 10}}
+	Match(rows, nil, testTypes, 41)
+	if rows[0].SubId != 41 || rows[0].Name != "其他" {
+		t.Errorf("unexpected row %+v", rows[0])
+	}
+}
+
+func TestMarkSkipped(t *testing.T) {
+	rows := []bundle.ImportRow{{FitId: "a"}, {FitId: "b"}, {FitId: "b"}, {}}
+	MarkSkipped(rows, map[string]bool{"a": true})
+
+	expected := []bool{true, false, true, false}
+	for i, e := range expected {
+		if rows[i].Skipped != e {
+			t.Errorf("row %d skipped = %v", i, rows[i].Skipped)
+		}
+	}
+}
@@ -1,10 +1,18 @@
+//go:generate swag init -g main.go -o docs
+
+// @title GoDaily
+// @version 1.0
+// @description 記帳 Api
+// @BasePath /
 package main
 
 import (
 	"embed"
 	"flag"
+	"log"
 
 	"github.com/gin-gonic/gin"
+	"me.daily/src/alert"
 	"me.daily/src/service"
 )
 
@@ -13,6 +21,8 @@ var fs embed.FS
 
 var host, user, password, dbname, authUser, authPw string
 
+var mailServer alert.MailServer
+
 func init() {
 	flag.StringVar(&host, "host", "", "database host")
 	flag.StringVar(&user, "user", "", "database user")
@@ -20,11 +30,21 @@ func init() {
 	flag.StringVar(&dbname, "dbname", "", "database dbname")
 	flag.StringVar(&authUser, "authUser", "", "auth user")
 	flag.StringVar(&authPw, "authPw", "", "auth password")
+	flag.StringVar(&mailServer.Addr, "smtpAddr", "", "smtp server host:port, empty to disable email alerts")
+	flag.StringVar(&mailServer.User, "smtpUser", "", "smtp user")
+	flag.StringVar(&mailServer.Password, "smtpPw", "", "smtp password")
+	flag.StringVar(&mailServer.From, "smtpFrom", "", "email alert sender")
 }
 
 func main() {
 	flag.Parse()
 
 	gin.SetMode(gin.ReleaseMode)
-	service.NewService(host, user, password, dbname, authUser, authPw, fs).Start()
+
+	s, err := service.NewService(host, user, password, dbname, authUser, authPw, mailServer, fs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s.Start()
 }
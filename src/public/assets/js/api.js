@@ -34,6 +34,20 @@ function putRequset(url, json) {
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// 最小單位轉成金額，scale 為幣別的小數位數
+function formatPrice(price, scale) {
+  if (!scale)
+    return String(price);
+  return (price / Math.pow(10, scale)).toFixed(scale);
+}
+
+// 十進位金額，例如 "45.5"
+function isPrice(str) {
+  return /^\d+(\.\d+)?$/.test(str);
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
 function hasClass(el, className) {
   if (el.classList)
     return el.classList.contains(className);
@@ -0,0 +1,220 @@
+package quick
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/fuzzy"
+	"me.daily/src/transformer"
+)
+
+var (
+	ErrAmount = errors.New("amount not found") // 找不到金額
+	ErrEmpty  = errors.New("empty text")       // 沒有內容
+)
+
+// 備註分隔符號，之後的文字都當成備註
+const remarkSeparator = "//"
+
+var (
+	amountRegexp = regexp.MustCompile(`^[$]?(\d+(?:\.\d+)?)(元|塊)?$`)
+	isoRegexp    = regexp.MustCompile(`^(\d{4})[-/](\d{1,2})[-/](\d{1,2})$`)
+	monthDay     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})$`)
+)
+
+// 相對日期
+var relativeDays = map[string]int{
+	"大前天": -3,
+	"前天":  -2,
+	"昨天":  -1,
+	"今天":  0,
+	"明天":  1,
+}
+
+// 星期，週一為一週的開始
+var weekdays = map[string]int{
+	"一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "日": 7, "天": 7,
+}
+
+var weekPrefixes = []string{"星期", "禮拜", "週", "周"}
+
+// 解析結果
+type Result struct {
+	Date   time.Time
+	Price  string // 十進位金額，例如 "4.5"
+	SubId  int    // 0 表示沒有符合的類別
+	Name   string
+	Remark string
+	Tags   []string
+}
+
+// 解析快速輸入，例如 "昨天 午餐 排骨飯 120 #公司 // 同事請客"
+//
+// 日期預設為 now，類別以模糊搜尋比對子類別名稱，其餘文字當作名稱
+func Parse(text string, now time.Time, types []bundle.AllType) (Result, error) {
+	var r Result
+
+	text, _, err := transform.String(transformer.Narrow, text)
+	if err != nil {
+		return r, err
+	}
+
+	if i := strings.Index(text, remarkSeparator); i >= 0 {
+		r.Remark = strings.TrimSpace(text[i+len(remarkSeparator):])
+		text = text[:i]
+	}
+
+	r.Date = truncateDay(now)
+	hasAmount := false
+	words := make([]string, 0)
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return r, ErrEmpty
+	}
+
+	for _, f := range fields {
+		if strings.HasPrefix(f, "#") && len(f) > 1 {
+			r.Tags = append(r.Tags, f[1:])
+			continue
+		}
+
+		if d, ok := parseDate(f, now); ok {
+			r.Date = d
+			continue
+		}
+
+		if m := amountRegexp.FindStringSubmatch(f); m != nil && !hasAmount {
+			r.Price = m[1]
+			hasAmount = true
+			continue
+		}
+
+		words = append(words, f)
+	}
+
+	if !hasAmount {
+		return r, ErrAmount
+	}
+
+	// 找出最像類別的字詞
+	index := -1
+	best := 0
+	for i, w := range words {
+		subId, score, ok := matchSub(w, types)
+		if ok && (index < 0 || score > best) {
+			index = i
+			best = score
+			r.SubId = subId
+		}
+	}
+
+	if index >= 0 && len(words) > 1 {
+		words = append(words[:index], words[index+1:]...)
+	}
+	r.Name = strings.Join(words, " ")
+
+	// 標籤加到備註
+	if len(r.Tags) > 0 {
+		tags := "#" + strings.Join(r.Tags, " #")
+		if len(r.Remark) > 0 {
+			r.Remark = tags + " " + r.Remark
+		} else {
+			r.Remark = tags
+		}
+	}
+
+	return r, nil
+}
+
+// 比對子類別名稱，回傳分數最高的
+func matchSub(word string, types []bundle.AllType) (int, int, bool) {
+	t := transformer.NewChineseFold()
+
+	subId := 0
+	best := 0
+	found := false
+	for _, main := range types {
+		for _, sub := range main.Subs {
+			m, ok := fuzzy.FuzzyMatch(sub.Name, word, t)
+			if ok && (!found || m.Score > best) {
+				subId = sub.Id
+				best = m.Score
+				found = true
+			}
+		}
+	}
+
+	return subId, best, found
+}
+
+func parseDate(s string, now time.Time) (time.Time, bool) {
+	today := truncateDay(now)
+
+	if d, ok := relativeDays[s]; ok {
+		return today.AddDate(0, 0, d), true
+	}
+
+	if m := isoRegexp.FindStringSubmatch(s); m != nil {
+		return date(atoi(m[1]), atoi(m[2]), atoi(m[3]), now.Location())
+	}
+
+	if m := monthDay.FindStringSubmatch(s); m != nil {
+		return date(now.Year(), atoi(m[1]), atoi(m[2]), now.Location())
+	}
+
+	// 上週五、下週一、週三
+	shift := 0
+	switch {
+	case strings.HasPrefix(s, "上"):
+		shift = -1
+		s = strings.TrimPrefix(s, "上")
+	case strings.HasPrefix(s, "下"):
+		shift = 1
+		s = strings.TrimPrefix(s, "下")
+	}
+
+	for _, p := range weekPrefixes {
+		if !strings.HasPrefix(s, p) {
+			continue
+		}
+
+		wd, ok := weekdays[strings.TrimPrefix(s, p)]
+		if !ok {
+			return time.Time{}, false
+		}
+
+		// time.Sunday 為 0
+		current := int(today.Weekday())
+		if current == 0 {
+			current = 7
+		}
+
+		return today.AddDate(0, 0, wd-current+7*shift), true
+	}
+
+	return time.Time{}, false
+}
+
+// 檢查日期是否存在，例如 2/30
+func date(year, month, day int, loc *time.Location) (time.Time, bool) {
+	d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if d.Year() != year || int(d.Month()) != month || d.Day() != day {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
@@ -0,0 +1,115 @@
+package quick
+
+import (
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+var types = []bundle.AllType{
+	{Id: 1, Name: "收入", Subs: []bundle.Sub{{Id: 1, Name: "薪水", Increase: true}}},
+	{Id: 2, Name: "餐費", Subs: []bundle.Sub{{Id: 2, Name: "早餐"}, {Id: 3, Name: "午餐"}, {Id: 4, Name: "晚餐"}}},
+	{Id: 3, Name: "交通", Subs: []bundle.Sub{{Id: 5, Name: "捷運"}, {Id: 6, Name: "計程車"}}},
+}
+
+// 2023-04-12 星期三
+var now = time.Date(2023, 4, 12, 15, 4, 5, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	r, err := Parse("昨天 午餐 排骨飯 120 #公司", now, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Date.Format("2006-01-02") != "2023-04-11" {
+		t.Errorf("date %v", r.Date)
+	}
+	if r.SubId != 3 {
+		t.Errorf("sub %d", r.SubId)
+	}
+	if r.Name != "排骨飯" {
+		t.Errorf("name %q", r.Name)
+	}
+	if r.Price != "120" {
+		t.Errorf("price %q", r.Price)
+	}
+	if r.Remark != "#公司" {
+		t.Errorf("remark %q", r.Remark)
+	}
+}
+
+func TestParseCategoryAsName(t *testing.T) {
+	r, err := Parse("計程車 ２５０元 // 趕車", now, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.SubId != 6 || r.Name != "計程車" || r.Price != "250" || r.Remark != "趕車" {
+		t.Fatalf("unexpected %+v", r)
+	}
+
+	if !r.Date.Equal(truncateDay(now)) {
+		t.Fatalf("date %v", r.Date)
+	}
+}
+
+func TestParseNoCategory(t *testing.T) {
+	r, err := Parse("7-ELEVEN 55", now, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.SubId != 0 || r.Name != "7-ELEVEN" {
+		t.Fatalf("unexpected %+v", r)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	r, err := Parse("咖啡 $4.5", now, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Price != "4.5" || r.Name != "咖啡" {
+		t.Fatalf("unexpected %+v", r)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Parse("  ", now, types); err != ErrEmpty {
+		t.Fatalf("unexpected %v", err)
+	}
+
+	if _, err := Parse("午餐 便當", now, types); err != ErrAmount {
+		t.Fatalf("unexpected %v", err)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	cases := map[string]string{
+		"今天":         "2023-04-12",
+		"前天":         "2023-04-10",
+		"2023-01-02": "2023-01-02",
+		"2023/1/2":   "2023-01-02",
+		"3/15":       "2023-03-15",
+		"週一":         "2023-04-10",
+		"星期日":        "2023-04-16",
+		"上週五":        "2023-04-07",
+		"上禮拜一":       "2023-04-03",
+		"下周三":        "2023-04-19",
+	}
+
+	for s, want := range cases {
+		d, ok := parseDate(s, now)
+		if !ok || d.Format("2006-01-02") != want {
+			t.Errorf("parseDate(%q) = %v, %v, want %s", s, d, ok, want)
+		}
+	}
+
+	for _, s := range []string{"上班", "2/30", "週八", "午餐"} {
+		if _, ok := parseDate(s, now); ok {
+			t.Errorf("parseDate(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package recur
+
+import (
+	"errors"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+const dateFormat = "2006-01-02"
+
+var (
+	ErrFrequency = errors.New("unknown frequency") // 不支援的週期
+	ErrInterval  = errors.New("invalid interval")  // 間隔必須大於 0
+	ErrWeekday   = errors.New("invalid weekday")   // 第幾個星期幾錯誤，或週期不是每月、每年
+	ErrDate      = errors.New("invalid date")      // 開始或結束日期錯誤
+	ErrCount     = errors.New("invalid count")     // 次數不可為負數
+)
+
+// 補上預設值，並檢查範本是否可以使用
+//
+// 週期預設每月，間隔預設 1
+func Normalize(r *bundle.Recurring) error {
+	if len(r.Frequency) == 0 {
+		r.Frequency = bundle.RecurMonthly
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+
+	switch r.Frequency {
+	case bundle.RecurDaily, bundle.RecurWeekly, bundle.RecurMonthly, bundle.RecurYearly:
+	default:
+		return ErrFrequency
+	}
+
+	if r.Interval < 0 {
+		return ErrInterval
+	}
+
+	if r.Nth != 0 {
+		if r.Frequency != bundle.RecurMonthly && r.Frequency != bundle.RecurYearly {
+			return ErrWeekday
+		}
+		if r.Nth < -1 || r.Nth > 4 || r.Weekday < 0 || r.Weekday > 6 {
+			return ErrWeekday
+		}
+	}
+
+	start, err := time.Parse(dateFormat, r.Start)
+	if err != nil {
+		return ErrDate
+	}
+
+	if len(r.End) > 0 {
+		end, err := time.Parse(dateFormat, r.End)
+		if err != nil || end.Before(start) {
+			return ErrDate
+		}
+	}
+
+	if r.Count < 0 {
+		return ErrCount
+	}
+
+	return nil
+}
+
+// 區間 [from, to] 內的發生日期，依序排列
+//
+// 次數從開始日期計算，第 nth 個星期幾早於開始日期的那一次不算
+func Between(r bundle.Recurring, from, to time.Time) []time.Time {
+	list := make([]time.Time, 0)
+
+	start, err := time.Parse(dateFormat, r.Start)
+	if err != nil || r.Interval <= 0 {
+		return list
+	}
+
+	if len(r.End) > 0 {
+		if end, err := time.Parse(dateFormat, r.End); err == nil && end.Before(to) {
+			to = end
+		}
+	}
+
+	count := 0
+	for i := 0; ; i++ {
+		d := candidate(r, start, i)
+		if d.After(to) {
+			break
+		}
+		if d.Before(start) {
+			continue
+		}
+
+		count++
+		if r.Count > 0 && count > r.Count {
+			break
+		}
+
+		if !d.Before(from) {
+			list = append(list, d)
+		}
+	}
+
+	return list
+}
+
+// 第 i 個週期的日期
+func candidate(r bundle.Recurring, start time.Time, i int) time.Time {
+	n := i * r.Interval
+
+	switch r.Frequency {
+	case bundle.RecurDaily:
+		return start.AddDate(0, 0, n)
+	case bundle.RecurWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case bundle.RecurYearly:
+		first := time.Date(start.Year()+n, start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return inMonth(r, first, start.Day())
+	}
+
+	first := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	return inMonth(r, first, start.Day())
+}
+
+// 月份內的日期，沒有第 day 天時為月底
+func inMonth(r bundle.Recurring, first time.Time, day int) time.Time {
+	last := first.AddDate(0, 1, -1)
+
+	switch {
+	case r.Nth > 0:
+		offset := (r.Weekday - int(first.Weekday()) + 7) % 7
+		return first.AddDate(0, 0, offset+7*(r.Nth-1))
+	case r.Nth < 0:
+		offset := (int(last.Weekday()) - r.Weekday + 7) % 7
+		return last.AddDate(0, 0, -offset)
+	}
+
+	if day > last.Day() {
+		day = last.Day()
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,112 @@
+package recur
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(dateFormat, s)
+	return t
+}
+
+func dates(list []time.Time) []string {
+	arr := make([]string, 0, len(list))
+	for _, d := range list {
+		arr = append(arr, d.Format(dateFormat))
+	}
+	return arr
+}
+
+func TestNormalize(t *testing.T) {
+	r := bundle.Recurring{Start: "2022-10-05"}
+	if err := Normalize(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Frequency != bundle.RecurMonthly || r.Interval != 1 {
+		t.Fatalf("unexpected recurring %+v", r)
+	}
+
+	cases := []struct {
+		r   bundle.Recurring
+		err error
+	}{
+		{bundle.Recurring{Start: "2022-10-05", Frequency: "hourly"}, ErrFrequency},
+		{bundle.Recurring{Start: "2022-10-05", Interval: -1}, ErrInterval},
+		{bundle.Recurring{Start: "2022-10-05", Frequency: bundle.RecurWeekly, Nth: 1}, ErrWeekday},
+		{bundle.Recurring{Start: "2022-10-05", Nth: 5}, ErrWeekday},
+		{bundle.Recurring{Start: "2022-10-05", Nth: 1, Weekday: 7}, ErrWeekday},
+		{bundle.Recurring{Start: "2022/10/05"}, ErrDate},
+		{bundle.Recurring{Start: "2022-10-05", End: "2022-10-01"}, ErrDate},
+		{bundle.Recurring{Start: "2022-10-05", Count: -1}, ErrCount},
+		{bundle.Recurring{Start: "2022-10-05", Nth: -1, Weekday: 5, Count: 12}, nil},
+	}
+
+	for i := range cases {
+		if err := Normalize(&cases[i].r); err != cases[i].err {
+			t.Errorf("Normalize(%+v) = %v, want %v", cases[i].r, err, cases[i].err)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	cases := []struct {
+		r        bundle.Recurring
+		from, to string
+		expected []string
+	}{
+		// 月底
+		{
+			bundle.Recurring{Frequency: bundle.RecurMonthly, Interval: 1, Start: "2022-01-31"},
+			"2022-01-01", "2022-04-30",
+			[]string{"2022-01-31", "2022-02-28", "2022-03-31", "2022-04-30"},
+		},
+		// 每兩週，區間從中間開始
+		{
+			bundle.Recurring{Frequency: bundle.RecurWeekly, Interval: 2, Start: "2022-10-03"},
+			"2022-10-10", "2022-11-14",
+			[]string{"2022-10-17", "2022-10-31", "2022-11-14"},
+		},
+		// 每月第二個星期二，十月的第二個星期二早於開始日期
+		{
+			bundle.Recurring{Frequency: bundle.RecurMonthly, Interval: 1, Nth: 2, Weekday: 2, Start: "2022-10-20"},
+			"2022-01-01", "2023-01-31",
+			[]string{"2022-11-08", "2022-12-13", "2023-01-10"},
+		},
+		// 每月最後一個星期五，共三次
+		{
+			bundle.Recurring{Frequency: bundle.RecurMonthly, Interval: 1, Nth: -1, Weekday: 5, Start: "2022-10-01", Count: 3},
+			"2022-01-01", "2023-12-31",
+			[]string{"2022-10-28", "2022-11-25", "2022-12-30"},
+		},
+		// 每天到結束日期
+		{
+			bundle.Recurring{Frequency: bundle.RecurDaily, Interval: 1, Start: "2022-10-01", End: "2022-10-03"},
+			"2022-01-01", "2022-12-31",
+			[]string{"2022-10-01", "2022-10-02", "2022-10-03"},
+		},
+		// 閏年的二月二十九日
+		{
+			bundle.Recurring{Frequency: bundle.RecurYearly, Interval: 1, Start: "2024-02-29"},
+			"2024-01-01", "2026-12-31",
+			[]string{"2024-02-29", "2025-02-28", "2026-02-28"},
+		},
+	}
+
+	for _, c := range cases {
+		list := dates(Between(c.r, date(c.from), date(c.to)))
+		if !reflect.DeepEqual(list, c.expected) {
+			t.Errorf("Between(%+v) = %v, want %v", c.r, list, c.expected)
+		}
+	}
+
+	// 次數從開始日期計算，不受區間影響
+	r := bundle.Recurring{Frequency: bundle.RecurDaily, Interval: 1, Start: "2022-10-01", Count: 5}
+	if list := dates(Between(r, date("2022-10-04"), date("2022-10-31"))); !reflect.DeepEqual(list, []string{"2022-10-04", "2022-10-05"}) {
+		t.Fatalf("unexpected list %v", list)
+	}
+}
@@ -0,0 +1,204 @@
+package rule
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/transform"
+	"me.daily/src/bundle"
+	"me.daily/src/fuzzy"
+	"me.daily/src/transformer"
+)
+
+var (
+	ErrField     = errors.New("unknown field")         // 不支援的比對欄位
+	ErrMatch     = errors.New("unknown match type")    // 不支援的比對方式
+	ErrRegexp    = errors.New("invalid regexp")        // 正規表示式錯誤
+	ErrPrice     = errors.New("invalid price range")   // 金額範圍錯誤
+	ErrCondition = errors.New("rule has no condition") // 沒有任何條件
+	ErrAction    = errors.New("rule has no action")    // 沒有任何動作
+)
+
+// 比對的帳單
+type Input struct {
+	Name   string
+	Remark string
+	Price  int
+}
+
+// 套用結果
+type Result struct {
+	Rule   bundle.Rule
+	SubId  int // 0 表示不變更
+	Name   string
+	Remark string
+}
+
+// 補上預設值，並檢查規則是否可以使用
+func Normalize(r *bundle.Rule) error {
+	r.Keyword = strings.TrimSpace(r.Keyword)
+	r.Rename = strings.TrimSpace(r.Rename)
+	r.Remark = strings.TrimSpace(r.Remark)
+
+	if len(r.Field) == 0 {
+		r.Field = bundle.RuleFieldName
+	}
+	if len(r.Match) == 0 {
+		r.Match = bundle.RuleMatchContains
+	}
+
+	switch r.Field {
+	case bundle.RuleFieldName, bundle.RuleFieldRemark, bundle.RuleFieldAny:
+	default:
+		return ErrField
+	}
+
+	switch r.Match {
+	case bundle.RuleMatchContains, bundle.RuleMatchFuzzy:
+	case bundle.RuleMatchRegex:
+		if _, err := regexp.Compile(r.Keyword); err != nil {
+			return ErrRegexp
+		}
+	default:
+		return ErrMatch
+	}
+
+	if r.MinPrice < 0 || r.MaxPrice < 0 || (r.MaxPrice > 0 && r.MinPrice > r.MaxPrice) {
+		return ErrPrice
+	}
+
+	if len(r.Keyword) == 0 && r.MinPrice == 0 && r.MaxPrice == 0 {
+		return ErrCondition
+	}
+
+	if r.SubId == 0 && len(r.Rename) == 0 && len(r.Remark) == 0 {
+		return ErrAction
+	}
+
+	return nil
+}
+
+// 依優先度排序的規則
+type Engine struct {
+	rules []compiled
+}
+
+type compiled struct {
+	rule    bundle.Rule
+	keyword string         // 統一格式後的關鍵字
+	re      *regexp.Regexp // 正規表示式
+}
+
+// 建立規則引擎，無法使用的規則略過
+func New(rules []bundle.Rule) *Engine {
+	e := &Engine{rules: make([]compiled, 0, len(rules))}
+
+	for _, r := range rules {
+		if err := Normalize(&r); err != nil {
+			continue
+		}
+
+		c := compiled{rule: r, keyword: fold(r.Keyword)}
+		if r.Match == bundle.RuleMatchRegex {
+			// 不分大小寫
+			c.re = regexp.MustCompile("(?i)" + r.Keyword)
+		}
+		e.rules = append(e.rules, c)
+	}
+
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		a, b := e.rules[i].rule, e.rules[j].rule
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Id < b.Id
+	})
+
+	return e
+}
+
+// 依序比對規則，套用第一個符合的規則
+func (e *Engine) Apply(in Input) (Result, bool) {
+	if e == nil {
+		return Result{}, false
+	}
+
+	for _, c := range e.rules {
+		if !c.match(in) {
+			continue
+		}
+
+		r := Result{
+			Rule:   c.rule,
+			SubId:  c.rule.SubId,
+			Name:   in.Name,
+			Remark: in.Remark,
+		}
+
+		if len(c.rule.Rename) > 0 {
+			r.Name = c.rule.Rename
+		}
+
+		if len(c.rule.Remark) > 0 && !strings.Contains(r.Remark, c.rule.Remark) {
+			if len(r.Remark) > 0 {
+				r.Remark += " "
+			}
+			r.Remark += c.rule.Remark
+		}
+
+		return r, true
+	}
+
+	return Result{}, false
+}
+
+func (c *compiled) match(in Input) bool {
+	r := c.rule
+
+	if r.MinPrice > 0 && in.Price < r.MinPrice {
+		return false
+	}
+	if r.MaxPrice > 0 && in.Price > r.MaxPrice {
+		return false
+	}
+
+	if len(r.Keyword) == 0 {
+		return true
+	}
+
+	switch r.Field {
+	case bundle.RuleFieldRemark:
+		return c.matchText(in.Remark)
+	case bundle.RuleFieldAny:
+		return c.matchText(in.Name) || c.matchText(in.Remark)
+	default:
+		return c.matchText(in.Name)
+	}
+}
+
+func (c *compiled) matchText(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	switch c.rule.Match {
+	case bundle.RuleMatchRegex:
+		return c.re.MatchString(s)
+	case bundle.RuleMatchFuzzy:
+		_, ok := fuzzy.FuzzyMatch(fold(s), c.keyword, nil)
+		return ok
+	default:
+		return strings.Contains(fold(s), c.keyword)
+	}
+}
+
+// 全形、繁簡、大小寫統一
+func fold(s string) string {
+	f, _, err := transform.String(transformer.NewChineseFold(), s)
+	if err != nil {
+		return s
+	}
+	return f
+}
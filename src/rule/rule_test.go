@@ -0,0 +1,84 @@
+package rule
+
+import (
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+func TestNormalize(t *testing.T) {
+	r := bundle.Rule{Keyword: " 7-ELEVEN ", SubId: 1}
+	if err := Normalize(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Keyword != "7-ELEVEN" || r.Field != bundle.RuleFieldName || r.Match != bundle.RuleMatchContains {
+		t.Fatalf("unexpected rule %+v", r)
+	}
+
+	cases := []struct {
+		r   bundle.Rule
+		err error
+	}{
+		{bundle.Rule{Keyword: "a", SubId: 1, Field: "date"}, ErrField},
+		{bundle.Rule{Keyword: "a", SubId: 1, Match: "glob"}, ErrMatch},
+		{bundle.Rule{Keyword: "(", SubId: 1, Match: bundle.RuleMatchRegex}, ErrRegexp},
+		{bundle.Rule{Keyword: "a", SubId: 1, MinPrice: 10, MaxPrice: 5}, ErrPrice},
+		{bundle.Rule{SubId: 1}, ErrCondition},
+		{bundle.Rule{Keyword: "a"}, ErrAction},
+		{bundle.Rule{MinPrice: 1000, Remark: "大筆"}, nil},
+	}
+
+	for _, c := range cases {
+		if err := Normalize(&c.r); err != c.err {
+			t.Errorf("Normalize(%+v) = %v, want %v", c.r, err, c.err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	e := New([]bundle.Rule{
+		{Id: 1, Keyword: "超商", SubId: 41, Priority: 1},
+		{Id: 2, Keyword: "7-eleven", SubId: 21, Rename: "便利商店", Remark: "7-11"},
+		{Id: 3, Keyword: `^uber\s*eats?`, Match: bundle.RuleMatchRegex, SubId: 22},
+		{Id: 4, Keyword: "台大車", Match: bundle.RuleMatchFuzzy, Field: bundle.RuleFieldAny, SubId: 31},
+		{Id: 5, Keyword: "公司", Field: bundle.RuleFieldRemark, Remark: "公費", MaxPrice: 500, Priority: 2},
+		{Id: 6, Keyword: "(", Match: bundle.RuleMatchRegex, SubId: 1},
+	})
+
+	cases := []struct {
+		in     Input
+		ok     bool
+		ruleId int
+		subId  int
+		name   string
+		remark string
+	}{
+		// 全形、大小寫不同也符合，優先度 0 先比對
+		{Input{Name: "７－ＥＬＥＶＥＮ 超商", Price: 50}, true, 2, 21, "便利商店", "7-11"},
+		{Input{Name: "全家超商", Price: 50}, true, 1, 41, "全家超商", ""},
+		{Input{Name: "UberEats 晚餐", Remark: "加班", Price: 200}, true, 3, 22, "UberEats 晚餐", "加班"},
+		{Input{Name: "計程車", Remark: "台灣大車隊", Price: 200}, true, 4, 31, "計程車", "台灣大車隊"},
+		{Input{Name: "午餐", Remark: "公司聚餐", Price: 300}, true, 5, 0, "午餐", "公司聚餐 公費"},
+		{Input{Name: "午餐", Remark: "公司聚餐", Price: 800}, false, 0, 0, "", ""},
+		{Input{Name: "午餐", Price: 100}, false, 0, 0, "", ""},
+	}
+
+	for _, c := range cases {
+		r, ok := e.Apply(c.in)
+		if ok != c.ok || r.Rule.Id != c.ruleId || r.SubId != c.subId || r.Name != c.name || r.Remark != c.remark {
+			t.Errorf("Apply(%+v) = %+v, %v", c.in, r, ok)
+		}
+	}
+
+	// 備註已經有相同文字時不重複附加
+	r, _ := e.Apply(Input{Name: "7-ELEVEN", Remark: "7-11"})
+	if r.Remark != "7-11" {
+		t.Errorf("unexpected remark %q", r.Remark)
+	}
+
+	var empty *Engine
+	if _, ok := empty.Apply(Input{Name: "午餐"}); ok {
+		t.Error("unexpected match")
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import "me.daily/src/bundle"
+
+// 確認帳戶類型，空白表示現金
+func checkAccountType(t string) (string, bool) {
+	switch t {
+	case "":
+		return bundle.AccountCash, true
+	case bundle.AccountCash, bundle.AccountBank, bundle.AccountCreditCard, bundle.AccountEWallet:
+		return t, true
+	}
+
+	return "", false
+}
+
+// 建立請求轉成轉帳，金額為基準幣別且必須大於 0
+func (s *Service) newTransfer(userId int, r bundle.CreateTransferRequest) (bundle.Transfer, string) {
+	amount, code := s.baseAmount(userId, r.Amount)
+	if code != bundle.CodeOk {
+		return bundle.Transfer{}, code
+	}
+
+	if amount <= 0 {
+		return bundle.Transfer{}, bundle.CodeAmount
+	}
+
+	return bundle.Transfer{
+		FromId: r.FromId,
+		ToId:   r.ToId,
+		Amount: amount,
+		Remark: r.Remark,
+		Date:   r.Date,
+	}, bundle.CodeOk
+}
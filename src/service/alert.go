@@ -0,0 +1,129 @@
+package service
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"me.daily/src/alert"
+	"me.daily/src/bundle"
+	"me.daily/src/log"
+)
+
+// 檢查更新提醒設定的請求，金額為基準幣別
+func (s *Service) newAlertSetting(userId int, r bundle.UpdateAlertSettingRequest) (bundle.AlertSetting, string) {
+	threshold, code := s.baseAmount(userId, r.BillThreshold)
+	if code != bundle.CodeOk {
+		return bundle.AlertSetting{}, code
+	}
+
+	a := bundle.AlertSetting{BillThreshold: threshold}
+	if threshold < 0 {
+		return a, bundle.CodeAlert
+	}
+
+	// 不接受本機與內部網路的 webhook
+	if len(r.Webhook) > 0 {
+		u, err := alert.CheckURL(r.Webhook)
+		if err != nil {
+			return a, bundle.CodeAlert
+		}
+		a.Webhook = u
+	}
+
+	if len(r.Email) > 0 {
+		addr, err := mail.ParseAddress(r.Email)
+		if err != nil {
+			return a, bundle.CodeAlert
+		}
+		a.Email = addr.Address
+	}
+
+	return a, bundle.CodeOk
+}
+
+// 使用者設定的通知方式，沒有設定寄信伺服器時不寄信
+func (s *Service) notifiers(setting bundle.AlertSetting) []alert.Notifier {
+	ns := make([]alert.Notifier, 0)
+
+	if len(setting.Webhook) > 0 {
+		ns = append(ns, &alert.Webhook{URL: setting.Webhook})
+	}
+
+	if len(setting.Email) > 0 && len(s.ms.Addr) > 0 {
+		ns = append(ns, &alert.Mail{Server: s.ms, To: setting.Email})
+	}
+
+	return ns
+}
+
+// 檢查預算與帳單，新的提醒寫入收件匣後再通知，itemIds 為 0 或沒有時只檢查預算
+//
+// 收件匣以 key 去除重複，同一期間同一門檻只通知一次
+func (s *Service) checkAlerts(userId int, itemIds ...int) {
+	setting, err := s.d.GetAlertSetting(userId)
+	if err != nil {
+		return
+	}
+
+	base, err := s.d.GetCurrency(userId)
+	if err != nil {
+		return
+	}
+
+	alerts := make([]bundle.Alert, 0)
+
+	// 缺少匯率時無法計算預算，等補上匯率後的排程檢查
+	if list, code := s.budgetStatus(userId, time.Now()); code == bundle.CodeOk {
+		for _, st := range list {
+			if a, ok := alert.Budget(st, base); ok {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+
+	for _, itemId := range itemIds {
+		if itemId <= 0 || setting.BillThreshold <= 0 {
+			continue
+		}
+
+		if name, amount, err := s.d.GetBillExpense(userId, itemId); err == nil {
+			if a, ok := alert.Bill(itemId, name, amount, setting.BillThreshold, base); ok {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+
+	ns := s.notifiers(setting)
+	for _, a := range alerts {
+		created, err := s.d.InsertAlert(userId, a)
+		if err != nil || !created {
+			continue
+		}
+
+		if err := alert.Send(ns, a); err != nil {
+			log.LogHistory.L.WithFields(logrus.Fields{
+				"Method": "checkAlerts",
+				"UserId": userId,
+				"Error":  err.Error(),
+			}).Error("Alert")
+		}
+	}
+}
+
+// 定時檢查全部使用者，預算也會因為補上匯率或進入新的期間而達到門檻
+func (s *Service) alertLoop() {
+	t := time.NewTicker(alertInterval * time.Second)
+	defer t.Stop()
+
+	for range t.C {
+		users, err := s.d.GetAlertUsers()
+		if err != nil {
+			continue
+		}
+
+		for _, userId := range users {
+			s.checkAlerts(userId, 0)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"me.daily/src/alert"
+	"me.daily/src/bundle"
+)
+
+func TestNewAlertSetting(t *testing.T) {
+	s := &Service{}
+
+	cases := []struct {
+		r    bundle.UpdateAlertSettingRequest
+		code string
+	}{
+		{bundle.UpdateAlertSettingRequest{}, bundle.CodeOk},
+		{bundle.UpdateAlertSettingRequest{Webhook: "https://example.com/hook", Email: "Me <me@example.com>"}, bundle.CodeOk},
+		{bundle.UpdateAlertSettingRequest{Webhook: "ftp://example.com"}, bundle.CodeAlert},
+		{bundle.UpdateAlertSettingRequest{Webhook: "/hook"}, bundle.CodeAlert},
+		{bundle.UpdateAlertSettingRequest{Webhook: "http://169.254.169.254/latest/meta-data"}, bundle.CodeAlert},
+		{bundle.UpdateAlertSettingRequest{Webhook: "http://127.0.0.1:8080/hook"}, bundle.CodeAlert},
+		{bundle.UpdateAlertSettingRequest{Webhook: "http://192.168.1.1/hook"}, bundle.CodeAlert},
+		{bundle.UpdateAlertSettingRequest{Email: "me"}, bundle.CodeAlert},
+	}
+
+	for _, c := range cases {
+		if _, code := s.newAlertSetting(1, c.r); code != c.code {
+			t.Errorf("newAlertSetting(%+v) = %s, want %s", c.r, code, c.code)
+		}
+	}
+
+	a, _ := s.newAlertSetting(1, cases[1].r)
+	if a.Email != "me@example.com" {
+		t.Fatalf("unexpected email %q", a.Email)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	setting := bundle.AlertSetting{Webhook: "https://example.com/hook", Email: "me@example.com"}
+
+	// 沒有寄信伺服器時只用 webhook
+	s := &Service{}
+	if ns := s.notifiers(setting); len(ns) != 1 {
+		t.Fatalf("unexpected notifiers %v", ns)
+	}
+
+	s.ms = alert.MailServer{Addr: "localhost:25", From: "daily@example.com"}
+	if ns := s.notifiers(setting); len(ns) != 2 {
+		t.Fatalf("unexpected notifiers %v", ns)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"time"
+
+	"me.daily/src/budget"
+	"me.daily/src/bundle"
+)
+
+// 建立請求轉成預算，金額為基準幣別
+func (s *Service) newBudget(userId int, r bundle.CreateBudgetRequest) (bundle.Budget, string) {
+	amount, code := s.baseAmount(userId, r.Amount)
+	if code != bundle.CodeOk {
+		return bundle.Budget{}, code
+	}
+
+	b := bundle.Budget{
+		MainId:   r.MainId,
+		SubId:    r.SubId,
+		Period:   r.Period,
+		Amount:   amount,
+		Rollover: r.Rollover,
+		Start:    r.Start,
+	}
+
+	if err := budget.Normalize(&b, time.Now()); err == budget.ErrAmount {
+		return b, bundle.CodeAmount
+	} else if err != nil {
+		return b, bundle.CodeBudget
+	}
+
+	return b, bundle.CodeOk
+}
+
+// 計算日期所在期間的預算狀態，rollover 的預算從開始日期讀取支出
+func (s *Service) budgetStatus(userId int, date time.Time) ([]bundle.BudgetStatus, string) {
+	list := make([]bundle.BudgetStatus, 0)
+
+	budgets, err := s.d.GetBudgets(userId)
+	if err != nil {
+		return list, err.Error()
+	}
+
+	for _, b := range budgets {
+		start, end := budget.Bounds(b.Period, date)
+		if b.Rollover && b.Start < start.Format(dateFormat) {
+			start, _ = time.Parse(dateFormat, b.Start)
+		}
+
+		sums, err := s.d.GetBudgetSpent(userId, b, start.Format(dateFormat), end.Format(dateFormat))
+		if err != nil {
+			return list, err.Error()
+		}
+
+		list = append(list, budget.Status(b, sums, date))
+	}
+
+	return list, bundle.CodeOk
+}
@@ -0,0 +1,32 @@
+package service
+
+import "me.daily/src/bundle"
+
+// 分類器已訓練時取得帳單原本的內容，修改或刪除後從分類器移除
+func (s *Service) trainedItem(userId, itemId int) (bundle.Item, bool) {
+	if _, ok := s.nb.Loaded(userId); !ok {
+		return bundle.Item{}, false
+	}
+
+	item, err := s.d.GetItem(userId, itemId)
+	if err != nil {
+		// 無法同步，下次重新訓練
+		s.nb.Reset(userId)
+		return bundle.Item{}, false
+	}
+
+	return item, true
+}
+
+// 增量訓練，分類器尚未訓練時略過，第一次查詢時會讀取全部帳單
+func (s *Service) learnItem(userId, subId int, name, remark string) {
+	if cl, ok := s.nb.Loaded(userId); ok {
+		cl.Add(subId, name, remark)
+	}
+}
+
+func (s *Service) forgetItem(userId, subId int, name, remark string) {
+	if cl, ok := s.nb.Loaded(userId); ok {
+		cl.Remove(subId, name, remark)
+	}
+}
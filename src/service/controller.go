@@ -1,42 +1,160 @@
 package service
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/text/transform"
+	"me.daily/src/archive"
 	"me.daily/src/bundle"
+	"me.daily/src/currency"
+	"me.daily/src/duplicate"
+	"me.daily/src/export"
 	"me.daily/src/fuzzy"
+	"me.daily/src/importer"
 	"me.daily/src/log"
+	"me.daily/src/rule"
 	"me.daily/src/token"
+	"me.daily/src/transformer"
 	"me.daily/src/util"
 )
 
+// @Summary 建立帳戶
+// @Description 建立帳戶，type 為 cash、bank、credit_card、e_wallet，預設 cash，opening 為基準幣別的期初餘額，信用卡可以是負數
+// @Tags create
+// @Accept json
+// @Produce json
+// @Param Body body bundle.CreateAccountRequest true "建立帳戶"
+// @Success 200 {object} bundle.CreateAccountResponse
+// @Router /api/account [post]
+func (s *Service) createAccount(c *gin.Context) {
+	var b bundle.CreateAccountResponse
+	var create bundle.CreateAccountRequest
+
+	err := c.BindJSON(&create)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		accountType, ok := checkAccountType(create.Type)
+		opening, code := s.baseAmount(userId, create.Opening)
+
+		if !ok {
+			b.Code = bundle.CodeAccount
+		} else if code != bundle.CodeOk {
+			b.Code = code
+		} else if accountId, err := s.d.InsertAccount(userId, create.Name, accountType, opening); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.AccountId = accountId
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "createAccount",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 建立預算
+// @Description 建立主類別或子類別的預算，main_id 與 sub_id 只能指定一個。period 為 week、month、year，預設 month，每週從星期一開始。
+// @Description amount 為基準幣別的金額，格式與項目的 price 相同。start 預設今天，會調整到所在期間的第一天，rollover 為 true 時從 start 開始累計未用完的金額。錯誤回傳 E-035
+// @Tags create
+// @Accept json
+// @Produce json
+// @Param Body body bundle.CreateBudgetRequest true "建立預算"
+// @Success 200 {object} bundle.CreateBudgetResponse
+// @Router /api/budget [post]
+func (s *Service) createBudget(c *gin.Context) {
+	var b bundle.CreateBudgetResponse
+	var create bundle.CreateBudgetRequest
+
+	err := c.BindJSON(&create)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+
+		if bg, code := s.newBudget(userId, create); code != bundle.CodeOk {
+			b.Code = code
+		} else if budgetId, err := s.d.InsertBudget(userId, bg); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.BudgetId = budgetId
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "createBudget",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
 // @Summary 建立項目
-// @Description 建立項目
+// @Description 建立項目，先依規則修改類別、名稱與備註，有相同子類別、金額、日期且名稱相似的帳單時回傳 E-022，force 為 true 時略過檢查。
+// @Description price 為十進位數字或字串，例如 "45.5"，小數位數不可超過幣別，否則回傳 E-033。currency 省略時使用基準幣別，代碼錯誤回傳 E-031。
+// @Description refund 為 true 時是退款或調整，金額從同類別的統計扣除。refund_of 可以連結原帳單，需要相同的子類別與幣別，退款總額不可超過原金額，否則回傳 E-034
 // @Tags create
 // @Accept json
 // @Produce json
 // @Param Body body bundle.CreateItemRequest true "建立項目"
+// @Success 200 {object} bundle.CreateItemResponse
 // @Router /api/item [post]
 func (s *Service) createItem(c *gin.Context) {
 	var b bundle.CreateItemResponse
 	var create bundle.CreateItemRequest
+	var ok bool
 
 	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
+	} else if create.Currency, ok = checkCurrency(create.Currency); !ok {
+		b.Code = bundle.CodeCurrency
 	} else {
 		userId := c.GetInt("user_id")
-		err := s.d.InsertItem(userId, create.Name, create.SubId, create.Price, create.Remark, create.Date)
+		bill, code := s.newBill(userId, create)
+		e, err := s.ruleEngine(userId)
 
-		if err != nil {
+		if code != bundle.CodeOk {
+			b.Code = code
+		} else if err != nil {
 			b.Code = err.Error()
 		} else {
-			b.Code = bundle.CodeOk
+			// 連結原帳單的退款沿用原帳單的類別，退款本來就和原帳單相似，不檢查重複
+			if bill.RefundOf == 0 {
+				b.RuleId = applyRule(e, &bill)
+			}
+			b.Duplicates, b.Code = s.findDuplicates(userId, bill.SubId, bill.Price, bill.Name, bill.Date, create.Force || bill.Refund)
+		}
+
+		if b.Code == bundle.CodeOk {
+			itemId, err := s.d.InsertItem(userId, bill)
+
+			if err != nil {
+				b.Code = err.Error()
+			} else {
+				b.ItemId = itemId
+				s.learnItem(userId, bill.SubId, bill.Name, bill.Remark)
+				go s.checkAlerts(userId, itemId)
+			}
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
@@ -55,7 +173,8 @@ func (s *Service) createItem(c *gin.Context) {
 // @Tags create
 // @Accept json
 // @Produce json
-// @Param Body body bundle.CreateMainTypeResponse true "建立主類別"
+// @Param Body body bundle.CreateMainTypeRequest true "建立主類別"
+// @Success 200 {object} bundle.CreateMainTypeResponse
 // @Router /api/main [post]
 func (s *Service) createMainType(c *gin.Context) {
 	var b bundle.CreateMainTypeResponse
@@ -87,34 +206,92 @@ func (s *Service) createMainType(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 建立子類別
-// @Description 建立子類別
+// @Summary 快速輸入項目
+// @Description 解析一行文字成項目，例如 "昨天 午餐 排骨飯 120 #公司"，commit 為 true 時直接建立，名稱須 2 到 32 字、備註最多 64 字，否則回傳格式錯誤
 // @Tags create
 // @Accept json
 // @Produce json
-// @Param Body body bundle.CreateSubTypeResponse true "建立子類別"
-// @Router /api/sub [post]
-func (s *Service) createSubType(c *gin.Context) {
-	var b bundle.CreateSubTypeResponse
-	var create bundle.CreateSubTypeRequest
+// @Param Body body bundle.QuickItemRequest true "快速輸入"
+// @Success 200 {object} bundle.QuickItemResponse
+// @Router /api/item/quick [post]
+func (s *Service) createQuickItem(c *gin.Context) {
+	var b bundle.QuickItemResponse
+	var quickReq bundle.QuickItemRequest
+
+	err := c.BindJSON(&quickReq)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		bill, ruleId, code := s.parseQuickItem(userId, quickReq.Text, &b.Item)
+		b.RuleId, b.Code = ruleId, code
+
+		if b.Code == bundle.CodeOk && quickReq.Commit {
+			if bill.SubId == 0 {
+				b.Code = bundle.CodeQuickParse
+			} else if !validQuickItem(bill) {
+				b.Code = bundle.CodeFormat
+			} else {
+				b.Duplicates, b.Code = s.findDuplicates(userId, bill.SubId, bill.Price, bill.Name, bill.Date, quickReq.Force)
+			}
+
+			if b.Code == bundle.CodeOk {
+				itemId, err := s.d.InsertItem(userId, bill)
+				if err != nil {
+					b.Code = err.Error()
+				} else {
+					b.Committed = true
+					s.learnItem(userId, bill.SubId, bill.Name, bill.Remark)
+					go s.checkAlerts(userId, itemId)
+				}
+			}
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "createQuickItem",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 建立匯率
+// @Description 建立一筆外幣對基準幣別的匯率，1 單位外幣等於 rate 單位基準幣別，同一天已有匯率時覆蓋。幣別與基準幣別相同時回傳 E-031
+// @Tags create
+// @Accept json
+// @Produce json
+// @Param Body body bundle.CreateRateRequest true "建立匯率"
+// @Success 200 {object} bundle.CreateRateResponse
+// @Router /api/rate [post]
+func (s *Service) createRate(c *gin.Context) {
+	var b bundle.CreateRateResponse
+	var create bundle.CreateRateRequest
 
 	err := c.BindJSON(&create)
-	if err != nil {
+	if err == nil {
+		_, err = time.Parse(dateFormat, create.Date)
+	}
+
+	if err != nil || create.Rate <= 0 {
 		b.Code = bundle.CodeFormat
+	} else if code, err := currency.Normalize(create.Currency); err != nil {
+		b.Code = bundle.CodeCurrency
 	} else {
 		userId := c.GetInt("user_id")
-		subId, err := s.d.InsertSubType(userId, create.MainId, create.Name, create.Increase)
+		rateId, err := s.d.InsertRates(userId, []bundle.Rate{{Currency: code, Date: create.Date, Rate: create.Rate}})
 
 		if err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
-			b.Name = create.Name
-			b.SubId = subId
+			b.RateId = rateId
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "createSubType",
+			"Method": "createRate",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -124,32 +301,41 @@ func (s *Service) createSubType(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 建立使用者
-// @Description 建立使用者
+// @Summary 建立定期帳單
+// @Description 建立定期帳單範本，排程會在發生當天建立帳單，停機期間的帳單在重新啟動後補上。frequency 為 daily、weekly、monthly、yearly，預設 monthly，interval 為每幾個週期一次，預設 1。
+// @Description 每月與每年預設是 start 的同一天，沒有這一天時為月底；nth 為 1 到 4 時是第 nth 個星期 weekday，-1 為最後一個，weekday 0 為星期日。end 與 count 為 0 或空白表示不限。
+// @Description price 與 currency 的格式與建立項目相同，account_id 為 0 時使用預設帳戶。錯誤回傳 E-037
 // @Tags create
 // @Accept json
 // @Produce json
-// @Param Body body bundle.CreateUserRequest true "建立使用者"
-// @Router /api/user [post]
-func (s *Service) createUser(c *gin.Context) {
-	var b bundle.ErrorResponse
-	var create bundle.CreateUserRequest
+// @Param Body body bundle.CreateRecurringRequest true "建立定期帳單"
+// @Success 200 {object} bundle.CreateRecurringResponse
+// @Router /api/recurring [post]
+func (s *Service) createRecurring(c *gin.Context) {
+	var b bundle.CreateRecurringResponse
+	var create bundle.CreateRecurringRequest
+	var ok bool
 
 	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
+	} else if create.Currency, ok = checkCurrency(create.Currency); !ok {
+		b.Code = bundle.CodeCurrency
 	} else {
-		pw := util.HashPassword(create.Password)
-		userId, err := s.d.CreateUser(create.Username, pw)
+		userId := c.GetInt("user_id")
 
-		if err != nil {
+		if rc, code := s.newRecurring(userId, create); code != bundle.CodeOk {
+			b.Code = code
+		} else if recurringId, err := s.d.InsertRecurring(userId, rc); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			b.RecurringId = recurringId
+			go s.materializeRecurring(today())
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "createUser",
+			"Method": "createRecurring",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -159,31 +345,38 @@ func (s *Service) createUser(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 刪除項目
-// @Description 刪除項目
-// @Tags delete
-// @Param item_id path int true "項目編號"
+// @Summary 建立規則
+// @Description 建立規則，field 為比對欄位(name、remark、any)，match 為比對方式(contains、regex、fuzzy)，
+// @Description 可再限制金額範圍(幣別的最小單位，0 表示不限)。符合時修改子類別、名稱並加上備註，sub_id 為 0 表示不變更。
+// @Description 建立項目、快速輸入與匯入時依 priority 由小到大比對，套用第一個符合的規則，格式錯誤回傳 E-028
+// @Tags create
 // @Accept json
 // @Produce json
-// @Router /api/item/{item_id} [delete]
-func (s *Service) deleteItem(c *gin.Context) {
-	var b bundle.DeleteMainTypeResponse
-	userId := c.GetInt("user_id")
-	itemId, err := strconv.Atoi(c.Param("item_id"))
+// @Param Body body bundle.CreateRuleRequest true "建立規則"
+// @Success 200 {object} bundle.CreateRuleResponse
+// @Router /api/rule [post]
+func (s *Service) createRule(c *gin.Context) {
+	var b bundle.CreateRuleResponse
+	var create bundle.CreateRuleRequest
 
+	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		err := s.d.DeleteItem(userId, itemId)
+		userId := c.GetInt("user_id")
+		r := newRule(create)
 
-		if err != nil {
+		if err := rule.Normalize(&r); err != nil {
+			b.Code = bundle.CodeRule
+		} else if ruleId, err := s.d.InsertRule(userId, r); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			b.RuleId = ruleId
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "deleteItem",
+			"Method": "createRule",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -193,31 +386,61 @@ func (s *Service) deleteItem(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 刪除主類別名稱
-// @Description 刪除主類別名稱
-// @Tags delete
-// @Param main_id path int true "主類別編號"
+// @Summary 測試規則
+// @Description 以既有帳單測試規則，回傳符合的帳單與套用後的結果，不寫入資料。
+// @Description start、end 預設為最近一年，list 最多 200 筆，total 為全部符合的筆數
+// @Tags get
 // @Accept json
 // @Produce json
-// @Router /api/main/{main_id} [delete]
-func (s *Service) deleteMainType(c *gin.Context) {
-	var b bundle.DeleteMainTypeResponse
-	userId := c.GetInt("user_id")
-	mainId, err := strconv.Atoi(c.Param("main_id"))
+// @Param Body body bundle.TestRuleRequest true "測試規則"
+// @Success 200 {object} bundle.TestRuleResponse
+// @Router /api/rule/test [post]
+func (s *Service) testRule(c *gin.Context) {
+	var b bundle.TestRuleResponse
+	var test bundle.TestRuleRequest
+	b.List = make([]bundle.RuleTestResult, 0)
+
+	err := c.BindJSON(&test)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		s.testRuleItems(userId, test, &b)
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 建立子類別
+// @Description 建立子類別
+// @Tags create
+// @Accept json
+// @Produce json
+// @Param Body body bundle.CreateSubTypeRequest true "建立子類別"
+// @Success 200 {object} bundle.CreateSubTypeResponse
+// @Router /api/sub [post]
+func (s *Service) createSubType(c *gin.Context) {
+	var b bundle.CreateSubTypeResponse
+	var create bundle.CreateSubTypeRequest
 
+	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		err := s.d.DeleteMainType(userId, mainId)
+		userId := c.GetInt("user_id")
+		subId, err := s.d.InsertSubType(userId, create.MainId, create.Name, create.Increase)
 
 		if err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			b.Name = create.Name
+			b.SubId = subId
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "deleteMainType",
+			"Method": "createSubType",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -227,31 +450,36 @@ func (s *Service) deleteMainType(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 刪除子類別名稱
-// @Description 刪除子類別名稱
-// @Tags delete
-// @Param sub_id path int true "子類別編號"
+// @Summary 建立轉帳
+// @Description 建立帳戶間轉帳，例如提款或繳信用卡費。轉帳只影響帳戶餘額，不計入收入與支出，轉出與轉入帳戶相同時回傳 E-030
+// @Tags create
 // @Accept json
 // @Produce json
-// @Router /api/sub/{sub_id} [delete]
-func (s *Service) deleteSubType(c *gin.Context) {
-	var b bundle.DeleteMainTypeResponse
-	userId := c.GetInt("user_id")
-	subId, err := strconv.Atoi(c.Param("sub_id"))
+// @Param Body body bundle.CreateTransferRequest true "建立轉帳"
+// @Success 200 {object} bundle.CreateTransferResponse
+// @Router /api/transfer [post]
+func (s *Service) createTransfer(c *gin.Context) {
+	var b bundle.CreateTransferResponse
+	var create bundle.CreateTransferRequest
 
+	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		err := s.d.DeleteSubType(userId, subId)
+		userId := c.GetInt("user_id")
+		t, code := s.newTransfer(userId, create)
 
-		if err != nil {
+		if code != bundle.CodeOk {
+			b.Code = code
+		} else if transferId, err := s.d.InsertTransfer(userId, t); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			b.TransferId = transferId
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "deleteSubType",
+			"Method": "createTransfer",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -261,40 +489,33 @@ func (s *Service) deleteSubType(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 登入
-// @Description 登入
-// @Tags login
+// @Summary 建立使用者
+// @Description 建立使用者
+// @Tags create
 // @Accept json
 // @Produce json
-// @Param Body body bundle.LoginRequest true "登入"
-// @Router /api/login [post]
-func (s *Service) login(c *gin.Context) {
+// @Param Body body bundle.CreateUserRequest true "建立使用者"
+// @Success 200 {object} bundle.ErrorResponse
+// @Router /api/user [post]
+func (s *Service) createUser(c *gin.Context) {
 	var b bundle.ErrorResponse
-	var login bundle.LoginRequest
+	var create bundle.CreateUserRequest
 
-	err := c.BindJSON(&login)
+	err := c.BindJSON(&create)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		userId, pw, err := s.d.Login(login.Username)
+		pw := util.HashPassword(create.Password)
+		userId, err := s.d.CreateUser(create.Username, pw)
 
 		if err != nil {
 			b.Code = err.Error()
 		} else {
-			// 比對密碼
-			if !util.CheckPasswordHash(login.Password, pw) {
-				b.Code = bundle.CodePassword
-			} else {
-				auth := token.NewToken(userId, login.Username, expiredTime*time.Second)
-				c.SetCookie("Authorization", auth, expiredTime, "/", c.Request.Host, false, false)
-				b.Code = bundle.CodeOk
-
-				s.c.Add(strconv.Itoa(userId), nil, cache.DefaultExpiration)
-			}
+			b.Code = bundle.CodeOk
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
-			"Method": "login",
+			"Method": "createUser",
 			"UserId": userId,
 			"Code":   b.Code,
 		}).Info("Api")
@@ -304,340 +525,1904 @@ func (s *Service) login(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 登出
-// @Description 登出
-// @Tags get
-// @Router /api/logout [get]
-func (s *Service) logout(c *gin.Context) {
-	c.SetCookie("Authorization", "", -1, "/", c.Request.Host, false, false)
-
-	c.Set("code", bundle.CodeOk)
-	c.JSON(http.StatusOK, bundle.ErrorResponse{
-		Code: bundle.CodeOk,
-	})
-}
-
-// @Summary 取得全部類別
-// @Description 取得全部類別
-// @Tags get
+// @Summary 刪除帳戶
+// @Description 刪除帳戶，帳單保留在原帳戶。不能刪除最後一個帳戶，回傳 E-029
+// @Tags delete
+// @Param account_id path int true "帳戶編號"
 // @Accept json
 // @Produce json
-// @Router /api/all [get]
-func (s *Service) getAll(c *gin.Context) {
-	var b bundle.GetAllTypeResponse
+// @Success 200 {object} bundle.DeleteAccountResponse
+// @Router /api/account/{account_id} [delete]
+func (s *Service) deleteAccount(c *gin.Context) {
+	var b bundle.DeleteAccountResponse
 	userId := c.GetInt("user_id")
-
-	all, err := s.d.GetAllType(userId)
+	accountId, err := strconv.Atoi(c.Param("account_id"))
 
 	if err != nil {
-		b.Code = err.Error()
+		b.Code = bundle.CodeFormat
 	} else {
-		b.Code = bundle.CodeOk
-		b.List = all
+		err := s.d.DeleteAccount(userId, accountId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteAccount",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 取得單一項目
-// @Description 取得單一項目
-// @Tags get
-// @Param item_id path int true "項目編號"
+// @Summary 刪除預算
+// @Description 刪除預算
+// @Tags delete
+// @Param budget_id path int true "預算編號"
 // @Accept json
 // @Produce json
-// @Router /api/item/{item_id} [get]
-func (s *Service) getItem(c *gin.Context) {
-	var b bundle.GetItemResponse
-
+// @Success 200 {object} bundle.DeleteBudgetResponse
+// @Router /api/budget/{budget_id} [delete]
+func (s *Service) deleteBudget(c *gin.Context) {
+	var b bundle.DeleteBudgetResponse
 	userId := c.GetInt("user_id")
-	itemId, err := strconv.Atoi(c.Param("item_id"))
+	budgetId, err := strconv.Atoi(c.Param("budget_id"))
 
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		item, err := s.d.GetItem(userId, itemId)
+		err := s.d.DeleteBudget(userId, budgetId)
+
 		if err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
-			b.Item = item
 		}
-	}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteBudget",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除項目
+// @Description 刪除項目
+// @Tags delete
+// @Param item_id path int true "項目編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteMainTypeResponse
+// @Router /api/item/{item_id} [delete]
+func (s *Service) deleteItem(c *gin.Context) {
+	var b bundle.DeleteMainTypeResponse
+	userId := c.GetInt("user_id")
+	itemId, err := strconv.Atoi(c.Param("item_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		old, loaded := s.trainedItem(userId, itemId)
+		err := s.d.DeleteItem(userId, itemId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			if loaded {
+				s.forgetItem(userId, old.SubId, old.Name, old.Remark)
+			}
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteItem",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除主類別名稱
+// @Description 刪除主類別名稱
+// @Tags delete
+// @Param main_id path int true "主類別編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteMainTypeResponse
+// @Router /api/main/{main_id} [delete]
+func (s *Service) deleteMainType(c *gin.Context) {
+	var b bundle.DeleteMainTypeResponse
+	userId := c.GetInt("user_id")
+	mainId, err := strconv.Atoi(c.Param("main_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteMainType(userId, mainId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			s.nb.Reset(userId)
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteMainType",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除匯率
+// @Description 刪除匯率
+// @Tags delete
+// @Param rate_id path int true "匯率編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteRateResponse
+// @Router /api/rate/{rate_id} [delete]
+func (s *Service) deleteRate(c *gin.Context) {
+	var b bundle.DeleteRateResponse
+	userId := c.GetInt("user_id")
+	rateId, err := strconv.Atoi(c.Param("rate_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteRate(userId, rateId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteRate",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除定期帳單
+// @Description 刪除定期帳單與單次的例外，已建立的帳單保留
+// @Tags delete
+// @Param recurring_id path int true "定期帳單編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteRecurringResponse
+// @Router /api/recurring/{recurring_id} [delete]
+func (s *Service) deleteRecurring(c *gin.Context) {
+	var b bundle.DeleteRecurringResponse
+	userId := c.GetInt("user_id")
+	recurringId, err := strconv.Atoi(c.Param("recurring_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteRecurring(userId, recurringId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteRecurring",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除規則
+// @Description 刪除名稱對應規則
+// @Tags delete
+// @Param rule_id path int true "規則編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteRuleResponse
+// @Router /api/rule/{rule_id} [delete]
+func (s *Service) deleteRule(c *gin.Context) {
+	var b bundle.DeleteRuleResponse
+	userId := c.GetInt("user_id")
+	ruleId, err := strconv.Atoi(c.Param("rule_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteRule(userId, ruleId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteRule",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除子類別名稱
+// @Description 刪除子類別名稱
+// @Tags delete
+// @Param sub_id path int true "子類別編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteSubTypeResponse
+// @Router /api/sub/{sub_id} [delete]
+func (s *Service) deleteSubType(c *gin.Context) {
+	var b bundle.DeleteMainTypeResponse
+	userId := c.GetInt("user_id")
+	subId, err := strconv.Atoi(c.Param("sub_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteSubType(userId, subId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			s.nb.Reset(userId)
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteSubType",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 刪除轉帳
+// @Description 刪除轉帳
+// @Tags delete
+// @Param transfer_id path int true "轉帳編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.DeleteTransferResponse
+// @Router /api/transfer/{transfer_id} [delete]
+func (s *Service) deleteTransfer(c *gin.Context) {
+	var b bundle.DeleteTransferResponse
+	userId := c.GetInt("user_id")
+	transferId, err := strconv.Atoi(c.Param("transfer_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		err := s.d.DeleteTransfer(userId, transferId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "deleteTransfer",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 登入
+// @Description 登入
+// @Tags login
+// @Accept json
+// @Produce json
+// @Param Body body bundle.LoginRequest true "登入"
+// @Success 200 {object} bundle.ErrorResponse
+// @Router /api/login [post]
+func (s *Service) login(c *gin.Context) {
+	var b bundle.ErrorResponse
+	var login bundle.LoginRequest
+
+	err := c.BindJSON(&login)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId, pw, err := s.d.Login(login.Username)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			// 比對密碼
+			if !util.CheckPasswordHash(login.Password, pw) {
+				b.Code = bundle.CodePassword
+			} else {
+				auth := token.NewToken(userId, login.Username, expiredTime*time.Second)
+				c.SetCookie("Authorization", auth, expiredTime, "/", c.Request.Host, false, false)
+				b.Code = bundle.CodeOk
+
+				s.c.Add(strconv.Itoa(userId), nil, cache.DefaultExpiration)
+			}
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "login",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 登出
+// @Description 登出
+// @Tags get
+// @Produce json
+// @Success 200 {object} bundle.ErrorResponse
+// @Router /api/logout [get]
+func (s *Service) logout(c *gin.Context) {
+	c.SetCookie("Authorization", "", -1, "/", c.Request.Host, false, false)
+
+	c.Set("code", bundle.CodeOk)
+	c.JSON(http.StatusOK, bundle.ErrorResponse{
+		Code: bundle.CodeOk,
+	})
+}
+
+// @Summary 取得帳戶
+// @Description 取得帳戶與目前餘額，餘額為期初餘額加上收入與轉入，減去支出與轉出
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetAccountsResponse
+// @Router /api/accounts [get]
+func (s *Service) getAccounts(c *gin.Context) {
+	var b bundle.GetAccountsResponse
+	b.List = make([]bundle.Account, 0)
+
+	userId := c.GetInt("user_id")
+	list, err := s.d.GetAccounts(userId)
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = list
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得帳戶明細
+// @Description 取得帳戶在日期區間內的帳單、轉帳與逐筆餘額，opening 為區間開始前的餘額，未指定日期時取得全部
+// @Tags get
+// @Param account_id	path int true "帳戶編號"
+// @Param start			query string false "起始日期"
+// @Param end			query string false "結束日期"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetAccountItemsResponse
+// @Router /api/account/{account_id}/items [get]
+func (s *Service) getAccountItems(c *gin.Context) {
+	var b bundle.GetAccountItemsResponse
+	b.List = make([]bundle.AccountEntry, 0)
+
+	userId := c.GetInt("user_id")
+	accountId, err := strconv.Atoi(c.Param("account_id"))
+	startStr, endStr, code := queryOptionalRange(c)
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else if code != bundle.CodeOk {
+		b.Code = code
+	} else {
+		opening, list, err := s.d.GetAccountItems(userId, accountId, startStr, endStr)
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.Opening = opening
+			b.List = list
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得提醒
+// @Description 取得站內收件匣最近 100 筆提醒與未讀數量。預算達到 80% 與 100%，或單筆支出超過提醒金額時產生，同一期間同一門檻只提醒一次
+// @Tags get
+// @Param unread query bool false "只取得未讀"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetAlertsResponse
+// @Router /api/alerts [get]
+func (s *Service) getAlerts(c *gin.Context) {
+	var b bundle.GetAlertsResponse
+	b.List = make([]bundle.Alert, 0)
+
+	userId := c.GetInt("user_id")
+	list, unread, err := s.d.GetAlerts(userId, c.Query("unread") == "true")
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = list
+		b.Unread = unread
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得提醒設定
+// @Description 取得單筆提醒金額與通知方式，金額為基準幣別的最小單位
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetAlertSettingResponse
+// @Router /api/alert/setting [get]
+func (s *Service) getAlertSetting(c *gin.Context) {
+	var b bundle.GetAlertSettingResponse
+
+	userId := c.GetInt("user_id")
+	setting, err := s.d.GetAlertSetting(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.AlertSetting = setting
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得全部類別
+// @Description 取得全部類別
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetAllTypeResponse
+// @Router /api/all [get]
+func (s *Service) getAll(c *gin.Context) {
+	var b bundle.GetAllTypeResponse
+	userId := c.GetInt("user_id")
+
+	all, err := s.d.GetAllType(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = all
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 備份帳號
+// @Description 匯出帳號全部資料，包含已刪除的類別、定期帳單的例外、基準幣別與提醒設定，每個區段附上 SHA-256 檢查碼
+// @Tags get
+// @Produce json
+// @Success 200 {object} archive.Archive
+// @Router /api/backup [get]
+func (s *Service) getBackup(c *gin.Context) {
+	var b bundle.ErrorResponse
+
+	userId := c.GetInt("user_id")
+	data, err := s.d.GetArchive(userId)
+	if err != nil {
+		b.Code = err.Error()
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	a, err := archive.New(data, time.Now())
+	if err != nil {
+		b.Code = bundle.CodeArchive
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	filename := fmt.Sprintf("daily_backup_%s.json", time.Now().Format(dateFormat))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Set("code", bundle.CodeOk)
+	c.JSON(http.StatusOK, a)
+}
+
+// @Summary 取得預算
+// @Description 取得預算，不包含類別已刪除的預算，金額為基準幣別的最小單位
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetBudgetsResponse
+// @Router /api/budgets [get]
+func (s *Service) getBudgets(c *gin.Context) {
+	var b bundle.GetBudgetsResponse
+	b.List = make([]bundle.Budget, 0)
+
+	userId := c.GetInt("user_id")
+	list, err := s.d.GetBudgets(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = list
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得預算狀態
+// @Description 取得日期所在期間的支出、剩餘與預估的期末支出，支出的計算方式與主類別總和相同，缺少匯率時回傳 E-032
+// @Tags get
+// @Param date query string false "日期，預設今天"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetBudgetStatusResponse
+// @Router /api/budgets/status [get]
+func (s *Service) getBudgetStatus(c *gin.Context) {
+	var b bundle.GetBudgetStatusResponse
+	b.List = make([]bundle.BudgetStatus, 0)
+
+	userId := c.GetInt("user_id")
+	date, err := time.Parse(dateFormat, c.DefaultQuery("date", time.Now().Format(dateFormat)))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		b.Date = date.Format(dateFormat)
+		b.List, b.Code = s.budgetStatus(userId, date)
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得基準幣別
+// @Description 取得基準幣別與小數位數，統計與帳戶餘額都換算成基準幣別的最小單位
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetCurrencyResponse
+// @Router /api/currency [get]
+func (s *Service) getCurrency(c *gin.Context) {
+	var b bundle.GetCurrencyResponse
+
+	userId := c.GetInt("user_id")
+	code, err := s.d.GetCurrency(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.Currency = code
+		b.Scale = currency.Scale(code)
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得重複帳單
+// @Description 取得子類別、金額、日期相同且名稱相似的帳單
+// @Tags get
+// @Param start		query string true "起始日期"
+// @Param end		query string true "結束日期"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetDuplicatesResponse
+// @Router /api/duplicates [get]
+func (s *Service) getDuplicates(c *gin.Context) {
+	var b bundle.GetDuplicatesResponse
+	b.List = make([]bundle.DuplicateGroup, 0)
+
+	userId := c.GetInt("user_id")
+	startStr, endStr, code := queryDateRange(c)
+	if code != bundle.CodeOk {
+		b.Code = code
+	} else {
+		items, err := s.d.GetDuplicateCandidates(userId, startStr, endStr)
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			for _, g := range duplicate.Group(items) {
+				b.List = append(b.List, bundle.DuplicateGroup{Items: g})
+			}
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 匯出帳單
+// @Description 依日期逐筆匯出帳單，包含主類別、子類別、收支、備註。省略日期時匯出全部，bom 為 true 時 CSV 開頭加上 UTF-8 BOM 讓 Excel 正確顯示中文。參數錯誤時回傳 bundle.ErrorResponse
+// @Tags get
+// @Param format	query string false "格式" Enums(csv, json, xlsx) default(csv)
+// @Param start		query string false "起始日期"
+// @Param end		query string false "結束日期"
+// @Param bom		query bool false "CSV 加上 UTF-8 BOM"
+// @Produce text/csv
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file
+// @Router /api/export [get]
+func (s *Service) getExport(c *gin.Context) {
+	var b bundle.ErrorResponse
+
+	userId := c.GetInt("user_id")
+	format := c.DefaultQuery("format", export.FormatCsv)
+	bom, _ := strconv.ParseBool(c.Query("bom"))
+
+	startStr, endStr, code := queryOptionalRange(c)
+	if code != bundle.CodeOk {
+		b.Code = code
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	// 確認格式後才開始輸出
+	if _, err := export.NewWriter(io.Discard, format, false); err != nil {
+		b.Code = bundle.CodeFormat
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	filename := fmt.Sprintf("daily_%s_%s.%s", startStr, endStr, format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(c.Writer, format, bom)
+	if err == nil {
+		err = s.d.EachItem(userId, startStr, endStr, w.Write)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+
+	// 已經開始輸出，只能記錄錯誤
+	b.Code = bundle.CodeOk
+	if err != nil {
+		b.Code = bundle.CodeDb
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "getExport",
+			"UserId": userId,
+			"Error":  err.Error(),
+		}).Error("Api")
+	}
+
+	c.Set("code", b.Code)
+}
+
+// @Summary 取得單一項目
+// @Description 取得單一項目
+// @Tags get
+// @Param item_id path int true "項目編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetItemResponse
+// @Router /api/item/{item_id} [get]
+func (s *Service) getItem(c *gin.Context) {
+	var b bundle.GetItemResponse
+
+	userId := c.GetInt("user_id")
+	itemId, err := strconv.Atoi(c.Param("item_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		item, err := s.d.GetItem(userId, itemId)
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.Item = item
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 由日期取得預覽項目
+// @Description 由日期取得預覽項目
+// @Tags get
+// @Param start		query string true "起始日期"
+// @Param end		query string true "結束日期"
+// @Param content	query string false "關鍵字"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetItemsResponse
+// @Router /api/items [get]
+func (s *Service) getItems(c *gin.Context) {
+	var b bundle.GetItemsResponse
+	b.List = make([]bundle.PreviewItem, 0)
+
+	userId := c.GetInt("user_id")
+	startStr := c.Query("start")
+	startDate, err := time.Parse(dateFormat, startStr)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	endStr := c.Query("end")
+	endDate, err := time.Parse(dateFormat, endStr)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	// 日期錯誤
+	if startDate.After(endDate) {
+		b.Code = bundle.CodeDate
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	// 查詢區間
+	shiftTime := startDate.AddDate(dateRange, 0, 0)
+	if endDate.After(shiftTime) {
+		b.Code = bundle.CodeDate
+		c.Set("code", b.Code)
+		c.JSON(http.StatusOK, b)
+		return
+	}
+
+	var items []bundle.PreviewItem
+	content := c.Query("content")
+	if len(content) == 0 {
+		items, err = s.d.GetPerviewItemsByDate(userId, startStr, endStr)
+	} else {
+		items, err = s.d.LikeName(userId, content, startStr, endStr)
+	}
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = items
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+func (s *Service) getLog(c *gin.Context) {
+	c.String(http.StatusOK, log.LogHistory.String())
+}
+
+// @Summary 取得全部主類別
+// @Description 取得全部主類別
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetMainTypeResponse
+// @Router /api/main [get]
+func (s *Service) getMainType(c *gin.Context) {
+	userId := c.GetInt("user_id")
+	main, err := s.d.GetMainType(userId)
+
+	var b bundle.GetMainTypeResponse
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = main
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得匯率
+// @Description 取得目前基準幣別的匯率，未指定幣別時取得全部幣別，未指定日期時取得全部
+// @Tags get
+// @Param currency	query string false "幣別"
+// @Param start		query string false "起始日期"
+// @Param end		query string false "結束日期"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetRatesResponse
+// @Router /api/rates [get]
+func (s *Service) getRates(c *gin.Context) {
+	var b bundle.GetRatesResponse
+	b.List = make([]bundle.Rate, 0)
+
+	userId := c.GetInt("user_id")
+	startStr, endStr, code := queryOptionalRange(c)
+
+	if code != bundle.CodeOk {
+		b.Code = code
+	} else if cur, ok := checkCurrency(c.Query("currency")); !ok {
+		b.Code = bundle.CodeCurrency
+	} else {
+		list, err := s.d.GetRates(userId, cur, startStr, endStr)
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = list
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得定期帳單
+// @Description 取得定期帳單範本，materialized 為已建立帳單的最後一天
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetRecurringResponse
+// @Router /api/recurring [get]
+func (s *Service) getRecurring(c *gin.Context) {
+	var b bundle.GetRecurringResponse
+	b.List = make([]bundle.Recurring, 0)
+
+	userId := c.GetInt("user_id")
+	list, err := s.d.GetRecurring(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = list
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得規則
+// @Description 取得名稱對應規則，依比對順序排列
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetRulesResponse
+// @Router /api/rule [get]
+func (s *Service) getRules(c *gin.Context) {
+	var b bundle.GetRulesResponse
+	b.List = make([]bundle.Rule, 0)
+
+	userId := c.GetInt("user_id")
+	list, err := s.d.GetRules(userId)
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = list
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得這個月的主類別總和
+// @Description 取得這個月的主類別總和，外幣帳單依帳單日期的匯率換算成基準幣別，缺少匯率時回傳 E-032
+// @Tags get
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetSumByMainTypeResponse
+// @Router /api/sum/main [get]
+func (s *Service) getSumByMainType(c *gin.Context) {
+	var b bundle.GetSumByMainTypeResponse
+	userId := c.GetInt("user_id")
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, -1)
+	m, err := s.d.GetSumByMainType(userId, startDate.Format(dateFormat), endDate.Format(dateFormat))
+
+	if err != nil {
+		b.Code = err.Error()
+	} else {
+		b.Code = bundle.CodeOk
+		b.List = m
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得前幾個月收支總和
+// @Description 取得前幾個月收支總和，外幣帳單依帳單日期的匯率換算成基準幣別，缺少匯率時回傳 E-032
+// @Tags get
+// @Param count path int true "月份數量(最多12)"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetSpendByMonthlyResponse
+// @Router /api/spend/month/{count} [get]
+func (s *Service) getSpendByLastMonthly(c *gin.Context) {
+	var b bundle.GetSpendByMonthlyResponse
+	userId := c.GetInt("user_id")
+
+	count, err := strconv.Atoi(c.Param("count"))
+	if count > 12 {
+		count = 12
+	}
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		now := time.Now()
+		tmpDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		// 月底
+		endDate := tmpDate.AddDate(0, 1, -1)
+		// 月初
+		start := tmpDate.AddDate(0, -count, 0)
+		m, err := s.d.GetSumByMonth(userId, start.Format(dateFormat), endDate.Format(dateFormat))
+
+		l := len(m)
+		if l != count {
+			list := make([]bundle.Monthly, count)
+			index := 0
+			for i := 0; i < count; i++ {
+				list[i].Date = start.AddDate(0, i, 0)
+				if index < l && list[i].Date.Equal(m[index].Date) {
+					list[i].Sum = m[index].Sum
+					index++
+				}
+			}
+
+			m = list
+		}
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = m
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得全部子類別
+// @Description 取得全部子類別
+// @Tags get
+// @Param main_id path int true "主類別編號"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetSubTypeResponse
+// @Router /api/sub/{main_id} [get]
+func (s *Service) getSubType(c *gin.Context) {
+	var b bundle.GetSubTypeResponse
+	userId := c.GetInt("user_id")
+	mainId, err := strconv.Atoi(c.Param("main_id"))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		sub, err := s.d.GetSubType(userId, mainId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = sub
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得類別建議
+// @Description 依過去的帳單預測名稱所屬的子類別，附上信心度
+// @Tags get
+// @Param name		query string true "項目名稱"
+// @Param remark	query string false "備註"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetCategorySuggestionResponse
+// @Router /api/suggest/category [get]
+func (s *Service) getSuggestCategory(c *gin.Context) {
+	var b bundle.GetCategorySuggestionResponse
+	b.List = make([]bundle.CategorySuggestion, 0)
+
+	userId := c.GetInt("user_id")
+	name := c.Query("name")
+
+	if len(name) == 0 {
+		b.Code = bundle.CodeEmptyContent
+	} else {
+		cl, err := s.nb.Get(userId, func() ([]bundle.Item, error) {
+			return s.d.GetAllItems(userId)
+		})
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = cl.Predict(name, c.Query("remark"), suggestLimit)
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得名稱建議
+// @Description 依前綴取得常用的項目名稱，附上最後一次使用的子類別與金額
+// @Tags get
+// @Param prefix	query string true "名稱前綴，可用拼音或注音"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetNameSuggestionResponse
+// @Router /api/suggest/name [get]
+func (s *Service) getSuggestName(c *gin.Context) {
+	var b bundle.GetNameSuggestionResponse
+	b.List = make([]bundle.NameSuggestion, 0)
+
+	userId := c.GetInt("user_id")
+	prefix := c.Query("prefix")
+
+	if len(prefix) == 0 {
+		b.Code = bundle.CodeEmptyContent
+	} else {
+		since := time.Now().AddDate(0, 0, -suggestDays).Format(dateFormat)
+		list, err := s.d.SuggestName(userId, prefix, since, suggestLimit)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = list
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得轉帳
+// @Description 取得日期區間內的轉帳，未指定日期時取得全部
+// @Tags get
+// @Param start	query string false "起始日期"
+// @Param end	query string false "結束日期"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetTransfersResponse
+// @Router /api/transfers [get]
+func (s *Service) getTransfers(c *gin.Context) {
+	var b bundle.GetTransfersResponse
+	b.List = make([]bundle.Transfer, 0)
+
+	userId := c.GetInt("user_id")
+	startStr, endStr, code := queryOptionalRange(c)
+
+	if code != bundle.CodeOk {
+		b.Code = code
+	} else {
+		list, err := s.d.GetTransfers(userId, startStr, endStr)
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.List = list
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 取得即將發生的定期帳單
+// @Description 取得今天到 end 之間還沒建立帳單的定期帳單，依日期排列，已套用略過與修改，金額為幣別的最小單位
+// @Tags get
+// @Param end query string false "結束日期，預設 30 天後"
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetUpcomingResponse
+// @Router /api/recurring/upcoming [get]
+func (s *Service) getUpcoming(c *gin.Context) {
+	var b bundle.GetUpcomingResponse
+	b.List = make([]bundle.Occurrence, 0)
+
+	userId := c.GetInt("user_id")
+	start := today()
+	end, err := time.Parse(dateFormat, c.DefaultQuery("end", start.AddDate(0, 0, upcomingDays).Format(dateFormat)))
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else if end.Before(start) || end.After(start.AddDate(dateRange, 0, 0)) {
+		b.Code = bundle.CodeDate
+	} else {
+		b.List, b.Code = s.upcoming(userId, start, end)
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 匯入 CSV
+// @Description 依欄位對應匯入帳單，金額可以有小數但不可超過幣別的小數位數，每一行的錯誤記錄在 rows[].error。與現有帳單的子類別、金額、日期相同且名稱相近的資料標記 rows[].skipped 並略過，force 時不檢查。dry_run 時只回傳預覽，否則有任何一筆錯誤就全部不寫入並回傳 E-025
+// @Tags create
+// @Accept multipart/form-data
+// @Produce json
+// @Param file		formData file true "CSV 檔案"
+// @Param mapping	formData string true "欄位對應，bundle.CsvMapping 的 JSON"
+// @Param dry_run	formData bool false "只預覽不寫入"
+// @Param force		formData bool false "不檢查重複"
+// @Success 200 {object} bundle.ImportResponse
+// @Router /api/import/csv [post]
+func (s *Service) importCsv(c *gin.Context) {
+	var b bundle.ImportResponse
+	var m bundle.CsvMapping
+
+	fh, err := c.FormFile("file")
+	if err == nil {
+		err = json.Unmarshal([]byte(c.PostForm("mapping")), &m)
+	}
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+		force, _ := strconv.ParseBool(c.PostForm("force"))
+
+		rows, code := s.parseCsv(userId, fh, m)
+		if code != bundle.CodeOk {
+			b.Code = code
+		} else {
+			s.importRows(userId, m.AccountId, rows, dryRun, force, &b)
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "importCsv",
+			"UserId": userId,
+			"DryRun": dryRun,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 匯入 OFX
+// @Description 匯入銀行或信用卡 OFX 對帳單，依規則、預設子類別決定類別。已匯入過的交易 (FITID) 略過，找不到類別的不匯入
+// @Tags create
+// @Accept multipart/form-data
+// @Produce json
+// @Param file		formData file true "OFX 檔案"
+// @Param sub_id		formData int false "沒有符合規則時使用的子類別"
+// @Param account_id	formData int false "匯入的帳戶，預設為預設帳戶"
+// @Param dry_run		formData bool false "只預覽不寫入"
+// @Success 200 {object} bundle.StatementImportResponse
+// @Router /api/import/ofx [post]
+func (s *Service) importOfx(c *gin.Context) {
+	s.importStatementFile(c, "importOfx", importer.ParseOfx)
+}
+
+// @Summary 匯入 QIF
+// @Description 匯入 QIF 對帳單，依規則、檔案內的類別名稱、預設子類別決定類別。已匯入過的交易略過，找不到類別的不匯入
+// @Tags create
+// @Accept multipart/form-data
+// @Produce json
+// @Param file			formData file true "QIF 檔案"
+// @Param date_order	formData string false "日期順序" Enums(mdy, dmy, ymd) default(mdy)
+// @Param sub_id		formData int false "沒有符合規則時使用的子類別"
+// @Param account_id	formData int false "匯入的帳戶，預設為預設帳戶"
+// @Param dry_run		formData bool false "只預覽不寫入"
+// @Success 200 {object} bundle.StatementImportResponse
+// @Router /api/import/qif [post]
+func (s *Service) importQif(c *gin.Context) {
+	order := c.PostForm("date_order")
+	s.importStatementFile(c, "importQif", func(r io.Reader, base string) ([]bundle.ImportRow, error) {
+		return importer.ParseQif(r, order, base)
+	})
+}
+
+// 匯入上傳的對帳單
+func (s *Service) importStatementFile(c *gin.Context, method string, parse func(io.Reader, string) ([]bundle.ImportRow, error)) {
+	var b bundle.StatementImportResponse
+	b.Rows = make([]bundle.ImportRow, 0)
+
+	fh, err := c.FormFile("file")
+
+	subId := 0
+	if err == nil && len(c.PostForm("sub_id")) > 0 {
+		subId, err = strconv.Atoi(c.PostForm("sub_id"))
+	}
+
+	accountId := 0
+	if err == nil && len(c.PostForm("account_id")) > 0 {
+		accountId, err = strconv.Atoi(c.PostForm("account_id"))
+	}
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+		base, err := s.d.GetCurrency(userId)
+
+		if err != nil {
+			b.Code = err.Error()
+		} else if rows, code := openStatement(fh, base, parse); code != bundle.CodeOk {
+			b.Code = code
+		} else {
+			s.importStatement(userId, accountId, rows, subId, dryRun, &b)
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": method,
+			"UserId": userId,
+			"DryRun": dryRun,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
 
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 由日期取得預覽項目
-// @Description 由日期取得預覽項目
+// @Summary 匯入匯率
+// @Description 匯入匯率 CSV，第一行為標題 date,currency,rate，同一天已有匯率時覆蓋。
+// @Description 格式錯誤時全部不寫入並回傳 E-025，原因記錄在 error
+// @Tags create
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV 檔案"
+// @Success 200 {object} bundle.ImportRatesResponse
+// @Router /api/import/rates [post]
+func (s *Service) importRates(c *gin.Context) {
+	var b bundle.ImportRatesResponse
+
+	fh, err := c.FormFile("file")
+
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+
+		rates, code, reason := openRates(fh)
+		if code != bundle.CodeOk {
+			b.Code = code
+			b.Error = reason
+		} else if _, err := s.d.InsertRates(userId, rates); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			b.Count = len(rates)
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "importRates",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 還原備份
+// @Description 還原 /api/backup 的備份檔，編號重新對應到目前的帳號，全部成功才寫入。mode 為 replace 時先清除目前的資料，merge 時沿用同名的類別，略過交易編號重複或內容與既有帳單相同的帳單，保留目前的基準幣別與提醒設定，備份的基準幣別不同時回傳 E-038。舊版備份的金額依幣別換算成最小單位。檢查碼不符回傳 E-026，版本不相容回傳 E-027
+// @Tags create
+// @Accept multipart/form-data
+// @Produce json
+// @Param file	formData file true "備份檔"
+// @Param mode	formData string false "還原方式" Enums(merge, replace) default(merge)
+// @Success 200 {object} bundle.RestoreResponse
+// @Router /api/restore [post]
+func (s *Service) restore(c *gin.Context) {
+	var b bundle.RestoreResponse
+
+	fh, err := c.FormFile("file")
+	mode := c.DefaultPostForm("mode", restoreMerge)
+
+	if err != nil || (mode != restoreMerge && mode != restoreReplace) {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		b.Code = s.restoreArchive(userId, fh, mode == restoreReplace, &b.RestoreResult)
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "restore",
+			"UserId": userId,
+			"Mode":   mode,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 模糊搜尋名稱
+// @Description 模糊搜尋名稱，依序包含關鍵字每個字的名稱都會找到，可用拼音、注音或首字母搜尋 (例如 jcc、ㄐㄔㄔ)。
+// @Description 由資料庫索引找出最多 1000 筆候選，全部依相關程度排序後再分頁；只有字形相似 (例如打錯字) 的帳單沒有 score，排在最後。
+// @Description 候選超過 1000 筆時 truncated 為 true，請縮小日期範圍或使用更長的關鍵字
 // @Tags get
 // @Param start		query string true "起始日期"
 // @Param end		query string true "結束日期"
-// @Param content	query string false "關鍵字"
+// @Param content	query string true "關鍵字"
+// @Param page		query int false "頁數" default(1)
+// @Param size		query int false "每頁筆數" default(50)
 // @Accept json
 // @Produce json
-// @Router /api/items [get]
-func (s *Service) getItems(c *gin.Context) {
+// @Success 200 {object} bundle.GetItemsResponse
+// @Router /api/search/name [get]
+func (s *Service) searchByName(c *gin.Context) {
 	var b bundle.GetItemsResponse
 	b.List = make([]bundle.PreviewItem, 0)
 
 	userId := c.GetInt("user_id")
-	startStr := c.Query("start")
-	startDate, err := time.Parse(dateFormat, startStr)
-	if err != nil {
-		b.Code = bundle.CodeFormat
+	startStr, endStr, code := queryDateRange(c)
+	if code != bundle.CodeOk {
+		b.Code = code
+		c.Set("code", b.Code)
 		c.JSON(http.StatusOK, b)
 		return
 	}
 
-	endStr := c.Query("end")
-	endDate, err := time.Parse(dateFormat, endStr)
-	if err != nil {
+	limit, offset, ok := queryPage(c)
+
+	content := c.Query("content")
+	if !ok {
 		b.Code = bundle.CodeFormat
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
-		return
+	} else if len(content) == 0 {
+		b.Code = bundle.CodeEmptyContent
+	} else {
+		items, err := s.d.SearchItems(userId, bundle.SearchQuery{
+			Keyword: content,
+			Mode:    bundle.SearchModeFuzzy,
+			Name:    true,
+			Start:   startStr,
+			End:     endStr,
+			Limit:   searchCandidates + 1,
+		})
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			items, b.Truncated = limitCandidates(items)
+			b.List = paginate(rankByName(items, content), limit, offset)
+		}
 	}
 
-	// 日期錯誤
-	if startDate.After(endDate) {
-		b.Code = bundle.CodeDate
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 搜尋備註
+// @Description 搜尋備註，可同時搜尋名稱。fuzzy 依序包含關鍵字每個字即符合，substring 為包含，exact 為完全相同，都先統一全形、繁簡與大小寫。
+// @Description 最多 1000 筆候選全部依相關程度排序後再分頁，候選超過 1000 筆時 truncated 為 true
+// @Tags get
+// @Param start		query string true "起始日期"
+// @Param end		query string true "結束日期"
+// @Param content	query string true "關鍵字"
+// @Param mode		query string false "搜尋模式" Enums(fuzzy, substring, exact) default(fuzzy)
+// @Param name		query bool false "同時搜尋名稱"
+// @Param page		query int false "頁數" default(1)
+// @Param size		query int false "每頁筆數" default(50)
+// @Accept json
+// @Produce json
+// @Success 200 {object} bundle.GetItemsResponse
+// @Router /api/search/remake [get]
+func (s *Service) searchByRemark(c *gin.Context) {
+	var b bundle.GetItemsResponse
+	b.List = make([]bundle.PreviewItem, 0)
+
+	userId := c.GetInt("user_id")
+	startStr, endStr, code := queryDateRange(c)
+	if code != bundle.CodeOk {
+		b.Code = code
 		c.Set("code", b.Code)
 		c.JSON(http.StatusOK, b)
 		return
 	}
 
-	// 查詢區間
-	shiftTime := startDate.AddDate(dateRange, 0, 0)
-	if endDate.After(shiftTime) {
-		b.Code = bundle.CodeDate
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
-		return
+	mode := c.DefaultQuery("mode", bundle.SearchModeFuzzy)
+
+	var match func(source, target string, t transform.Transformer) (fuzzy.Match, bool)
+	switch mode {
+	case bundle.SearchModeFuzzy:
+		match = fuzzy.FuzzyMatch
+	case bundle.SearchModeSubstring:
+		match = fuzzy.ContainsMatch
+	case bundle.SearchModeExact:
+		match = fuzzy.EqualMatch
 	}
 
-	var items []bundle.PreviewItem
+	withName, err := strconv.ParseBool(c.DefaultQuery("name", "false"))
+	limit, offset, ok := queryPage(c)
+
 	content := c.Query("content")
-	if len(content) == 0 {
-		items, err = s.d.GetPerviewItemsByDate(userId, startStr, endStr)
+	if match == nil || err != nil || !ok {
+		b.Code = bundle.CodeFormat
+	} else if len(content) == 0 {
+		b.Code = bundle.CodeEmptyContent
 	} else {
-		items, err = s.d.LikeName(userId, content, startStr, endStr)
+		items, err := s.d.SearchItems(userId, bundle.SearchQuery{
+			Keyword: content,
+			Mode:    mode,
+			Name:    withName,
+			Remark:  true,
+			Start:   startStr,
+			End:     endStr,
+			Limit:   searchCandidates + 1,
+		})
+		if err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+			items, b.Truncated = limitCandidates(items)
+			b.List = paginate(rankByRemark(items, content, match, withName), limit, offset)
+		}
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// 依名稱的模糊比對排序
+//
+// 只有 pg_trgm 相似、模糊比對不符合的候選 (例如打錯字) 保留在最後，沒有分數
+func rankByName(items []bundle.PreviewItem, content string) []bundle.PreviewItem {
+	list := make([]bundle.PreviewItem, 0, len(items))
+
+	n := newNameMatcher()
+	for _, item := range items {
+		if m, ok := n.match(item.Name, content); ok {
+			item.Score = &m.Score
+			item.NameHighlight = highlights(m)
+		}
+		list = append(list, item)
+	}
+
+	sortByScore(list)
+
+	return list
+}
+
+// 依備註的比對結果排序，同時搜尋名稱時分數取較高者
+func rankByRemark(items []bundle.PreviewItem, content string,
+	match func(source, target string, t transform.Transformer) (fuzzy.Match, bool), withName bool) []bundle.PreviewItem {
+	list := make([]bundle.PreviewItem, 0, len(items))
+
+	t := transformer.NewChineseFold()
+	for _, item := range items {
+		if m, ok := match(item.Remark, content, t); ok {
+			item.Score = &m.Score
+			item.RemarkHighlight = highlights(m)
+		}
+
+		if withName {
+			if m, ok := match(item.Name, content, t); ok {
+				if item.Score == nil || m.Score > *item.Score {
+					item.Score = &m.Score
+				}
+				item.NameHighlight = highlights(m)
+			}
+		}
+
+		list = append(list, item)
+	}
+
+	sortByScore(list)
+
+	return list
+}
+
+// 候選多查一筆判斷是否超過上限，超過時去掉相似度最低的那筆
+func limitCandidates(items []bundle.PreviewItem) ([]bundle.PreviewItem, bool) {
+	if len(items) > searchCandidates {
+		return items[:searchCandidates], true
+	}
+
+	return items, false
+}
+
+// 排序後的分頁
+func paginate(items []bundle.PreviewItem, limit, offset int) []bundle.PreviewItem {
+	if offset >= len(items) {
+		return make([]bundle.PreviewItem, 0)
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end]
+}
+
+// 名稱比對，原文找不到時改用拼音或注音
+type nameMatcher struct {
+	fold      transform.Transformer
+	phonetics []phonetic
+}
+
+type phonetic struct {
+	p        *transformer.Phonetic
+	oneToOne bool // 每個字轉成一個字，符合位置可對應回原文
+}
+
+func newNameMatcher() *nameMatcher {
+	return &nameMatcher{
+		fold: transformer.NewChineseFold(),
+		phonetics: []phonetic{
+			{p: transformer.PinyinInitials, oneToOne: true},
+			{p: transformer.ZhuyinInitials, oneToOne: true},
+			{p: transformer.Pinyin},
+			{p: transformer.Zhuyin},
+		},
+	}
+}
+
+func (n *nameMatcher) match(name, content string) (fuzzy.Match, bool) {
+	if m, ok := fuzzy.FuzzyMatch(name, content, n.fold); ok {
+		return m, true
 	}
 
+	folded, _, err := transform.String(transformer.Fold, name)
 	if err != nil {
-		b.Code = err.Error()
-	} else {
-		b.Code = bundle.CodeOk
-		b.List = items
+		folded = name
 	}
 
-	c.Set("code", b.Code)
-	c.JSON(http.StatusOK, b)
+	// 多音字的每種讀音都比對，取分數最高的
+	var best fuzzy.Match
+	found := false
+	for _, p := range n.phonetics {
+		for _, v := range p.p.Variants(folded) {
+			m, ok := fuzzy.FuzzyMatch(v, content, transformer.Fold)
+			if !ok || (found && m.Score <= best.Score) {
+				continue
+			}
+
+			if !p.oneToOne {
+				m.Positions = nil
+			}
+
+			best = m
+			found = true
+		}
+	}
+
+	return best, found
 }
 
-func (s *Service) getLog(c *gin.Context) {
-	c.String(http.StatusOK, log.LogHistory.String())
+// 依分數排序，同分維持原本順序，沒有分數的排在最後
+func sortByScore(items []bundle.PreviewItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score == nil || items[j].Score == nil {
+			return items[j].Score == nil && items[i].Score != nil
+		}
+		return *items[i].Score > *items[j].Score
+	})
 }
 
-// @Summary 取得全部主類別
-// @Description 取得全部主類別
-// @Tags get
-// @Accept json
-// @Produce json
-// @Router /api/main [get]
-func (s *Service) getMainType(c *gin.Context) {
-	userId := c.GetInt("user_id")
-	main, err := s.d.GetMainType(userId)
+// 轉換符合區間
+func highlights(m fuzzy.Match) []bundle.Highlight {
+	ranges := m.Ranges()
+	h := make([]bundle.Highlight, len(ranges))
+	for i, r := range ranges {
+		h[i] = bundle.Highlight{Start: r[0], End: r[1]}
+	}
 
-	var b bundle.GetMainTypeResponse
+	return h
+}
+
+// 分頁，page 由 1 開始
+func queryPage(c *gin.Context) (limit int, offset int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, false
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(pageSize)))
+	if err != nil || size < 1 {
+		return 0, 0, false
+	}
+
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	return size, (page - 1) * size, true
+}
+
+// 檢查查詢日期區間
+func queryDateRange(c *gin.Context) (string, string, string) {
+	startStr := c.Query("start")
+	startDate, err := time.Parse(dateFormat, startStr)
 	if err != nil {
-		b.Code = err.Error()
-	} else {
-		b.Code = bundle.CodeOk
-		b.List = main
+		return "", "", bundle.CodeFormat
 	}
 
-	c.Set("code", b.Code)
-	c.JSON(http.StatusOK, b)
+	endStr := c.Query("end")
+	endDate, err := time.Parse(dateFormat, endStr)
+	if err != nil {
+		return "", "", bundle.CodeFormat
+	}
+
+	// 日期錯誤
+	if startDate.After(endDate) {
+		return "", "", bundle.CodeDate
+	}
+
+	// 查詢區間
+	shiftTime := startDate.AddDate(dateRange, 0, 0)
+	if endDate.After(shiftTime) {
+		return "", "", bundle.CodeDate
+	}
+
+	return startStr, endStr, bundle.CodeOk
 }
 
-// @Summary 取得這個月的主類別總和
-// @Description 取得這個月的主類別總和
-// @Tags get
+// 日期區間，省略時為全部，不限制區間長度
+func queryOptionalRange(c *gin.Context) (string, string, string) {
+	startStr := c.DefaultQuery("start", exportStart)
+	endStr := c.DefaultQuery("end", exportEnd)
+
+	startDate, err := time.Parse(dateFormat, startStr)
+	if err != nil {
+		return "", "", bundle.CodeFormat
+	}
+
+	endDate, err := time.Parse(dateFormat, endStr)
+	if err != nil {
+		return "", "", bundle.CodeFormat
+	}
+
+	if startDate.After(endDate) {
+		return "", "", bundle.CodeDate
+	}
+
+	return startStr, endStr, bundle.CodeOk
+}
+
+// @Summary 提醒已讀
+// @Description 將提醒標為已讀，alert_ids 空白時全部已讀
+// @Tags update
 // @Accept json
 // @Produce json
-// @Router /api/sum/main [get]
-func (s *Service) getSumByMainType(c *gin.Context) {
-	var b bundle.GetSumByMainTypeResponse
-	userId := c.GetInt("user_id")
+// @Param Body body bundle.ReadAlertsRequest true "已讀"
+// @Success 200 {object} bundle.ReadAlertsResponse
+// @Router /api/alerts/read [put]
+func (s *Service) readAlerts(c *gin.Context) {
+	var b bundle.ReadAlertsResponse
+	var read bundle.ReadAlertsRequest
+
+	err := c.BindJSON(&read)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
 
-	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, -1)
-	m, err := s.d.GetSumByMainType(userId, startDate.Format(dateFormat), endDate.Format(dateFormat))
+		if err := s.d.ReadAlerts(userId, read.AlertIds); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
 
-	if err != nil {
-		b.Code = err.Error()
-	} else {
-		b.Code = bundle.CodeOk
-		b.List = m
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "readAlerts",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 取得前幾個月收支總和
-// @Description 取得前幾個月收支總和
-// @Tags get
+// @Summary 修改帳戶
+// @Description 修改帳戶名稱、類型與期初餘額
+// @Tags update
 // @Accept json
 // @Produce json
-// @Router /api/spend/month/{count} [get]
-func (s *Service) getSpendByLastMonthly(c *gin.Context) {
-	var b bundle.GetSpendByMonthlyResponse
-	userId := c.GetInt("user_id")
-
-	count, err := strconv.Atoi(c.Param("count"))
-	if count > 12 {
-		count = 12
-	}
+// @Param Body body bundle.UpdateAccountRequest true "修改"
+// @Success 200 {object} bundle.UpdateAccountResponse
+// @Router /api/account [put]
+func (s *Service) updateAccount(c *gin.Context) {
+	var b bundle.UpdateAccountResponse
+	var update bundle.UpdateAccountRequest
 
+	err := c.BindJSON(&update)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		now := time.Now()
-		tmpDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-		// 月底
-		endDate := tmpDate.AddDate(0, 1, -1)
-		// 月初
-		start := tmpDate.AddDate(0, -count, 0)
-		m, err := s.d.GetSumByMonth(userId, start.Format(dateFormat), endDate.Format(dateFormat))
-
-		l := len(m)
-		if l != count {
-			list := make([]bundle.Monthly, count)
-			index := 0
-			for i := 0; i < count; i++ {
-				list[i].Date = start.AddDate(0, i, 0)
-				if index < l && list[i].Date.Equal(m[index].Date) {
-					list[i].Sum = m[index].Sum
-					index++
-				}
-			}
-
-			m = list
-		}
-
-		if err != nil {
+		userId := c.GetInt("user_id")
+		accountType, ok := checkAccountType(update.Type)
+		opening, code := s.baseAmount(userId, update.Opening)
+
+		if !ok {
+			b.Code = bundle.CodeAccount
+		} else if code != bundle.CodeOk {
+			b.Code = code
+		} else if err := s.d.UpdateAccount(userId, update.AccountId, update.Name, accountType, opening); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
-			b.List = m
 		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateAccount",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 取得全部子類別
-// @Description 取得全部子類別
-// @Tags get
-// @Param main_id path int true "主類別編號"
+// @Summary 修改提醒設定
+// @Description 修改單筆提醒金額與通知方式。bill_threshold 為基準幣別的金額，0 表示不提醒；webhook 必須是 http 或 https 網址且不可指向本機或內部網路，會以 JSON POST 提醒內容；
+// @Description email 需要伺服器設定寄信伺服器。站內收件匣一定會收到提醒，格式錯誤回傳 E-036
+// @Tags update
 // @Accept json
 // @Produce json
-// @Router /api/sub/{main_id} [get]
-func (s *Service) getSubType(c *gin.Context) {
-	var b bundle.GetSubTypeResponse
-	userId := c.GetInt("user_id")
-	mainId, err := strconv.Atoi(c.Param("main_id"))
+// @Param Body body bundle.UpdateAlertSettingRequest true "修改"
+// @Success 200 {object} bundle.UpdateAlertSettingResponse
+// @Router /api/alert/setting [put]
+func (s *Service) updateAlertSetting(c *gin.Context) {
+	var b bundle.UpdateAlertSettingResponse
+	var update bundle.UpdateAlertSettingRequest
 
+	err := c.BindJSON(&update)
 	if err != nil {
 		b.Code = bundle.CodeFormat
 	} else {
-		sub, err := s.d.GetSubType(userId, mainId)
+		userId := c.GetInt("user_id")
 
-		if err != nil {
+		if setting, code := s.newAlertSetting(userId, update); code != bundle.CodeOk {
+			b.Code = code
+		} else if err := s.d.UpdateAlertSetting(userId, setting); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
-			b.List = sub
 		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateAlertSetting",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
 
-// @Summary 模糊搜尋名稱
-// @Description 模糊搜尋名稱
-// @Tags get
-// @Param start		query string true "起始日期"
-// @Param end		query string true "結束日期"
-// @Param content	query string true "關鍵字"
+// @Summary 修改預算
+// @Description 修改預算，欄位規則與建立預算相同
+// @Tags update
 // @Accept json
 // @Produce json
-// @Router /api/search/name [get]
-func (s *Service) searchByName(c *gin.Context) {
-	var b bundle.GetItemsResponse
-	b.List = make([]bundle.PreviewItem, 0)
+// @Param Body body bundle.UpdateBudgetRequest true "修改"
+// @Success 200 {object} bundle.UpdateBudgetResponse
+// @Router /api/budget [put]
+func (s *Service) updateBudget(c *gin.Context) {
+	var b bundle.UpdateBudgetResponse
+	var update bundle.UpdateBudgetRequest
 
-	userId := c.GetInt("user_id")
-	startStr := c.Query("start")
-	startDate, err := time.Parse(dateFormat, startStr)
+	err := c.BindJSON(&update)
 	if err != nil {
 		b.Code = bundle.CodeFormat
-		c.JSON(http.StatusOK, b)
-		return
-	}
+	} else {
+		userId := c.GetInt("user_id")
 
-	endStr := c.Query("end")
-	endDate, err := time.Parse(dateFormat, endStr)
-	if err != nil {
-		b.Code = bundle.CodeFormat
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
-		return
-	}
+		if bg, code := s.newBudget(userId, update.CreateBudgetRequest); code != bundle.CodeOk {
+			b.Code = code
+		} else {
+			bg.Id = update.BudgetId
+			if err := s.d.UpdateBudget(userId, bg); err != nil {
+				b.Code = err.Error()
+			} else {
+				b.Code = bundle.CodeOk
+			}
+		}
 
-	// 日期錯誤
-	if startDate.After(endDate) {
-		b.Code = bundle.CodeDate
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
-		return
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateBudget",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
-	// 查詢區間
-	shiftTime := startDate.AddDate(dateRange, 0, 0)
-	if endDate.After(shiftTime) {
-		b.Code = bundle.CodeDate
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
-		return
-	}
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
 
-	var items []bundle.PreviewItem
-	content := c.Query("content")
-	if len(content) == 0 {
-		b.Code = bundle.CodeEmptyContent
-		c.Set("code", b.Code)
-		c.JSON(http.StatusOK, b)
+// @Summary 修改基準幣別
+// @Description 修改基準幣別，統計改用新基準幣別的匯率換算。帳戶期初餘額、轉帳、預算與單筆提醒金額以基準幣別記錄，還有這些金額時回傳 E-038
+// @Tags update
+// @Accept json
+// @Produce json
+// @Param Body body bundle.UpdateCurrencyRequest true "修改"
+// @Success 200 {object} bundle.UpdateCurrencyResponse
+// @Router /api/currency [put]
+func (s *Service) updateCurrency(c *gin.Context) {
+	var b bundle.UpdateCurrencyResponse
+	var update bundle.UpdateCurrencyRequest
+
+	err := c.BindJSON(&update)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else if code, err := currency.Normalize(update.Currency); err != nil {
+		b.Code = bundle.CodeCurrency
 	} else {
-		items, err = s.d.GetPerviewItemsByDate(userId, startStr, endStr)
+		userId := c.GetInt("user_id")
+		err := s.d.UpdateCurrency(userId, code)
+
 		if err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
-
-			// 過濾內文
-			for _, item := range items {
-				if fuzzy.FuzzySearch(item.Name, content, nil) {
-					b.List = append(b.List, item)
-				}
-			}
 		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateCurrency",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
 	}
 
 	c.Set("code", b.Code)
@@ -645,27 +2430,39 @@ func (s *Service) searchByName(c *gin.Context) {
 }
 
 // @Summary 修改項目
-// @Description 修改項目
+// @Description 修改項目，currency 空白時不變更，price、refund 與 refund_of 的規則與建立項目相同
 // @Tags update
 // @Accept json
 // @Produce json
 // @Param Body body bundle.UpdateItemRequest true "修改"
+// @Success 200 {object} bundle.UpdateItemResponse
 // @Router /api/item [put]
 func (s *Service) updateItem(c *gin.Context) {
 	var b bundle.UpdateItemResponse
 	var update bundle.UpdateItemRequest
+	var ok bool
 
 	err := c.BindJSON(&update)
 	if err != nil {
 		b.Code = bundle.CodeFormat
+	} else if update.Currency, ok = checkCurrency(update.Currency); !ok {
+		b.Code = bundle.CodeCurrency
 	} else {
 		userId := c.GetInt("user_id")
-		err := s.d.UpdateItem(userId, update.ItemId, update.Name, update.SubId, update.Price, update.Remark, update.Date)
+		old, loaded := s.trainedItem(userId, update.ItemId)
+		bill, code := s.updatedBill(userId, update)
 
-		if err != nil {
+		if code != bundle.CodeOk {
+			b.Code = code
+		} else if err := s.d.UpdateItem(userId, bill); err != nil {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			if loaded {
+				s.forgetItem(userId, old.SubId, old.Name, old.Remark)
+			}
+			s.learnItem(userId, bill.SubId, bill.Name, bill.Remark)
+			go s.checkAlerts(userId, bill.Id)
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
@@ -685,6 +2482,7 @@ func (s *Service) updateItem(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Body body bundle.UpdateMainTypeRequest true "修改"
+// @Success 200 {object} bundle.UpdateMainTypeResponse
 // @Router /api/main [put]
 func (s *Service) updateMainType(c *gin.Context) {
 	var b bundle.UpdateMainTypeResponse
@@ -714,12 +2512,134 @@ func (s *Service) updateMainType(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
+// @Summary 略過或修改定期帳單的某一次
+// @Description 略過或修改定期帳單某一天的名稱、金額與備註，空白表示不變更，skip 為 false 且沒有修改時取消。
+// @Description date 必須是會發生的日期，已建立帳單的日期請直接修改帳單，錯誤回傳 E-037
+// @Tags update
+// @Accept json
+// @Produce json
+// @Param Body body bundle.UpdateOccurrenceRequest true "修改"
+// @Success 200 {object} bundle.UpdateOccurrenceResponse
+// @Router /api/recurring/occurrence [put]
+func (s *Service) updateOccurrence(c *gin.Context) {
+	var b bundle.UpdateOccurrenceResponse
+	var update bundle.UpdateOccurrenceRequest
+
+	err := c.BindJSON(&update)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+
+		if e, code := s.newException(userId, update); code != bundle.CodeOk {
+			b.Code = code
+		} else if err := s.d.UpdateOccurrence(userId, e); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateOccurrence",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 修改定期帳單
+// @Description 修改定期帳單，欄位規則與建立定期帳單相同，已建立的帳單不變更
+// @Tags update
+// @Accept json
+// @Produce json
+// @Param Body body bundle.UpdateRecurringRequest true "修改"
+// @Success 200 {object} bundle.UpdateRecurringResponse
+// @Router /api/recurring [put]
+func (s *Service) updateRecurring(c *gin.Context) {
+	var b bundle.UpdateRecurringResponse
+	var update bundle.UpdateRecurringRequest
+	var ok bool
+
+	err := c.BindJSON(&update)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else if update.Currency, ok = checkCurrency(update.Currency); !ok {
+		b.Code = bundle.CodeCurrency
+	} else {
+		userId := c.GetInt("user_id")
+
+		if rc, code := s.newRecurring(userId, update.CreateRecurringRequest); code != bundle.CodeOk {
+			b.Code = code
+		} else {
+			rc.Id = update.RecurringId
+			if err := s.d.UpdateRecurring(userId, rc); err != nil {
+				b.Code = err.Error()
+			} else {
+				b.Code = bundle.CodeOk
+				go s.materializeRecurring(today())
+			}
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateRecurring",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary 修改規則
+// @Description 修改規則，欄位與建立規則相同
+// @Tags update
+// @Accept json
+// @Produce json
+// @Param Body body bundle.UpdateRuleRequest true "修改"
+// @Success 200 {object} bundle.UpdateRuleResponse
+// @Router /api/rule [put]
+func (s *Service) updateRule(c *gin.Context) {
+	var b bundle.UpdateRuleResponse
+	var update bundle.UpdateRuleRequest
+
+	err := c.BindJSON(&update)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		r := newRule(update.CreateRuleRequest)
+		r.Id = update.RuleId
+
+		if err := rule.Normalize(&r); err != nil {
+			b.Code = bundle.CodeRule
+		} else if err := s.d.UpdateRule(userId, r); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateRule",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
+
 // @Summary 修改子類別名稱
 // @Description 修改子類別名稱
 // @Tags update
 // @Accept json
 // @Produce json
 // @Param Body body bundle.UpdateSubTypeRequest true "修改"
+// @Success 200 {object} bundle.UpdateSubTypeResponse
 // @Router /api/sub [put]
 func (s *Service) updateSubType(c *gin.Context) {
 	var b bundle.UpdateSubTypeResponse
@@ -736,6 +2656,7 @@ func (s *Service) updateSubType(c *gin.Context) {
 			b.Code = err.Error()
 		} else {
 			b.Code = bundle.CodeOk
+			s.nb.Reset(userId)
 		}
 
 		log.LogHistory.L.WithFields(logrus.Fields{
@@ -748,3 +2669,42 @@ func (s *Service) updateSubType(c *gin.Context) {
 	c.Set("code", b.Code)
 	c.JSON(http.StatusOK, b)
 }
+
+// @Summary 修改轉帳
+// @Description 修改轉帳帳戶、金額、日期與備註
+// @Tags update
+// @Accept json
+// @Produce json
+// @Param Body body bundle.UpdateTransferRequest true "修改"
+// @Success 200 {object} bundle.UpdateTransferResponse
+// @Router /api/transfer [put]
+func (s *Service) updateTransfer(c *gin.Context) {
+	var b bundle.UpdateTransferResponse
+	var update bundle.UpdateTransferRequest
+
+	err := c.BindJSON(&update)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+	} else {
+		userId := c.GetInt("user_id")
+		t, code := s.newTransfer(userId, update.CreateTransferRequest)
+		t.Id = update.TransferId
+
+		if code != bundle.CodeOk {
+			b.Code = code
+		} else if err := s.d.UpdateTransfer(userId, t); err != nil {
+			b.Code = err.Error()
+		} else {
+			b.Code = bundle.CodeOk
+		}
+
+		log.LogHistory.L.WithFields(logrus.Fields{
+			"Method": "updateTransfer",
+			"UserId": userId,
+			"Code":   b.Code,
+		}).Info("Api")
+	}
+
+	c.Set("code", b.Code)
+	c.JSON(http.StatusOK, b)
+}
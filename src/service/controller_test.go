@@ -0,0 +1,171 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"me.daily/src/bundle"
+	"me.daily/src/fuzzy"
+)
+
+func TestNameMatcher(t *testing.T) {
+	n := newNameMatcher()
+
+	for _, content := range []string{"計程車", "计程车", "jcc", "JCC", "ㄐㄔㄔ", "jichengche", "ㄐㄧㄔㄥㄔㄜ"} {
+		if _, ok := n.match("計程車", content); !ok {
+			t.Errorf("%q should match", content)
+		}
+	}
+
+	for _, content := range []string{"jjc", "ㄔㄐ", "公車"} {
+		if _, ok := n.match("計程車", content); ok {
+			t.Errorf("%q should not match", content)
+		}
+	}
+}
+
+func TestNameMatcherPolyphone(t *testing.T) {
+	n := newNameMatcher()
+
+	for _, c := range []struct{ name, content string }{
+		{"銀行", "yinhang"},
+		{"銀行", "yh"},
+		{"還款", "huankuan"},
+		{"重慶", "chongqing"},
+		{"行程", "xingcheng"},
+	} {
+		if _, ok := n.match(c.name, c.content); !ok {
+			t.Errorf("%q should match %q", c.name, c.content)
+		}
+	}
+}
+
+func TestNameMatcherHighlight(t *testing.T) {
+	n := newNameMatcher()
+
+	m, ok := n.match("搭計程車", "jcc")
+	if !ok {
+		t.Fatal("expected match")
+	}
+
+	h := highlights(m)
+	if len(h) != 1 || h[0].Start != 1 || h[0].End != 4 {
+		t.Fatalf("unexpected highlight %v", h)
+	}
+}
+
+func TestSortByScore(t *testing.T) {
+	zero, low, high := 0, -3, 25
+	items := []bundle.PreviewItem{{Id: 1}, {Id: 2, Score: &zero}, {Id: 3, Score: &low}, {Id: 4, Score: &high}}
+
+	sortByScore(items)
+
+	// 分數 0 也有排序，沒有分數的排在最後
+	for i, id := range []int{4, 2, 3, 1} {
+		if items[i].Id != id {
+			t.Fatalf("unexpected order %+v", items)
+		}
+	}
+}
+
+func TestRankByName(t *testing.T) {
+	// 資料庫依字形相似度排序的候選
+	items := []bundle.PreviewItem{
+		{Id: 1, Name: "計程車費用"},
+		{Id: 2, Name: "計程表"},
+		{Id: 3, Name: "計程車"},
+		{Id: 4, Name: "搭計程車"},
+	}
+
+	list := rankByName(items, "jcc")
+
+	// 只有字形相似的候選保留在最後
+	if list[0].Id != 3 || list[3].Id != 2 || list[3].Score != nil {
+		t.Fatalf("unexpected order %+v", list)
+	}
+
+	// 排序在分頁之前，第二頁不會比第一頁相關
+	first, second := paginate(list, 2, 0), paginate(list, 2, 2)
+	if *first[1].Score < *second[0].Score {
+		t.Fatalf("unexpected pages %+v %+v", first, second)
+	}
+
+	if page := paginate(list, 2, 4); len(page) != 0 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+}
+
+func TestQueryDateRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		query string
+		code  string
+	}{
+		{"start=2022-01-01&end=2022-12-31", bundle.CodeOk},
+		{"start=2022-10-01&end=2022-10-01", bundle.CodeOk},
+		{"start=2017-01-01&end=2022-12-31", bundle.CodeDate}, // 超過查詢區間
+		{"start=2022-12-31&end=2022-01-01", bundle.CodeDate},
+		{"start=2022/01/01&end=2022-12-31", bundle.CodeFormat},
+		{"start=2022-01-01", bundle.CodeFormat},
+	}
+
+	for _, c := range cases {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", "/?"+c.query, nil)
+
+		if _, _, code := queryDateRange(ctx); code != c.code {
+			t.Errorf("queryDateRange(%q) = %s, want %s", c.query, code, c.code)
+		}
+	}
+}
+
+func TestRankByRemark(t *testing.T) {
+	items := []bundle.PreviewItem{
+		{Id: 1, Name: "午餐", Remark: "公司聚餐"},
+		{Id: 2, Name: "晚餐", Remark: "ＫＴＶ續攤"},
+		{Id: 3, Name: "ktv 包廂", Remark: "生日"},
+	}
+
+	// 備註統一全形、繁簡與大小寫後比對
+	list := rankByRemark(items, "ktv续摊", fuzzy.ContainsMatch, false)
+	if list[0].Id != 2 || list[0].Score == nil || len(list[0].RemarkHighlight) != 1 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	if list[1].Score != nil || list[2].Score != nil {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	// 包含必須連續，模糊比對只要依序出現
+	if list := rankByRemark(items, "公聚", fuzzy.ContainsMatch, false); list[0].Score != nil {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	if list := rankByRemark(items, "公聚", fuzzy.FuzzyMatch, false); list[0].Id != 1 || list[0].Score == nil {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	// 同時搜尋名稱，名稱符合的也有分數
+	list = rankByRemark(items, "KTV", fuzzy.ContainsMatch, true)
+	if list[0].Id != 2 || list[1].Id != 3 || list[1].Score == nil || len(list[1].NameHighlight) != 1 || list[2].Score != nil {
+		t.Fatalf("unexpected list %+v", list)
+	}
+}
+
+func TestLimitCandidates(t *testing.T) {
+	items := make([]bundle.PreviewItem, searchCandidates+1)
+	for i := range items {
+		items[i].Id = i + 1
+	}
+
+	// 剛好上限時沒有截斷
+	if list, truncated := limitCandidates(items[:searchCandidates]); truncated || len(list) != searchCandidates {
+		t.Fatalf("unexpected %d %v", len(list), truncated)
+	}
+
+	// 超過上限時去掉最後一筆
+	list, truncated := limitCandidates(items)
+	if !truncated || len(list) != searchCandidates || list[len(list)-1].Id != searchCandidates {
+		t.Fatalf("unexpected %d %v", len(list), truncated)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"encoding/json"
+	"mime/multipart"
+
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+)
+
+// 確認帳單幣別，空白表示基準幣別
+func checkCurrency(code string) (string, bool) {
+	if len(code) == 0 {
+		return "", true
+	}
+
+	code, err := currency.Normalize(code)
+	if err != nil {
+		return "", false
+	}
+
+	return code, true
+}
+
+// 金額換算成幣別的最小單位，幣別空白時使用基準幣別，回傳使用的幣別
+func (s *Service) parsePrice(userId int, price json.Number, code string) (int, string, string) {
+	if len(code) == 0 {
+		base, err := s.d.GetCurrency(userId)
+		if err != nil {
+			return 0, "", err.Error()
+		}
+		code = base
+	}
+
+	minor, err := currency.ParseAmount(price.String(), code)
+	if err != nil {
+		return 0, "", bundle.CodeAmount
+	}
+
+	return minor, code, bundle.CodeOk
+}
+
+// 解析基準幣別的金額，例如期初餘額與轉帳，空白為 0
+func (s *Service) baseAmount(userId int, amount json.Number) (int, string) {
+	if len(amount) == 0 {
+		return 0, bundle.CodeOk
+	}
+
+	minor, _, code := s.parsePrice(userId, amount, "")
+	return minor, code
+}
+
+// 建立請求轉成帳單，金額必須大於 0，連結原帳單的退款沒有幣別時沿用原帳單的幣別
+func (s *Service) newBill(userId int, r bundle.CreateItemRequest) (bundle.Bill, string) {
+	if r.RefundOf > 0 && len(r.Currency) == 0 {
+		origin, err := s.d.GetItem(userId, r.RefundOf)
+		if err != nil && err.Error() == bundle.CodeNoData {
+			return bundle.Bill{}, bundle.CodeRefund
+		} else if err != nil {
+			return bundle.Bill{}, err.Error()
+		}
+		r.Currency = origin.Currency
+	}
+
+	price, code, errCode := s.parsePrice(userId, r.Price, r.Currency)
+	if errCode != bundle.CodeOk {
+		return bundle.Bill{}, errCode
+	}
+
+	if price <= 0 {
+		return bundle.Bill{}, bundle.CodeAmount
+	}
+
+	return bundle.Bill{
+		SubId:     r.SubId,
+		Name:      r.Name,
+		Price:     price,
+		Remark:    r.Remark,
+		Date:      r.Date,
+		AccountId: r.AccountId,
+		Currency:  code,
+		Refund:    r.Refund,
+		RefundOf:  r.RefundOf,
+	}, bundle.CodeOk
+}
+
+// 修改請求轉成帳單，幣別空白時沿用原本的幣別
+func (s *Service) updatedBill(userId int, r bundle.UpdateItemRequest) (bundle.Bill, string) {
+	if len(r.Currency) == 0 {
+		item, err := s.d.GetItem(userId, r.ItemId)
+		if err != nil {
+			return bundle.Bill{}, err.Error()
+		}
+		r.Currency = item.Currency
+	}
+
+	bill, code := s.newBill(userId, bundle.CreateItemRequest{
+		SubId:     r.SubId,
+		Name:      r.Name,
+		Price:     r.Price,
+		Remark:    r.Remark,
+		Date:      r.Date,
+		AccountId: r.AccountId,
+		Currency:  r.Currency,
+		Refund:    r.Refund,
+		RefundOf:  r.RefundOf,
+	})
+	bill.Id = r.ItemId
+
+	return bill, code
+}
+
+// 讀取上傳的匯率 CSV，格式錯誤時回傳原因
+func openRates(fh *multipart.FileHeader) ([]bundle.Rate, string, string) {
+	if fh.Size > importMaxSize {
+		return nil, bundle.CodeImport, ""
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, bundle.CodeFormat, ""
+	}
+	defer f.Close()
+
+	rates, err := currency.ParseRates(f)
+	if err != nil {
+		return nil, bundle.CodeImport, err.Error()
+	}
+
+	return rates, bundle.CodeOk, ""
+}
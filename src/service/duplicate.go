@@ -0,0 +1,25 @@
+package service
+
+import (
+	"me.daily/src/bundle"
+	"me.daily/src/duplicate"
+)
+
+// 檢查是否重複，force 為 true 時略過
+func (s *Service) findDuplicates(userId, subId, price int, name, date string, force bool) ([]bundle.PreviewItem, string) {
+	if force {
+		return nil, bundle.CodeOk
+	}
+
+	candidates, err := s.d.GetSameItems(userId, subId, price, date)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	list := duplicate.Filter(name, candidates)
+	if len(list) > 0 {
+		return list, bundle.CodeDuplicate
+	}
+
+	return nil, bundle.CodeOk
+}
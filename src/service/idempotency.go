@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"me.daily/src/bundle"
+)
+
+// 冪等鍵 header
+const idempotencyHeader = "Idempotency-Key"
+
+// 已處理的請求
+type idempotentResponse struct {
+	hash        [sha256.Size]byte // 請求內容
+	done        bool              // false 表示處理中
+	status      int
+	contentType string
+	body        []byte
+}
+
+// 記錄回應內容
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.buf.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// 帶有 Idempotency-Key 的寫入請求只處理一次，重試時回傳第一次的回應
+//
+// 同一個 key 搭配不同的請求內容回傳 E-023，第一次還沒處理完回傳 E-024
+func (s *Service) checkIdempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyHeader)
+	userId := c.GetInt("user_id")
+
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		c.Next()
+		return
+	}
+
+	if len(key) == 0 || userId == 0 {
+		c.Next()
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Set("code", bundle.CodeFormat)
+		c.AbortWithStatusJSON(http.StatusOK, bundle.ErrorResponse{
+			Code: bundle.CodeFormat,
+		})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", c.Request.Method, c.Request.URL.RequestURI())
+	h.Write(body)
+
+	var hash [sha256.Size]byte
+	copy(hash[:], h.Sum(nil))
+
+	cacheKey := strconv.Itoa(userId) + ":" + key
+	if err := s.ic.Add(cacheKey, &idempotentResponse{hash: hash}, cache.DefaultExpiration); err != nil {
+		// 已經有相同的 key
+		s.replay(c, cacheKey, hash)
+		return
+	}
+
+	w := &bodyWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+
+	// handler panic 時沒有記錄回應，刪除處理中的 key 讓用戶端可以重試
+	recorded := false
+	defer func() {
+		if !recorded {
+			s.ic.Delete(cacheKey)
+		}
+	}()
+
+	c.Next()
+
+	// 資料庫錯誤可以重試，不保留
+	if c.GetString("code") == bundle.CodeDb || w.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	recorded = true
+	s.ic.Set(cacheKey, &idempotentResponse{
+		hash:        hash,
+		done:        true,
+		status:      w.Status(),
+		contentType: w.Header().Get("Content-Type"),
+		body:        w.buf.Bytes(),
+	}, cache.DefaultExpiration)
+}
+
+// 回傳先前的回應
+func (s *Service) replay(c *gin.Context, cacheKey string, hash [sha256.Size]byte) {
+	v, ok := s.ic.Get(cacheKey)
+	if !ok {
+		// 剛好過期或被刪除，請用戶端重試
+		v = &idempotentResponse{hash: hash}
+	}
+
+	r := v.(*idempotentResponse)
+
+	code := ""
+	switch {
+	case r.hash != hash:
+		code = bundle.CodeIdempotency
+	case !r.done:
+		code = bundle.CodeProcessing
+	}
+
+	if len(code) > 0 {
+		c.Set("code", code)
+		c.AbortWithStatusJSON(http.StatusOK, bundle.ErrorResponse{
+			Code: code,
+		})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(r.status, r.contentType, r.body)
+	c.Abort()
+}
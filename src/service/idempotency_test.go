@@ -0,0 +1,137 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"me.daily/src/bundle"
+)
+
+func newIdempotencyEngine(count *int, code string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	s := &Service{ic: cache.New(idempotencyTime*time.Second, time.Minute)}
+	e := gin.New()
+	e.Use(func(c *gin.Context) {
+		c.Set("user_id", 1)
+	}, s.checkIdempotency)
+	e.POST("/item", func(c *gin.Context) {
+		*count++
+		c.Set("code", code)
+		c.JSON(http.StatusOK, gin.H{"code": code, "count": *count})
+	})
+
+	return e
+}
+
+func doIdempotent(e *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/item", strings.NewReader(body))
+	if len(key) > 0 {
+		req.Header.Set(idempotencyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	count := 0
+	e := newIdempotencyEngine(&count, bundle.CodeOk)
+
+	first := doIdempotent(e, "k1", `{"name":"午餐"}`)
+	second := doIdempotent(e, "k1", `{"name":"午餐"}`)
+
+	if count != 1 {
+		t.Fatalf("handler called %d times", count)
+	}
+
+	if first.Body.String() != second.Body.String() {
+		t.Fatalf("unexpected replay %q %q", first.Body.String(), second.Body.String())
+	}
+
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("missing replay header")
+	}
+
+	// 不同的 key 會再執行一次
+	doIdempotent(e, "k2", `{"name":"午餐"}`)
+	if count != 2 {
+		t.Fatalf("handler called %d times", count)
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	count := 0
+	e := newIdempotencyEngine(&count, bundle.CodeOk)
+
+	doIdempotent(e, "k1", `{"name":"午餐"}`)
+	w := doIdempotent(e, "k1", `{"name":"晚餐"}`)
+
+	if count != 1 {
+		t.Fatalf("handler called %d times", count)
+	}
+
+	if !strings.Contains(w.Body.String(), bundle.CodeIdempotency) {
+		t.Fatalf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	count := 0
+	e := newIdempotencyEngine(&count, bundle.CodeOk)
+
+	doIdempotent(e, "", `{}`)
+	doIdempotent(e, "", `{}`)
+
+	if count != 2 {
+		t.Fatalf("handler called %d times", count)
+	}
+}
+
+func TestIdempotencyDbError(t *testing.T) {
+	count := 0
+	e := newIdempotencyEngine(&count, bundle.CodeDb)
+
+	// 資料庫錯誤可以用同一個 key 重試
+	doIdempotent(e, "k1", `{}`)
+	doIdempotent(e, "k1", `{}`)
+
+	if count != 2 {
+		t.Fatalf("handler called %d times", count)
+	}
+}
+
+func TestIdempotencyPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	count := 0
+	s := &Service{ic: cache.New(idempotencyTime*time.Second, time.Minute)}
+	e := gin.New()
+	e.Use(gin.Recovery(), func(c *gin.Context) {
+		c.Set("user_id", 1)
+	}, s.checkIdempotency)
+	e.POST("/item", func(c *gin.Context) {
+		count++
+		if count == 1 {
+			panic("boom")
+		}
+		c.Set("code", bundle.CodeOk)
+		c.JSON(http.StatusOK, gin.H{"code": bundle.CodeOk})
+	})
+
+	// panic 後不會一直停在處理中
+	doIdempotent(e, "k1", `{}`)
+	if w := doIdempotent(e, "k1", `{}`); strings.Contains(w.Body.String(), bundle.CodeProcessing) {
+		t.Fatalf("key still processing: %s", w.Body.String())
+	}
+
+	if count != 2 {
+		t.Fatalf("handler called %d times", count)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"io"
+	"mime/multipart"
+
+	"me.daily/src/alert"
+	"me.daily/src/archive"
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+	"me.daily/src/importer"
+)
+
+// 解析上傳的 CSV
+func (s *Service) parseCsv(userId int, fh *multipart.FileHeader, m bundle.CsvMapping) ([]bundle.ImportRow, string) {
+	if fh.Size > importMaxSize {
+		return nil, bundle.CodeImport
+	}
+
+	types, err := s.d.GetAllType(userId)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	e, err := s.ruleEngine(userId)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	base, err := s.d.GetCurrency(userId)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, bundle.CodeFormat
+	}
+	defer f.Close()
+
+	rows, err := importer.ParseCsv(f, m, base, types, e)
+	if err != nil {
+		return nil, bundle.CodeImport
+	}
+
+	return rows, bundle.CodeOk
+}
+
+// 寫入匯入資料，有任何一筆錯誤時全部不寫入
+//
+// 與現有帳單重複的資料標記為略過，force 為 true 時不檢查。新增的帳單與建立項目相同檢查提醒
+func (s *Service) importRows(userId, accountId int, rows []bundle.ImportRow, dryRun, force bool, b *bundle.ImportResponse) {
+	b.Rows = rows
+
+	create := make([]bundle.ImportRow, 0, len(rows))
+	for i, r := range rows {
+		if len(r.Error) > 0 {
+			b.Errors++
+			continue
+		}
+
+		// 新的類別不會有重複
+		if r.SubId != 0 {
+			_, code := s.findDuplicates(userId, r.SubId, r.Price, r.Name, r.Date, force)
+			if code == bundle.CodeDuplicate {
+				rows[i].Skipped = true
+				b.Skipped++
+				continue
+			} else if code != bundle.CodeOk {
+				b.Code = code
+				return
+			}
+		}
+
+		create = append(create, r)
+	}
+
+	if dryRun {
+		b.Code = bundle.CodeOk
+		return
+	}
+
+	if b.Errors > 0 {
+		b.Code = bundle.CodeImport
+		return
+	}
+
+	if len(create) > 0 {
+		ids, err := s.d.ImportItems(userId, accountId, create)
+		if err != nil {
+			b.Code = err.Error()
+			return
+		}
+		go s.checkAlerts(userId, ids...)
+	}
+
+	// 類別可能有新增，分類器重新讀取
+	s.nb.Reset(userId)
+
+	b.Code = bundle.CodeOk
+	b.Committed = true
+}
+
+// 解析上傳的對帳單，base 為基準幣別
+func openStatement(fh *multipart.FileHeader, base string, parse func(io.Reader, string) ([]bundle.ImportRow, error)) ([]bundle.ImportRow, string) {
+	if fh.Size > importMaxSize {
+		return nil, bundle.CodeImport
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, bundle.CodeFormat
+	}
+	defer f.Close()
+
+	rows, err := parse(f, base)
+	if err != nil {
+		return nil, bundle.CodeImport
+	}
+
+	return rows, bundle.CodeOk
+}
+
+// 匯入對帳單
+//
+// 依規則決定類別，已匯入過的交易略過，找不到類別的不匯入，其餘一次寫入後檢查提醒
+func (s *Service) importStatement(userId, accountId int, rows []bundle.ImportRow, subId int, dryRun bool, b *bundle.StatementImportResponse) {
+	b.Rows = rows
+
+	types, err := s.d.GetAllType(userId)
+	if err != nil {
+		b.Code = err.Error()
+		return
+	}
+
+	e, err := s.ruleEngine(userId)
+	if err != nil {
+		b.Code = err.Error()
+		return
+	}
+
+	importer.Match(rows, e, types, subId)
+
+	exist, err := s.d.GetFitIds(userId, importer.FitIds(rows))
+	if err != nil {
+		b.Code = err.Error()
+		return
+	}
+	importer.MarkSkipped(rows, exist)
+
+	create := make([]bundle.ImportRow, 0, len(rows))
+	for _, r := range rows {
+		switch {
+		case r.Skipped:
+			b.Skipped++
+		case len(r.Error) > 0:
+			b.Unmatched++
+		default:
+			create = append(create, r)
+		}
+	}
+
+	b.Code = bundle.CodeOk
+	b.Created = len(create)
+	if dryRun || len(create) == 0 {
+		return
+	}
+
+	ids, err := s.d.ImportItems(userId, accountId, create)
+	if err != nil {
+		b.Code = err.Error()
+		b.Created = 0
+		return
+	}
+
+	// 同時匯入時可能已被寫入
+	b.Skipped += len(create) - len(ids)
+	b.Created = len(ids)
+	b.Committed = true
+	s.nb.Reset(userId)
+	go s.checkAlerts(userId, ids...)
+}
+
+// 還原上傳的備份檔
+func (s *Service) restoreArchive(userId int, fh *multipart.FileHeader, replace bool, r *bundle.RestoreResult) string {
+	if fh.Size > restoreMaxSize {
+		return bundle.CodeArchive
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return bundle.CodeFormat
+	}
+	defer f.Close()
+
+	base, err := s.d.GetCurrency(userId)
+	if err != nil {
+		return err.Error()
+	}
+
+	data, err := archive.Read(f, base)
+	if err == archive.ErrVersion {
+		return bundle.CodeVersion
+	} else if err != nil {
+		return bundle.CodeArchive
+	}
+
+	if len(data.Settings.Currency) > 0 {
+		if data.Settings.Currency, err = currency.Normalize(data.Settings.Currency); err != nil {
+			return bundle.CodeArchive
+		}
+	}
+
+	// 備份的 webhook 與設定時相同檢查
+	if len(data.Settings.Alert.Webhook) > 0 {
+		if data.Settings.Alert.Webhook, err = alert.CheckURL(data.Settings.Alert.Webhook); err != nil {
+			return bundle.CodeAlert
+		}
+	}
+
+	result, err := s.d.RestoreArchive(userId, data, replace)
+	if err != nil {
+		return err.Error()
+	}
+
+	*r = result
+	s.nb.Reset(userId)
+
+	return bundle.CodeOk
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"me.daily/src/bundle"
+	"me.daily/src/quick"
+)
+
+// 解析快速輸入並套用規則，找不到類別時改用分類器預測，回傳要建立的帳單與套用的規則編號
+//
+// item 為回傳給使用者的解析結果，金額為基準幣別
+func (s *Service) parseQuickItem(userId int, text string, item *bundle.CreateItemRequest) (bundle.Bill, int, string) {
+	types, err := s.d.GetAllType(userId)
+	if err != nil {
+		return bundle.Bill{}, 0, err.Error()
+	}
+
+	e, err := s.ruleEngine(userId)
+	if err != nil {
+		return bundle.Bill{}, 0, err.Error()
+	}
+
+	r, err := quick.Parse(text, time.Now(), types)
+	if err != nil || len(r.Name) == 0 {
+		return bundle.Bill{}, 0, bundle.CodeQuickParse
+	}
+
+	item.SubId = r.SubId
+	item.Name = r.Name
+	item.Price = json.Number(r.Price)
+	item.Remark = r.Remark
+	item.Date = r.Date.Format(dateFormat)
+
+	bill, code := s.newBill(userId, *item)
+	if code != bundle.CodeOk {
+		return bill, 0, code
+	}
+
+	ruleId := applyRule(e, &bill)
+
+	if bill.SubId == 0 {
+		cl, err := s.nb.Get(userId, func() ([]bundle.Item, error) {
+			return s.d.GetAllItems(userId)
+		})
+
+		if err == nil {
+			list := cl.Predict(bill.Name, bill.Remark, 1)
+			if len(list) > 0 && list[0].Confidence >= quickConfidence {
+				bill.SubId = list[0].SubId
+			}
+		}
+	}
+
+	item.SubId = bill.SubId
+	item.Name = bill.Name
+	item.Remark = bill.Remark
+	item.Currency = bill.Currency
+
+	return bill, ruleId, bundle.CodeOk
+}
+
+// 與 CreateItemRequest 相同的長度限制，名稱 2 到 32 字，備註最多 64 字
+func validQuickItem(bill bundle.Bill) bool {
+	n := utf8.RuneCountInString(bill.Name)
+	return n >= 2 && n <= 32 && utf8.RuneCountInString(bill.Remark) <= 64
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"me.daily/src/bundle"
+)
+
+func TestValidQuickItem(t *testing.T) {
+	cases := []struct {
+		bill bundle.Bill
+		ok   bool
+	}{
+		{bundle.Bill{Name: "午餐"}, true},
+		{bundle.Bill{Name: "飯"}, false},
+		{bundle.Bill{Name: strings.Repeat("飯", 32)}, true},
+		{bundle.Bill{Name: strings.Repeat("飯", 33)}, false},
+		{bundle.Bill{Name: "午餐", Remark: strings.Repeat("公", 64)}, true},
+		{bundle.Bill{Name: "午餐", Remark: strings.Repeat("公", 65)}, false},
+	}
+
+	for _, c := range cases {
+		if ok := validQuickItem(c.bill); ok != c.ok {
+			t.Errorf("validQuickItem(%q, %q) = %v, want %v", c.bill.Name, c.bill.Remark, ok, c.ok)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"me.daily/src/bundle"
+	"me.daily/src/currency"
+	"me.daily/src/log"
+	"me.daily/src/recur"
+)
+
+// 即將發生的定期帳單預設天數
+const upcomingDays = 30
+
+// 建立請求轉成定期帳單範本，金額必須大於 0，幣別空白時記錄當時的基準幣別
+func (s *Service) newRecurring(userId int, r bundle.CreateRecurringRequest) (bundle.Recurring, string) {
+	price, code, errCode := s.parsePrice(userId, r.Price, r.Currency)
+	if errCode != bundle.CodeOk {
+		return bundle.Recurring{}, errCode
+	}
+
+	if price <= 0 {
+		return bundle.Recurring{}, bundle.CodeAmount
+	}
+
+	rc := bundle.Recurring{
+		SubId:     r.SubId,
+		Name:      r.Name,
+		Price:     price,
+		Remark:    r.Remark,
+		AccountId: r.AccountId,
+		Currency:  code,
+		Frequency: r.Frequency,
+		Interval:  r.Interval,
+		Nth:       r.Nth,
+		Weekday:   r.Weekday,
+		Start:     r.Start,
+		End:       r.End,
+		Count:     r.Count,
+	}
+
+	if err := recur.Normalize(&rc); err != nil {
+		return rc, bundle.CodeRecurring
+	}
+
+	return rc, bundle.CodeOk
+}
+
+// 修改請求轉成例外，日期必須是範本會發生的日期，金額使用範本的幣別
+func (s *Service) newException(userId int, r bundle.UpdateOccurrenceRequest) (bundle.RecurringException, string) {
+	date, err := time.Parse(dateFormat, r.Date)
+	if err != nil {
+		return bundle.RecurringException{}, bundle.CodeFormat
+	}
+
+	list, err := s.d.GetRecurring(userId)
+	if err != nil {
+		return bundle.RecurringException{}, err.Error()
+	}
+
+	for _, rc := range list {
+		if rc.Id != r.RecurringId {
+			continue
+		}
+
+		if len(recur.Between(rc, date, date)) == 0 {
+			return bundle.RecurringException{}, bundle.CodeRecurring
+		}
+
+		e := bundle.RecurringException{
+			RecurringId: rc.Id,
+			Date:        date.Format(dateFormat),
+			Skip:        r.Skip,
+			Name:        r.Name,
+			Remark:      r.Remark,
+		}
+
+		if len(r.Price) > 0 {
+			price, _, code := s.parsePrice(userId, r.Price, rc.Currency)
+			if code != bundle.CodeOk {
+				return e, code
+			}
+			if price <= 0 {
+				return e, bundle.CodeAmount
+			}
+			e.Price = price
+		}
+
+		return e, bundle.CodeOk
+	}
+
+	return bundle.RecurringException{}, bundle.CodeNoData
+}
+
+// 區間內的發生日期套用例外，例外的 key 為 範本id:日期
+func occurrences(r bundle.Recurring, exceptions map[string]bundle.RecurringException, from, to time.Time) []bundle.Occurrence {
+	list := make([]bundle.Occurrence, 0)
+
+	for _, d := range recur.Between(r, from, to) {
+		o := bundle.Occurrence{
+			RecurringId: r.Id,
+			Date:        d.Format(dateFormat),
+			SubId:       r.SubId,
+			Name:        r.Name,
+			Price:       r.Price,
+			Remark:      r.Remark,
+			Currency:    r.Currency,
+			Scale:       currency.Scale(r.Currency),
+		}
+
+		if e, ok := exceptions[exceptionKey(r.Id, o.Date)]; ok {
+			o.Skip = e.Skip
+			if len(e.Name) > 0 {
+				o.Name, o.Edited = e.Name, true
+			}
+			if e.Price > 0 {
+				o.Price, o.Edited = e.Price, true
+			}
+			if len(e.Remark) > 0 {
+				o.Remark, o.Edited = e.Remark, true
+			}
+		}
+
+		list = append(list, o)
+	}
+
+	return list
+}
+
+// 例外的 key
+func exceptionKey(recurringId int, date string) string {
+	return fmt.Sprintf("%d:%s", recurringId, date)
+}
+
+// 取得使用者的例外
+func (s *Service) recurringExceptions(userId int) (map[string]bundle.RecurringException, error) {
+	exceptions := make(map[string]bundle.RecurringException)
+
+	list, err := s.d.GetRecurringExceptions(userId)
+	if err != nil {
+		return exceptions, err
+	}
+
+	return exceptionMap(list), nil
+}
+
+// 例外依 key 建立索引
+func exceptionMap(list []bundle.RecurringException) map[string]bundle.RecurringException {
+	exceptions := make(map[string]bundle.RecurringException, len(list))
+	for _, e := range list {
+		exceptions[exceptionKey(e.RecurringId, e.Date)] = e
+	}
+
+	return exceptions
+}
+
+// 今天零時，與 recur 計算的日期相同使用 UTC
+func today() time.Time {
+	d, _ := time.Parse(dateFormat, time.Now().Format(dateFormat))
+	return d
+}
+
+// 尚未建立帳單的第一天，沒有建立過時為開始日期
+func nextDate(r bundle.Recurring) time.Time {
+	if d, err := time.Parse(dateFormat, r.Materialized); err == nil {
+		return d.AddDate(0, 0, 1)
+	}
+
+	d, _ := time.Parse(dateFormat, r.Start)
+	return d
+}
+
+// 今天到 end 之間即將發生的定期帳單，依日期排列，包含略過的那一次
+func (s *Service) upcoming(userId int, today, end time.Time) ([]bundle.Occurrence, string) {
+	list := make([]bundle.Occurrence, 0)
+
+	templates, err := s.d.GetRecurring(userId)
+	if err != nil {
+		return list, err.Error()
+	}
+
+	exceptions, err := s.recurringExceptions(userId)
+	if err != nil {
+		return list, err.Error()
+	}
+
+	for _, r := range templates {
+		from := nextDate(r)
+		if from.Before(today) {
+			from = today
+		}
+
+		list = append(list, occurrences(r, exceptions, from, end)...)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Date < list[j].Date
+	})
+
+	return list, bundle.CodeOk
+}
+
+// 建立到今天為止的定期帳單，停機期間的帳單也會補上，略過的那一次不建立
+//
+// 新帳單與建立項目相同加入分類器並檢查提醒
+func (s *Service) materializeRecurring(today time.Time) {
+	list, err := s.d.GetDueRecurring(today.Format(dateFormat))
+	if err != nil {
+		return
+	}
+
+	order := make([]int, 0)
+	created := make(map[int][]int)
+
+	for _, r := range list {
+		build := func(list []bundle.RecurringException) []bundle.Bill {
+			bills := make([]bundle.Bill, 0)
+			for _, o := range occurrences(r, exceptionMap(list), nextDate(r), today) {
+				if o.Skip {
+					continue
+				}
+
+				bills = append(bills, bundle.Bill{
+					SubId:    o.SubId,
+					Name:     o.Name,
+					Price:    o.Price,
+					Remark:   o.Remark,
+					Date:     o.Date,
+					Currency: o.Currency,
+				})
+			}
+
+			return bills
+		}
+
+		bills, err := s.d.MaterializeRecurring(r, today.Format(dateFormat), build)
+		if err != nil {
+			log.LogHistory.L.WithFields(logrus.Fields{
+				"Method":      "materializeRecurring",
+				"UserId":      r.UserId,
+				"RecurringId": r.Id,
+				"Error":       err.Error(),
+			}).Error("Recurring")
+			continue
+		}
+
+		for _, b := range bills {
+			s.learnItem(r.UserId, b.SubId, b.Name, b.Remark)
+			if _, ok := created[r.UserId]; !ok {
+				order = append(order, r.UserId)
+			}
+			created[r.UserId] = append(created[r.UserId], b.Id)
+		}
+	}
+
+	for _, userId := range order {
+		s.checkAlerts(userId, created[userId]...)
+	}
+}
+
+// 啟動時先補上停機期間的帳單，之後定時建立
+func (s *Service) recurLoop() {
+	t := time.NewTicker(recurInterval * time.Second)
+	defer t.Stop()
+
+	for {
+		s.materializeRecurring(today())
+
+		<-t.C
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"me.daily/src/bundle"
+)
+
+func TestNewRecurring(t *testing.T) {
+	s := &Service{}
+
+	cases := []struct {
+		r    bundle.CreateRecurringRequest
+		code string
+	}{
+		{bundle.CreateRecurringRequest{Price: "150", Currency: "TWD", Start: "2022-10-01"}, bundle.CodeOk},
+		{bundle.CreateRecurringRequest{Price: "0", Currency: "TWD", Start: "2022-10-01"}, bundle.CodeAmount},
+		{bundle.CreateRecurringRequest{Price: "1.5", Currency: "JPY", Start: "2022-10-01"}, bundle.CodeAmount},
+		{bundle.CreateRecurringRequest{Price: "150", Currency: "TWD", Start: "2022-10-01", Frequency: "hourly"}, bundle.CodeRecurring},
+		{bundle.CreateRecurringRequest{Price: "150", Currency: "TWD", Start: "2022-10-01", End: "2022-09-01"}, bundle.CodeRecurring},
+	}
+
+	for _, c := range cases {
+		if _, code := s.newRecurring(1, c.r); code != c.code {
+			t.Errorf("newRecurring(%+v) = %s, want %s", c.r, code, c.code)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	r := bundle.Recurring{
+		Id: 1, SubId: 2, Name: "房租", Price: 1500000, Currency: "USD",
+		Frequency: bundle.RecurMonthly, Interval: 1, Start: "2022-10-05",
+	}
+	exceptions := map[string]bundle.RecurringException{
+		exceptionKey(1, "2022-11-05"): {RecurringId: 1, Date: "2022-11-05", Skip: true},
+		exceptionKey(1, "2022-12-05"): {RecurringId: 1, Date: "2022-12-05", Price: 1600000},
+	}
+
+	from, _ := time.Parse(dateFormat, "2022-10-01")
+	to, _ := time.Parse(dateFormat, "2022-12-31")
+	list := occurrences(r, exceptions, from, to)
+
+	if len(list) != 3 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	if list[0].Skip || list[0].Edited || list[0].Scale != 2 {
+		t.Errorf("unexpected occurrence %+v", list[0])
+	}
+
+	if !list[1].Skip || list[1].Date != "2022-11-05" {
+		t.Errorf("unexpected occurrence %+v", list[1])
+	}
+
+	if !list[2].Edited || list[2].Price != 1600000 || list[2].Name != "房租" {
+		t.Errorf("unexpected occurrence %+v", list[2])
+	}
+}
+
+func TestNextDate(t *testing.T) {
+	r := bundle.Recurring{Start: "2022-10-05"}
+	if d := nextDate(r).Format(dateFormat); d != "2022-10-05" {
+		t.Fatalf("unexpected date %s", d)
+	}
+
+	// 停機後從上次建立的隔天開始補
+	r.Materialized = "2022-10-31"
+	if d := nextDate(r).Format(dateFormat); d != "2022-11-01" {
+		t.Fatalf("unexpected date %s", d)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"time"
+
+	"me.daily/src/bundle"
+	"me.daily/src/rule"
+)
+
+// 讀取使用者的規則
+func (s *Service) ruleEngine(userId int) (*rule.Engine, error) {
+	rules, err := s.d.GetRules(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.New(rules), nil
+}
+
+// 依規則修改項目，回傳套用的規則編號，沒有符合的規則時回傳 0
+func applyRule(e *rule.Engine, item *bundle.Bill) int {
+	r, ok := e.Apply(rule.Input{Name: item.Name, Remark: item.Remark, Price: item.Price})
+	if !ok {
+		return 0
+	}
+
+	if r.SubId > 0 {
+		item.SubId = r.SubId
+	}
+	item.Name = r.Name
+	item.Remark = r.Remark
+
+	return r.Rule.Id
+}
+
+// 建立請求轉成規則
+func newRule(r bundle.CreateRuleRequest) bundle.Rule {
+	return bundle.Rule{
+		Field:    r.Field,
+		Match:    r.Match,
+		Keyword:  r.Keyword,
+		MinPrice: r.MinPrice,
+		MaxPrice: r.MaxPrice,
+		SubId:    r.SubId,
+		Rename:   r.Rename,
+		Remark:   r.Remark,
+		Priority: r.Priority,
+	}
+}
+
+// 以既有帳單測試規則，不寫入資料
+func (s *Service) testRuleItems(userId int, test bundle.TestRuleRequest, b *bundle.TestRuleResponse) {
+	r := newRule(test.CreateRuleRequest)
+	if err := rule.Normalize(&r); err != nil {
+		b.Code = bundle.CodeRule
+		return
+	}
+
+	// 預設最近一年
+	now := time.Now()
+	if len(test.End) == 0 {
+		test.End = now.Format(dateFormat)
+	}
+	if len(test.Start) == 0 {
+		test.Start = now.AddDate(-1, 0, 0).Format(dateFormat)
+	}
+
+	startDate, err := time.Parse(dateFormat, test.Start)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+		return
+	}
+
+	endDate, err := time.Parse(dateFormat, test.End)
+	if err != nil {
+		b.Code = bundle.CodeFormat
+		return
+	}
+
+	if startDate.After(endDate) || endDate.After(startDate.AddDate(dateRange, 0, 0)) {
+		b.Code = bundle.CodeDate
+		return
+	}
+
+	items, err := s.d.GetPerviewItemsByDate(userId, test.Start, test.End)
+	if err != nil {
+		b.Code = err.Error()
+		return
+	}
+
+	e := rule.New([]bundle.Rule{r})
+	for _, item := range items {
+		result, ok := e.Apply(rule.Input{Name: item.Name, Remark: item.Remark, Price: item.Price})
+		if !ok {
+			continue
+		}
+
+		b.Total++
+		if len(b.List) < maxPageSize {
+			subId := item.SubId
+			if result.SubId > 0 {
+				subId = result.SubId
+			}
+
+			b.List = append(b.List, bundle.RuleTestResult{
+				Item:   item,
+				SubId:  subId,
+				Name:   result.Name,
+				Remark: result.Remark,
+			})
+		}
+	}
+
+	b.Code = bundle.CodeOk
+}
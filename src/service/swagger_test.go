@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"me.daily/src/docs"
+)
+
+// 尚未實作的路由
+var undocumented = map[string]bool{}
+
+func newTestService() *Service {
+	gin.SetMode(gin.TestMode)
+
+	a := make(gin.Accounts)
+	a["admin"] = "admin"
+
+	s := &Service{
+		a: a,
+		s: gin.New(),
+	}
+	s.route()
+
+	return s
+}
+
+// 取得 swagger 文件中的路由
+func swaggerRoutes(t *testing.T) map[string]bool {
+	doc := docs.SwaggerInfo.ReadDoc()
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := make(map[string]bool)
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			routes[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	return routes
+}
+
+func TestSwaggerMatchRoutes(t *testing.T) {
+	s := newTestService()
+	documented := swaggerRoutes(t)
+
+	// gin 路由參數 :id 轉成 swagger {id}
+	param := regexp.MustCompile(`:(\w+)`)
+
+	registered := make(map[string]bool)
+	for _, r := range s.s.Routes() {
+		if !strings.HasPrefix(r.Path, "/api/") {
+			continue
+		}
+
+		key := r.Method + " " + param.ReplaceAllString(r.Path, "{$1}")
+		registered[key] = true
+
+		if !documented[key] && !undocumented[key] {
+			t.Errorf("route %s is not documented", key)
+		}
+	}
+
+	for key := range documented {
+		if !registered[key] {
+			t.Errorf("documented route %s is not registered", key)
+		}
+	}
+}
+
+func TestSwaggerAuth(t *testing.T) {
+	s := newTestService()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	s.s.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	req.SetBasicAuth("admin", "admin")
+	s.s.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if !json.Valid(w.Body.Bytes()) {
+		t.Fatal("doc.json is not valid json")
+	}
+}
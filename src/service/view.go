@@ -6,16 +6,43 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"me.daily/src/alert"
+	"me.daily/src/classifier"
 	"me.daily/src/db"
+	"me.daily/src/docs"
 	"me.daily/src/log"
 
 	"github.com/patrickmn/go-cache"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 const (
-	dateFormat  = "2006-01-02" //日期格式
-	expiredTime = 8 * 60 * 60  //過期時間
-	dateRange   = 5            // 查詢日期區間(年)
+	dateFormat      = "2006-01-02" //日期格式
+	expiredTime     = 8 * 60 * 60  //過期時間
+	idempotencyTime = 24 * 60 * 60 // 冪等鍵保留時間
+	dateRange       = 5            // 查詢日期區間(年)
+	pageSize        = 50           // 預設每頁筆數
+	maxPageSize     = 200          // 每頁筆數上限
+
+	searchCandidates = 1000 // 搜尋重新排序的候選筆數上限
+
+	suggestDays  = 365 // 建議參考的天數
+	suggestLimit = 10  // 建議筆數
+
+	quickConfidence = 0.5 // 快速輸入採用分類器預測的最低信心度
+
+	importMaxSize  = 5 << 20  // 匯入檔案大小上限
+	restoreMaxSize = 50 << 20 // 備份檔大小上限
+
+	restoreMerge   = "merge"   // 還原時合併
+	restoreReplace = "replace" // 還原時取代
+
+	exportStart = "1970-01-01" // 匯出預設起始日期
+	exportEnd   = "9999-12-31" // 匯出預設結束日期
+
+	alertInterval = 60 * 60 // 排程檢查提醒的間隔(秒)
+	recurInterval = 60 * 60 // 排程建立定期帳單的間隔(秒)
 )
 
 type Service struct {
@@ -23,28 +50,47 @@ type Service struct {
 	c   *cache.Cache
 	d   *db.Db
 	fsh http.Handler
+	ic  *cache.Cache
+	ms  alert.MailServer
+	nb  *classifier.Store
 	s   *gin.Engine
 }
 
-func NewService(host, user, password, dbname, authUser, authPw string, fs embed.FS) *Service {
+func NewService(host, user, password, dbname, authUser, authPw string, ms alert.MailServer, fs embed.FS) (*Service, error) {
 	a := make(gin.Accounts)
 	a[authUser] = authPw
 
+	d, err := db.NewDb(host, user, password, dbname)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
 		a:   a,
 		c:   cache.New(expiredTime*time.Second, 60*time.Minute),
-		d:   db.NewDb(host, user, password, dbname),
+		d:   d,
 		fsh: http.FileServer(http.FS(fs)),
+		ic:  cache.New(idempotencyTime*time.Second, 60*time.Minute),
+		ms:  ms,
+		nb:  classifier.NewStore(),
 		s:   gin.New(),
-	}
+	}, nil
 }
 
-// swag init
-//
-//	http://localhost:8080/swagger/index.html
-//	go get -u github.com/swaggo/gin-swagger
-//	go get -u github.com/swaggo/files
 func (s *Service) Start() {
+	go s.alertLoop()
+	go s.recurLoop()
+
+	s.route()
+	s.s.Run(":80")
+}
+
+// 註冊路由
+//
+// swagger 文件由 go generate 產生 (src/docs)
+//
+//	http://localhost/swagger/index.html
+func (s *Service) route() {
 	s.s.RedirectFixedPath = true
 
 	s.s.Use(gin.Recovery(), log.LogHistory.Func)
@@ -53,7 +99,7 @@ func (s *Service) Start() {
 	s.s.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", c.Request.Host)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Idempotency-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -64,9 +110,6 @@ func (s *Service) Start() {
 		c.Next()
 	})
 
-	// docs.SwaggerInfo.BasePath = "/"
-	// s.s.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
 	// static files
 	s.s.Any("/public/*any", func(c *gin.Context) {
 		s.fsh.ServeHTTP(c.Writer, c.Request)
@@ -81,37 +124,91 @@ func (s *Service) Start() {
 		gInfo.GET("/log", s.getLog)
 	}
 
+	// swagger
+	{
+		docs.SwaggerInfo.BasePath = "/"
+
+		gSwagger := s.s.Group("swagger")
+
+		gSwagger.Use(gin.BasicAuth(s.a))
+
+		gSwagger.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
 	// Api
 	{
 		gApi := s.s.Group("/api")
 
-		gApi.Use(s.checkAuth)
+		gApi.Use(s.checkAuth, s.checkIdempotency)
 
+		gApi.GET("/accounts", s.getAccounts)
+		gApi.GET("/alerts", s.getAlerts)
+		gApi.GET("/alert/setting", s.getAlertSetting)
+		gApi.GET("/account/:account_id/items", s.getAccountItems)
 		gApi.GET("/main", s.getMainType)
 		gApi.GET("/sub/:main_id", s.getSubType)
 		gApi.GET("/all", s.getAll)
 		gApi.GET("/item/:item_id", s.getItem)
 		gApi.GET("/items", s.getItems)
+		gApi.GET("/backup", s.getBackup)
+		gApi.GET("/budgets", s.getBudgets)
+		gApi.GET("/budgets/status", s.getBudgetStatus)
+		gApi.GET("/currency", s.getCurrency)
+		gApi.GET("/duplicates", s.getDuplicates)
+		gApi.GET("/export", s.getExport)
 		gApi.GET("/spend/month/:count", s.getSpendByLastMonthly)
 		gApi.GET("/sum/main", s.getSumByMainType)
 		gApi.GET("/search/name", s.searchByName)
-		gApi.GET("/search/remake")
+		gApi.GET("/search/remake", s.searchByRemark)
+		gApi.GET("/suggest/name", s.getSuggestName)
+		gApi.GET("/suggest/category", s.getSuggestCategory)
+		gApi.GET("/recurring", s.getRecurring)
+		gApi.GET("/recurring/upcoming", s.getUpcoming)
+		gApi.GET("/rule", s.getRules)
+		gApi.GET("/transfers", s.getTransfers)
+		gApi.GET("/rates", s.getRates)
 
 		gApi.GET("/logout", s.logout)
 		gApi.POST("/login", s.login)
 		gApi.POST("/user", s.createUser)
+		gApi.POST("/account", s.createAccount)
+		gApi.POST("/budget", s.createBudget)
 		gApi.POST("/main", s.createMainType)
 		gApi.POST("/sub", s.createSubType)
 		gApi.POST("/item", s.createItem)
-
+		gApi.POST("/item/quick", s.createQuickItem)
+		gApi.POST("/import/csv", s.importCsv)
+		gApi.POST("/import/ofx", s.importOfx)
+		gApi.POST("/import/qif", s.importQif)
+		gApi.POST("/import/rates", s.importRates)
+		gApi.POST("/recurring", s.createRecurring)
+		gApi.POST("/rule", s.createRule)
+		gApi.POST("/rule/test", s.testRule)
+		gApi.POST("/restore", s.restore)
+		gApi.POST("/transfer", s.createTransfer)
+		gApi.POST("/rate", s.createRate)
+
+		gApi.PUT("/account", s.updateAccount)
+		gApi.PUT("/alerts/read", s.readAlerts)
+		gApi.PUT("/alert/setting", s.updateAlertSetting)
+		gApi.PUT("/budget", s.updateBudget)
 		gApi.PUT("/main", s.updateMainType)
 		gApi.PUT("/sub", s.updateSubType)
 		gApi.PUT("/item", s.updateItem)
-
+		gApi.PUT("/recurring", s.updateRecurring)
+		gApi.PUT("/recurring/occurrence", s.updateOccurrence)
+		gApi.PUT("/rule", s.updateRule)
+		gApi.PUT("/transfer", s.updateTransfer)
+		gApi.PUT("/currency", s.updateCurrency)
+
+		gApi.DELETE("/account/:account_id", s.deleteAccount)
+		gApi.DELETE("/budget/:budget_id", s.deleteBudget)
 		gApi.DELETE("/main/:main_id", s.deleteMainType)
 		gApi.DELETE("/sub/:sub_id", s.deleteSubType)
 		gApi.DELETE("/item/:item_id", s.deleteItem)
+		gApi.DELETE("/recurring/:recurring_id", s.deleteRecurring)
+		gApi.DELETE("/rule/:rule_id", s.deleteRule)
+		gApi.DELETE("/transfer/:transfer_id", s.deleteTransfer)
+		gApi.DELETE("/rate/:rate_id", s.deleteRate)
 	}
-
-	s.s.Run(":80")
 }
@@ -0,0 +1,78 @@
+package transformer
+
+import (
+	"bufio"
+	"embed"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/width"
+)
+
+//go:embed data/*.txt
+var data embed.FS
+
+// 繁簡對照表
+var (
+	t2s = loadTable("data/t2s.txt")
+	s2t = loadTable("data/s2t.txt")
+)
+
+// 全形轉半形，例如 "ＫＴＶ" -> "KTV"
+var Narrow = width.Fold
+
+// 繁體轉簡體
+var Simplified = runes.Map(func(r rune) rune {
+	return lookup(t2s, r)
+})
+
+// 簡體轉繁體
+var Traditional = runes.Map(func(r rune) rune {
+	return lookup(s2t, r)
+})
+
+// 全形轉半形 + 繁簡統一 + 忽略大小寫 + Unicode等價性
+//
+// 搜尋時使用，不論繁簡體或全形半形都能比對。
+// transform.Chain 帶有狀態，不能同時使用，每次搜尋建立一個
+func NewChineseFold() transform.Transformer {
+	return transform.Chain(Narrow, Simplified, Fold, newNormalized())
+}
+
+// 讀取對照表，每行 "來源\t結果"，# 開頭為註解
+func loadTable(name string) map[rune]rune {
+	f, err := data.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	t := make(map[rune]rune)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		from, to, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		k, _ := utf8.DecodeRuneInString(from)
+		v, _ := utf8.DecodeRuneInString(to)
+		t[k] = v
+	}
+
+	return t
+}
+
+func lookup(t map[rune]rune, r rune) rune {
+	if v, ok := t[r]; ok {
+		return v
+	}
+	return r
+}
@@ -0,0 +1,57 @@
+package transformer
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestNarrow(t *testing.T) {
+	s, _, err := transform.String(Narrow, "ＫＴＶ１２３")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != "KTV123" {
+		t.Fatalf("unexpected %q", s)
+	}
+}
+
+func TestSimplified(t *testing.T) {
+	s, _, err := transform.String(Simplified, "計程車 早餐")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != "计程车 早餐" {
+		t.Fatalf("unexpected %q", s)
+	}
+}
+
+func TestTraditional(t *testing.T) {
+	s, _, err := transform.String(Traditional, "计程车")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != "計程車" {
+		t.Fatalf("unexpected %q", s)
+	}
+}
+
+func TestChineseFold(t *testing.T) {
+	cases := [][2]string{
+		{"ＫＴＶ", "ktv"},
+		{"計程車", "计程车"},
+		{"高鐵ＡＢＣ", "高铁abc"},
+	}
+
+	fold := NewChineseFold()
+	for _, c := range cases {
+		a, _, _ := transform.String(fold, c[0])
+		b, _, _ := transform.String(fold, c[1])
+		if a != b {
+			t.Errorf("%q and %q fold to %q and %q", c[0], c[1], a, b)
+		}
+	}
+}
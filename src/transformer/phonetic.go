@@ -0,0 +1,190 @@
+package transformer
+
+import (
+	"bufio"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// 讀音
+type reading struct {
+	pinyin string // 拼音，不含聲調，ü 以 v 表示
+	zhuyin string // 注音，不含聲調
+}
+
+// 一個名稱最多產生的讀音組合，多音字太多時其餘的字只用常用讀音
+const maxVariants = 8
+
+// 漢字讀音表，多音字有多個讀音，常用的在前
+var readings = loadReadings("data/phonetic.txt", "data/polyphone.txt")
+
+// 漢字轉讀音，多音字使用常用讀音，Variants 取得全部讀音的組合
+type Phonetic struct {
+	form func(v reading) string
+}
+
+// 漢字轉拼音，例如 "計程車" -> "jichengche"
+var Pinyin = &Phonetic{form: func(v reading) string {
+	return v.pinyin
+}}
+
+// 漢字轉拼音首字母，例如 "計程車" -> "jcc"
+var PinyinInitials = &Phonetic{form: func(v reading) string {
+	return v.pinyin[:1]
+}}
+
+// 漢字轉注音，例如 "計程車" -> "ㄐㄧㄔㄥㄔㄜ"
+var Zhuyin = &Phonetic{form: func(v reading) string {
+	return v.zhuyin
+}}
+
+// 漢字轉注音首個符號，例如 "計程車" -> "ㄐㄔㄔ"
+var ZhuyinInitials = &Phonetic{form: func(v reading) string {
+	_, size := utf8.DecodeRuneInString(v.zhuyin)
+	return v.zhuyin[:size]
+}}
+
+func (p *Phonetic) convert(r rune) string {
+	if v, ok := readings[r]; ok {
+		return p.form(v[0])
+	}
+	return string(r)
+}
+
+func (p *Phonetic) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	return runeTransformer(p.convert).Transform(dst, src, atEOF)
+}
+
+func (p *Phonetic) Reset() {}
+
+// 多音字每種讀音的組合，例如 "銀行" 的拼音為 "yinxing"、"yinhang"
+//
+// 第一個與 Transform 的結果相同，最多 maxVariants 種
+func (p *Phonetic) Variants(s string) []string {
+	list := []string{""}
+	for _, r := range s {
+		forms := p.forms(r)
+		if len(list)*len(forms) > maxVariants {
+			forms = forms[:1]
+		}
+
+		next := make([]string, 0, len(list)*len(forms))
+		for _, prefix := range list {
+			for _, f := range forms {
+				next = append(next, prefix+f)
+			}
+		}
+		list = next
+	}
+
+	return list
+}
+
+// 字元全部讀音轉換後的結果，去除重複，例如首字母相同的讀音只算一種
+func (p *Phonetic) forms(r rune) []string {
+	v, ok := readings[r]
+	if !ok {
+		return []string{string(r)}
+	}
+
+	forms := make([]string, 0, len(v))
+	seen := make(map[string]bool)
+	for _, reading := range v {
+		if f := p.form(reading); !seen[f] {
+			seen[f] = true
+			forms = append(forms, f)
+		}
+	}
+
+	return forms
+}
+
+// 讀取讀音表 "拼音\t注音\t漢字..." 與多音字表 "漢字\t拼音 拼音..."，# 開頭為註解
+//
+// 多音字表列出的字以多音字表的讀音為準，注音依讀音表的拼音對照
+func loadReadings(name, polyphone string) map[rune][]reading {
+	m := make(map[rune][]reading)
+	zhuyin := make(map[string]string)
+	for _, fields := range readTable(name, 3) {
+		zhuyin[fields[0]] = fields[1]
+		for _, r := range fields[2] {
+			m[r] = []reading{{pinyin: fields[0], zhuyin: fields[1]}}
+		}
+	}
+
+	for _, fields := range readTable(polyphone, 2) {
+		r, _ := utf8.DecodeRuneInString(fields[0])
+
+		v := make([]reading, 0)
+		for _, py := range strings.Fields(fields[1]) {
+			if zy, ok := zhuyin[py]; ok {
+				v = append(v, reading{pinyin: py, zhuyin: zy})
+			}
+		}
+		if len(v) > 0 {
+			m[r] = v
+		}
+	}
+
+	return m
+}
+
+// 讀取以 tab 分隔的表格，略過空白行、註解與欄位數不符的行
+func readTable(name string, n int) [][]string {
+	f, err := data.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	rows := make([][]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == n {
+			rows = append(rows, fields)
+		}
+	}
+
+	return rows
+}
+
+// 逐字轉換，一個字元可以轉成多個字元
+type runeTransformer func(r rune) string
+
+func (f runeTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+
+		var s string
+		if r == utf8.RuneError && size == 1 {
+			// 字元被切開，等待後續資料
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+
+			// 不合法的位元組換成 RuneError，與 runes.Map 相同
+			s = string(utf8.RuneError)
+		} else {
+			s = f(r)
+		}
+
+		if nDst+len(s) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+
+		nDst += copy(dst[nDst:], s)
+		nSrc += size
+	}
+
+	return nDst, nSrc, nil
+}
+
+func (runeTransformer) Reset() {}
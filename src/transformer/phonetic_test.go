@@ -0,0 +1,89 @@
+package transformer
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestPhonetic(t *testing.T) {
+	cases := []struct {
+		t    transform.Transformer
+		want string
+	}{
+		{Pinyin, "jichengche"},
+		{PinyinInitials, "jcc"},
+		{Zhuyin, "ㄐㄧㄔㄥㄔㄜ"},
+		{ZhuyinInitials, "ㄐㄔㄔ"},
+	}
+
+	for _, c := range cases {
+		s, _, err := transform.String(c.t, "計程車")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s != c.want {
+			t.Errorf("want %q, got %q", c.want, s)
+		}
+	}
+}
+
+func TestPhoneticChunked(t *testing.T) {
+	// 每次只給一個位元組，字元會被切開
+	src := []byte("KTV 計程車")
+	dst := make([]byte, 64)
+	nDst := 0
+	for i := 0; i < len(src); {
+		end := i + 1
+		for {
+			n, m, err := PinyinInitials.Transform(dst[nDst:], src[i:end], end == len(src))
+			nDst += n
+			i += m
+			if err != transform.ErrShortSrc {
+				break
+			}
+			end++
+		}
+	}
+
+	if got := string(dst[:nDst]); got != "KTV jcc" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestPolyphone(t *testing.T) {
+	cases := []struct {
+		p    *Phonetic
+		s    string
+		want string
+	}{
+		{Pinyin, "銀行", "yinhang"},
+		{PinyinInitials, "银行", "yh"},
+		{Zhuyin, "銀行", "ㄧㄣㄏㄤ"},
+		{Pinyin, "還款", "huankuan"},
+		{ZhuyinInitials, "還款", "ㄏㄎ"},
+		{Pinyin, "重慶", "chongqing"},
+		{PinyinInitials, "重庆", "cq"},
+	}
+
+	for _, c := range cases {
+		found := false
+		for _, v := range c.p.Variants(c.s) {
+			found = found || v == c.want
+		}
+		if !found {
+			t.Errorf("Variants(%q) = %v, want %q", c.s, c.p.Variants(c.s), c.want)
+		}
+	}
+
+	// 第一個是常用讀音，與 Transform 相同
+	if v := Pinyin.Variants("行程"); v[0] != "xingcheng" {
+		t.Fatalf("unexpected variants %v", v)
+	}
+
+	// 組合數有上限
+	if v := Pinyin.Variants("還還還還還還還還還還"); len(v) > maxVariants {
+		t.Fatalf("too many variants %d", len(v))
+	}
+}
@@ -1,7 +1,6 @@
 package transformer
 
 import (
-	"bytes"
 	"unicode"
 
 	"golang.org/x/text/runes"
@@ -15,33 +14,20 @@ import (
 var Nop = transform.Nop
 
 // 忽略大小寫
-var Fold = unicodeFoldTransformer{}
+//
+// runes.Map 會處理被切開的字元，可以分段轉換
+var Fold = runes.Map(unicode.ToLower)
 
 // Unicode 等價性
 //
 //	https://learnku.com/docs/go-blog/normalization/6554
-var Normalized = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+var Normalized = newNormalized()
 
 // 忽略大小寫 + Unicode等價性
-var NormalizedFold = transform.Chain(Fold, Normalized)
-
-// 轉成小寫
-type unicodeFoldTransformer struct{}
-
-func (unicodeFoldTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
-	runes := bytes.Runes(src)
-
-	lowerRunes := make([]rune, len(runes))
-	for i, r := range runes {
-		lowerRunes[i] = unicode.ToLower(r)
-	}
+//
+// transform.Chain 帶有狀態，同時使用時請各自建立
+var NormalizedFold = transform.Chain(Fold, newNormalized())
 
-	srcBytes := []byte(string(lowerRunes))
-	n := copy(dst, srcBytes)
-	if n < len(srcBytes) {
-		err = transform.ErrShortDst
-	}
-	return n, n, err
+func newNormalized() transform.Transformer {
+	return transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
 }
-
-func (unicodeFoldTransformer) Reset() {}
@@ -0,0 +1,153 @@
+package transformer
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// 測試用字元，包含不同長度的 UTF-8 與組合字元
+var sampleRunes = []rune{
+	'a', 'Z', '1', ' ', '-',
+	'É', 'é', 'ß', 'İ', 'Σ', 'ς', 'Ж', '́', '̈',
+	'中', '計', '程', '車', '锕', 'ｋ', 'Ｔ', '１', '　',
+	'😀', '𠀋',
+}
+
+func randomString(r *rand.Rand) string {
+	var sb strings.Builder
+	n := r.Intn(64)
+	for i := 0; i < n; i++ {
+		if r.Intn(4) == 0 {
+			// 任意合法字元
+			c := rune(r.Intn(utf8.MaxRune))
+			if utf8.ValidRune(c) {
+				sb.WriteRune(c)
+			}
+			continue
+		}
+		sb.WriteRune(sampleRunes[r.Intn(len(sampleRunes))])
+	}
+	return sb.String()
+}
+
+// 以隨機長度的 src 與 dst 分段轉換
+func transformChunked(t transform.Transformer, s string, r *rand.Rand) (string, error) {
+	t.Reset()
+
+	src := []byte(s)
+	var out bytes.Buffer
+	dst := make([]byte, 0)
+
+	nSrc := 0
+	for {
+		end := nSrc + r.Intn(8)
+		if end > len(src) {
+			end = len(src)
+		}
+		atEOF := end == len(src)
+
+		if len(dst) == 0 {
+			dst = make([]byte, 1+r.Intn(8))
+		}
+
+		n, m, err := t.Transform(dst, src[nSrc:end], atEOF)
+		out.Write(dst[:n])
+		nSrc += m
+
+		switch err {
+		case nil:
+			if atEOF {
+				return out.String(), nil
+			}
+		case transform.ErrShortDst:
+			if n == 0 {
+				// 目的空間不足，放大
+				dst = make([]byte, 2*len(dst))
+				continue
+			}
+		case transform.ErrShortSrc:
+			if atEOF {
+				return "", err
+			}
+		default:
+			return "", err
+		}
+
+		dst = dst[:0]
+	}
+}
+
+func TestFoldMatchesToLower(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		s := randomString(r)
+
+		got, err := transformChunked(Fold, s, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := strings.ToLower(s); got != want {
+			t.Fatalf("Fold(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestNormalizedFoldChunked(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 2000; i++ {
+		s := randomString(r)
+
+		want, _, err := transform.String(newNormalized(), strings.ToLower(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := transformChunked(transform.Chain(Fold, newNormalized()), s, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Fatalf("NormalizedFold(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestReaderOneByte(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	transformers := map[string]func() transform.Transformer{
+		"Fold":        func() transform.Transformer { return Fold },
+		"ChineseFold": NewChineseFold,
+		"Pinyin":      func() transform.Transformer { return Pinyin },
+		"Zhuyin":      func() transform.Transformer { return Zhuyin },
+	}
+
+	for name, newT := range transformers {
+		for i := 0; i < 500; i++ {
+			s := randomString(r)
+
+			want, _, err := transform.String(newT(), s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reader := transform.NewReader(iotest.OneByteReader(strings.NewReader(s)), newT())
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != want {
+				t.Fatalf("%s(%q) = %q, want %q", name, s, got, want)
+			}
+		}
+	}
+}